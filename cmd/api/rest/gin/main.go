@@ -1,12 +1,14 @@
 package main
 
 import (
+	"flag"
 	"golang_starter/internal/api/rest/gin"
 )
 
 func main() {
-	// TODO implement delete album by id
+	file := flag.String("conf", "", "The configuration file, like res/conf/gin.yaml. It is reloaded when it changes")
+	flag.Parse()
 
 	// start a rest api server
-	gin.Run()
+	gin.Run(*file)
 }
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"golang_starter/internal/api/grpc/go-grpc/greeter/cli"
+)
+
+func main() {
+	cli.Execute()
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"flag"
+	"golang_starter/internal/api/grpc/go-grpc/greeter/server"
+	"log"
+)
+
+var port = flag.Int("port", 50051, "The server port")
+
+func main() {
+	flag.Parse()
+	if err := server.Run(*port); err != nil {
+		log.Fatal(err)
+	}
+}
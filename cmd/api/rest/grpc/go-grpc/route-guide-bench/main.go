@@ -0,0 +1,9 @@
+package main
+
+import (
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/bench"
+)
+
+func main() {
+	bench.Execute()
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"golang_starter/internal/api/grpc/go-grpc/tlsconfig"
+	"log"
+	"strings"
+)
+
+// Generates a throwaway CA, server and client certificates to try TLS and mutual TLS locally
+var (
+	out   = flag.String("out", "certs", "The directory to write the PEM files to")
+	hosts = flag.String("hosts", "localhost,127.0.0.1", "Comma separated DNS names and IP addresses of the server certificate")
+)
+
+func main() {
+	flag.Parse()
+	files, err := tlsconfig.GenerateDevCertificates(*out, strings.Split(*hosts, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("CA certificate:    ", files.CAFile)
+	log.Println("Server certificate:", files.ServerCertFile, files.ServerKeyFile)
+	log.Println("Client certificate:", files.ClientCertFile, files.ClientKeyFile)
+}
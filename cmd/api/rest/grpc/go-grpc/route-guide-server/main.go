@@ -3,15 +3,20 @@ package main
 import (
 	"flag"
 	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"log"
 )
 
-// static default vars for the server
-var (
-	host = flag.String("host", "localhost", "The server address")
-	port = flag.Int("port", 8080, "The server port")
-)
+// The flags override the configuration file and the ROUTE_GUIDE_* environment variables
+var configFile = flag.String("config", "", "The YAML configuration file, see res/conf/route-guide-server.yaml")
 
 func main() {
+	server.RegisterFlags(flag.CommandLine)
 	flag.Parse()
-	server.Run(*host, *port)
+	config, err := server.LoadConfig(*configFile, flag.CommandLine)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := server.Run(config); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"golang_starter/internal/clients/rest/resty/probe"
+)
+
+func main() {
+	probe.Execute()
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"flag"
 	"golang_starter/internal/clients/rest/resty"
 	"log"
 )
 
 func main() {
+	baseURL := flag.String("url", resty.DefaultBaseURL, "The base URL of the albums API")
+	flag.Parse()
+
 	log.Println("Rest client with Resty")
-	resty.Run()
+	resty.Run(*baseURL)
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/rest/gin"
+	"golang_starter/internal/marshaller/viper"
+	"golang_starter/internal/marshaller/viper/config"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// schemas are the configuration structs of the programs, by schema file
+var schemas = map[string]struct {
+	title  string
+	target interface{}
+}{
+	"viper.schema.json":              {"Configuration of the viper tutorial", &viper.Config{}},
+	"gin.schema.json":                {"Configuration of the albums API", &gin.Config{}},
+	"route-guide-server.schema.json": {"Configuration of the RouteGuide gRPC server", &server.Config{}},
+}
+
+// main writes the JSON Schemas of the configuration files of res/conf, referred by their
+// yaml-language-server comment. Run it after changing a configuration struct:
+//
+//	go run ./cmd/marshaller/config-schemas -dir res/conf
+func main() {
+	dir := flag.String("dir", "res/conf", "The directory of the schemas")
+	flag.Parse()
+
+	for name, schema := range schemas {
+		file, err := os.Create(filepath.Join(*dir, name))
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err := config.WriteSchema(file, schema.target, schema.title); err != nil {
+			log.Fatalln(err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatalln(err)
+		}
+		log.Println("Written", file.Name())
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"golang_starter/internal/marshaller/viper"
+	"os"
+)
+
+func main() {
+	if err := viper.NewConfigCommand().Execute(); err != nil {
+		// cobra has already printed the error
+		os.Exit(1)
+	}
+}
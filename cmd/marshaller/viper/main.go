@@ -1,18 +1,14 @@
 package main
 
 import (
-	"flag"
+	"github.com/spf13/pflag"
 	"golang_starter/internal/marshaller/viper"
-	"log"
-	"path/filepath"
 )
 
 func main() {
-	// TODO give file in parameters
-	fileArg := flag.String("conf", "", "Yaml file to parse")
-	flag.Parse()
+	file := pflag.String("conf", "", "The configuration file overriding the embedded defaults, like res/conf/viper.yaml")
+	viper.RegisterFlags(pflag.CommandLine)
+	pflag.Parse()
 
-	file, _ := filepath.Abs(*fileArg)
-	log.Println("file is", file)
-	viper.Start(*fileArg)
+	viper.Start(*file, pflag.CommandLine)
 }
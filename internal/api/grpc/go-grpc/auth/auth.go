@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// This package authenticates the gRPC calls with a bearer token sent in the "authorization"
+// metadata, and authorizes them with per-method rules on the roles of the authenticated principal.
+// The tokens are either static ones listed in the configuration, or JWT verified with a local key.
+
+// ErrInvalidToken is returned by the authenticators for unknown, expired or malformed tokens
+var ErrInvalidToken = errors.New("invalid token")
+
+// Principal is the authenticated identity of a caller
+type Principal struct {
+	Subject string
+	Roles   []string
+}
+
+// HasRole tells if the principal has one of the given roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		for _, own := range p.Roles {
+			if own == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticator returns the principal owning a token, or ErrInvalidToken
+type Authenticator interface {
+	Authenticate(token string) (*Principal, error)
+}
+
+// StaticTokens authenticates the tokens listed in a map
+type StaticTokens map[string]*Principal
+
+func (s StaticTokens) Authenticate(token string) (*Principal, error) {
+	principal, ok := s[token]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return principal, nil
+}
+
+// Authenticators tries each authenticator in turn, and returns the first principal found
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(token string) (*Principal, error) {
+	for _, authenticator := range a {
+		if principal, err := authenticator.Authenticate(token); err == nil {
+			return principal, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// principalKey is the context key of the principal. An unexported type prevents collisions with the
+// keys of other packages.
+type principalKey struct{}
+
+// NewContext returns a copy of the context holding the principal
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal authenticated for the call, if any
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// Rules tells which principals can call which methods.
+// The methods are full gRPC method names like "/main.RouteGuide/GetFeature".
+type Rules struct {
+	// Public methods are called without authentication
+	Public []string `yaml:"public" mapstructure:"public"`
+	// Methods maps a method to the roles allowed to call it. A service wildcard like
+	// "/main.RouteGuide/*" applies to the methods of the service without their own rule.
+	// An empty list of roles allows every authenticated principal. Once a rule is set, the methods
+	// without rule are denied, like the ones of a service added later. Without any rule, every
+	// authenticated principal calls every method.
+	Methods map[string][]string `yaml:"methods" mapstructure:"methods"`
+}
+
+// IsPublic tells if the method is called without authentication
+func (r Rules) IsPublic(method string) bool {
+	for _, public := range r.Public {
+		if public == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows tells if the principal can call the method
+func (r Rules) Allows(method string, principal *Principal) bool {
+	roles, ok := r.Methods[method]
+	if !ok {
+		// "/package.Service/Method" -> "/package.Service/*"
+		service := method[:strings.LastIndex(method, "/")+1]
+		roles, ok = r.Methods[service+"*"]
+	}
+	if !ok {
+		return len(r.Methods) == 0
+	}
+	if len(roles) == 0 {
+		return true
+	}
+	return principal.HasRole(roles...)
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+)
+
+// Config is the authentication configuration of a gRPC server, usually read from a YAML file:
+//
+//	tokens:
+//	  - token: s3cr3t
+//	    subject: alice
+//	    roles: [reader, writer]
+//	jwt:
+//	  keyFile: jwt-public.pem
+//	  issuer: route-guide
+//	rules:
+//	  public: [/grpc.health.v1.Health/Check]
+//	  methods:
+//	    /main.RouteGuide/RecordRoute: [writer]
+//	    /main.RouteGuide/*: [reader, writer]
+type Config struct {
+	Tokens []StaticToken `yaml:"tokens" mapstructure:"tokens"`
+	JWT    JWTConfig     `yaml:"jwt" mapstructure:"jwt"`
+	Rules  Rules         `yaml:"rules" mapstructure:"rules"`
+}
+
+// StaticToken is a token given to a principal in the configuration
+type StaticToken struct {
+	Token   string   `yaml:"token" mapstructure:"token"`
+	Subject string   `yaml:"subject" mapstructure:"subject"`
+	Roles   []string `yaml:"roles" mapstructure:"roles"`
+}
+
+// JWTConfig enables the JWT authentication when KeyFile is set. See NewJWTVerifier.
+type JWTConfig struct {
+	KeyFile  string `yaml:"keyFile" mapstructure:"keyFile"`
+	Issuer   string `yaml:"issuer" mapstructure:"issuer"`
+	Audience string `yaml:"audience" mapstructure:"audience"`
+}
+
+// LoadConfig reads the configuration from a YAML file. The unknown keys are errors, like a
+// misspelled keyFile, and so is a file without tokens nor JWT key: a server given an authentication
+// file must not accept every call.
+func LoadConfig(file string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return config, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	// an empty file is a configuration without authenticator
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		return config, fmt.Errorf("invalid authentication file %s: %w", file, err)
+	}
+	if !config.Enabled() {
+		return config, fmt.Errorf("invalid authentication file %s: %w", file, errNoAuthenticator)
+	}
+	return config, nil
+}
+
+var errNoAuthenticator = errors.New("no authenticator is configured, set tokens or jwt.keyFile")
+
+// Enabled tells if the configuration authenticates the calls
+func (c Config) Enabled() bool {
+	return len(c.Tokens) > 0 || c.JWT.KeyFile != ""
+}
+
+// Validate returns an error when the configuration sets something, like rules or a JWT issuer, but no
+// authenticator: the server would accept every call
+func (c Config) Validate() error {
+	if !c.Enabled() && !reflect.DeepEqual(c, Config{}) {
+		return errNoAuthenticator
+	}
+	return nil
+}
+
+// Interceptors creates the interceptors checking the configured tokens and rules
+func (c Config) Interceptors() (*Interceptors, error) {
+	var authenticators Authenticators
+	if len(c.Tokens) > 0 {
+		tokens := make(StaticTokens)
+		for _, token := range c.Tokens {
+			tokens[token.Token] = &Principal{Subject: token.Subject, Roles: token.Roles}
+		}
+		authenticators = append(authenticators, tokens)
+	}
+	if c.JWT.KeyFile != "" {
+		verifier, err := NewJWTVerifier(c.JWT.KeyFile, c.JWT.Issuer, c.JWT.Audience)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, verifier)
+	}
+	return NewInterceptors(authenticators, c.Rules), nil
+}
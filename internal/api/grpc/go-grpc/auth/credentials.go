@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+)
+
+// TokenCredentials implements grpc.PerRPCCredentials: the client sends the token as bearer token in
+// the metadata of every call. Use it with grpc.WithPerRPCCredentials.
+type TokenCredentials struct {
+	Token string
+	// AllowInsecure lets the token be sent without TLS. The token can then be read on the network:
+	// only use it for local development.
+	AllowInsecure bool
+}
+
+func (c TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.Token}, nil
+}
+
+func (c TokenCredentials) RequireTransportSecurity() bool {
+	return !c.AllowInsecure
+}
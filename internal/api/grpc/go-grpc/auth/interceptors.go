@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// Interceptors are invoked by the server before the service methods.
+// They authenticate the caller, check the rules, and attach the principal to the call context.
+type Interceptors struct {
+	authenticator Authenticator
+	rules         Rules
+}
+
+// NewInterceptors creates the interceptors checking the tokens with the authenticator
+func NewInterceptors(authenticator Authenticator, rules Rules) *Interceptors {
+	return &Interceptors{authenticator: authenticator, rules: rules}
+}
+
+// ServerOptions returns the options installing both the unary and the stream interceptors
+func (i *Interceptors) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(i.Unary),
+		grpc.ChainStreamInterceptor(i.Stream),
+	}
+}
+
+// Unary is the grpc.UnaryServerInterceptor of the unary methods
+func (i *Interceptors) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := i.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream is the grpc.StreamServerInterceptor of the streaming methods
+func (i *Interceptors) Stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize returns the context holding the principal, or the status error to return to the caller
+func (i *Interceptors) authorize(ctx context.Context, method string) (context.Context, error) {
+	if i.rules.IsPublic(method) {
+		return ctx, nil
+	}
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	principal, err := i.authenticator.Authenticate(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !i.rules.Allows(method, principal) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s cannot call %s", principal.Subject, method)
+	}
+	return NewContext(ctx, principal), nil
+}
+
+// bearerToken reads the token from the "authorization: Bearer <token>" metadata
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", status.Error(codes.Unauthenticated, "authorization metadata is not a bearer token")
+	}
+	return token, nil
+}
+
+// authenticatedStream overrides the context of the stream with the one holding the principal
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
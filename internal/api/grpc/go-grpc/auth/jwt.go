@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"os"
+)
+
+// claims are the JWT claims read by JWTVerifier: the registered ones, and the principal's roles
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// JWTVerifier authenticates JWT signed with a local key. The principal is the "sub" claim, with the
+// roles of the "roles" claim.
+type JWTVerifier struct {
+	// key is either a HMAC secret, or a RSA, ECDSA or Ed25519 public key
+	key      interface{}
+	issuer   string
+	audience string
+}
+
+// NewJWTVerifier reads the verification key from a file. A PEM public key file verifies the tokens
+// signed with the matching private key, any other file content is a HMAC secret.
+// The issuer and the audience are only checked when not empty.
+func NewJWTVerifier(keyFile string, issuer string, audience string) (*JWTVerifier, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	verifier := &JWTVerifier{key: data, issuer: issuer, audience: audience}
+	if block, _ := pem.Decode(data); block != nil {
+		verifier.key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the JWT public key: %w", err)
+		}
+	}
+	return verifier, nil
+}
+
+func (v *JWTVerifier) Authenticate(token string) (*Principal, error) {
+	var tokenClaims claims
+	_, err := jwt.ParseWithClaims(token, &tokenClaims, v.keyFunc)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	// the expiration and "not before" dates are checked by ParseWithClaims
+	if v.issuer != "" && !tokenClaims.VerifyIssuer(v.issuer, true) {
+		return nil, ErrInvalidToken
+	}
+	if v.audience != "" && !tokenClaims.VerifyAudience(v.audience, true) {
+		return nil, ErrInvalidToken
+	}
+	if tokenClaims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return &Principal{Subject: tokenClaims.Subject, Roles: tokenClaims.Roles}, nil
+}
+
+// keyFunc returns the verification key after checking that the token's algorithm matches its type.
+// Otherwise, a token signed with HMAC using the public key as secret would be accepted.
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	var ok bool
+	switch v.key.(type) {
+	case []byte:
+		_, ok = token.Method.(*jwt.SigningMethodHMAC)
+	case *rsa.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodRSA)
+		if !ok {
+			_, ok = token.Method.(*jwt.SigningMethodRSAPSS)
+		}
+	case *ecdsa.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodEd25519)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return v.key, nil
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang_starter/internal/api/grpc/go-grpc/greeter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// app holds the values of the global flags, and the connection opened for the command
+type app struct {
+	host    string
+	port    int
+	timeout time.Duration
+
+	connection *grpc.ClientConn
+}
+
+// NewCommand creates the greeter-client command line, with one sub command per method of
+// spec_intro.proto
+func NewCommand() *cobra.Command {
+	a := &app{}
+	rootCmd := &cobra.Command{
+		Use:          "greeter-client",
+		Short:        "Call the methods of a Greeter server",
+		SilenceUsage: true,
+
+		// open the connection before running any sub command
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			a.connection, err = grpc.Dial(net.JoinHostPort(a.host, strconv.Itoa(a.port)),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			return err
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return a.connection.Close()
+		},
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&a.host, "host", "localhost", "The server address")
+	flags.IntVar(&a.port, "port", 50051, "The server port")
+	flags.DurationVar(&a.timeout, "timeout", 10*time.Second, "The deadline of the call")
+
+	rootCmd.AddCommand(a.helloCommand(), a.repliesCommand(), a.greetingsCommand(), a.bidiCommand())
+	return rootCmd
+}
+
+// Execute is the entry point of the cli
+func Execute() {
+	if err := NewCommand().Execute(); err != nil {
+		// cobra has already printed the error
+		os.Exit(1)
+	}
+}
+
+// context returns the context of the call, cancelled after the timeout flag
+func (a *app) context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), a.timeout)
+}
+
+// helloCommand calls the unary SayHello method
+func (a *app) helloCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hello NAME",
+		Short: "Get a greeting",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := a.context(cmd)
+			defer cancel()
+			reply, err := greeter.NewGreeterClient(a.connection).SayHello(ctx, &greeter.HelloRequest{Name: args[0]})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), reply.GetMessage())
+			return nil
+		},
+	}
+}
+
+// repliesCommand calls the server streaming LotsOfReplies method
+func (a *app) repliesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "replies NAME",
+		Short: "Get a stream of greetings",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := a.context(cmd)
+			defer cancel()
+			stream, err := greeter.NewGreeterServerStreamClient(a.connection).LotsOfReplies(ctx, &greeter.HelloRequest{Name: args[0]})
+			if err != nil {
+				return err
+			}
+			// read until the server ends the stream
+			for {
+				reply, err := stream.Recv()
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), reply.GetMessage())
+			}
+		},
+	}
+}
+
+// greetingsCommand calls the client streaming LotsOfGreetings method
+func (a *app) greetingsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "greetings NAME...",
+		Short: "Send a stream of names, and get all their greetings at once",
+		Args:  cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := a.context(cmd)
+			defer cancel()
+			stream, err := greeter.NewGreeterClientStreamClient(a.connection).LotsOfGreetings(ctx)
+			if err != nil {
+				return err
+			}
+			for _, name := range args {
+				if err := stream.Send(&greeter.HelloRequest{Name: name}); err != nil {
+					// the server has ended the call: CloseAndRecv returns its status
+					break
+				}
+			}
+			reply, err := stream.CloseAndRecv()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), reply.GetMessage())
+			return nil
+		},
+	}
+}
+
+// bidiCommand calls the bidirectional streaming BidiHello method
+func (a *app) bidiCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "bidi NAME...",
+		Short: "Send a stream of names, and get their greetings one by one",
+		Args:  cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := a.context(cmd)
+			defer cancel()
+			stream, err := greeter.NewGreeterBidirectionalStreamClient(a.connection).BidiHello(ctx)
+			if err != nil {
+				return err
+			}
+			// send one name, then wait for its greeting
+			for _, name := range args {
+				if err := stream.Send(&greeter.HelloRequest{Name: name}); err != nil && err != io.EOF {
+					return err
+				}
+				reply, err := stream.Recv()
+				if err != nil {
+					return fmt.Errorf("greeting %s: %w", name, err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), reply.GetMessage())
+			}
+			return stream.CloseSend()
+		},
+	}
+}
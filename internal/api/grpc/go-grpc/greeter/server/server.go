@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"golang_starter/internal/api/grpc/go-grpc/greeter"
+	"golang_starter/pkg/greetings"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"log"
+	"net"
+	"strings"
+)
+
+// server implements the four services of spec_intro.proto, one per kind of rpc method.
+// The greetings themselves come from the pkg/greetings module.
+
+// ReplyCount is the number of greetings sent back by LotsOfReplies
+const ReplyCount = 3
+
+// greeterServer server's structure.
+// Each service has its own Unimplemented structure to embed.
+type greeterServer struct {
+	greeter.UnimplementedGreeterServer
+	greeter.UnimplementedGreeterServerStreamServer
+	greeter.UnimplementedGreeterClientStreamServer
+	greeter.UnimplementedGreeterBidirectionalStreamServer
+}
+
+// Register registers the four Greeter services on the gRPC server
+func Register(s *grpc.Server) {
+	server := &greeterServer{}
+	greeter.RegisterGreeterServer(s, server)
+	greeter.RegisterGreeterServerStreamServer(s, server)
+	greeter.RegisterGreeterClientStreamServer(s, server)
+	greeter.RegisterGreeterBidirectionalStreamServer(s, server)
+}
+
+// hello returns the greeting of a name, as a gRPC status error when the name is empty
+func hello(name string) (*greeter.HelloReply, error) {
+	message, err := greetings.Hello(name)
+	if err != nil {
+		// the only error of greetings.Hello is the empty name: the client has to fix its request
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &greeter.HelloReply{Message: message}, nil
+}
+
+// SayHello is the unary method: one request, one reply
+func (s *greeterServer) SayHello(ctx context.Context, request *greeter.HelloRequest) (*greeter.HelloReply, error) {
+	log.Println("Received SayHello message for name:", request.GetName())
+	return hello(request.GetName())
+}
+
+// LotsOfReplies is the server streaming method: it sends ReplyCount greetings for the name of the
+// request
+func (s *greeterServer) LotsOfReplies(request *greeter.HelloRequest, stream greeter.GreeterServerStream_LotsOfRepliesServer) error {
+	log.Println("Received LotsOfReplies message for name:", request.GetName())
+	for i := 0; i < ReplyCount; i++ {
+		reply, err := hello(request.GetName())
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LotsOfGreetings is the client streaming method: it reads all the names sent by the client, then
+// replies with one greeting per line, in the order of the names
+func (s *greeterServer) LotsOfGreetings(stream greeter.GreeterClientStream_LotsOfGreetingsServer) error {
+	var names []string
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		names = append(names, request.GetName())
+	}
+	log.Println("Received LotsOfGreetings messages for names:", names)
+
+	messages, err := greetings.Hellos(names)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	// the map of Hellos has no order
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = messages[name]
+	}
+	return stream.SendAndClose(&greeter.HelloReply{Message: strings.Join(lines, "\n")})
+}
+
+// BidiHello is the bidirectional streaming method: it replies to each name as soon as it is
+// received, until the client closes its side of the stream
+func (s *greeterServer) BidiHello(stream greeter.GreeterBidirectionalStream_BidiHelloServer) error {
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		log.Println("Received BidiHello message for name:", request.GetName())
+		reply, err := hello(request.GetName())
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// Run serves the Greeter services on the local port until the server fails
+func Run(port int) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	grpcServer := grpc.NewServer()
+	Register(grpcServer)
+	log.Println("Greeter server listening on", listener.Addr())
+	return grpcServer.Serve(listener)
+}
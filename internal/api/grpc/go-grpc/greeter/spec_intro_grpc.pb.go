@@ -0,0 +1,459 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.12.4
+// source: spec_intro.proto
+
+package greeter
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// GreeterClient is the client API for Greeter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GreeterClient interface {
+	// Sends a greeting
+	SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error)
+}
+
+type greeterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGreeterClient(cc grpc.ClientConnInterface) GreeterClient {
+	return &greeterClient{cc}
+}
+
+func (c *greeterClient) SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error) {
+	out := new(HelloReply)
+	err := c.cc.Invoke(ctx, "/main.Greeter/SayHello", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GreeterServer is the server API for Greeter service.
+// All implementations must embed UnimplementedGreeterServer
+// for forward compatibility
+type GreeterServer interface {
+	// Sends a greeting
+	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	mustEmbedUnimplementedGreeterServer()
+}
+
+// UnimplementedGreeterServer must be embedded to have forward compatible implementations.
+type UnimplementedGreeterServer struct {
+}
+
+func (UnimplementedGreeterServer) SayHello(context.Context, *HelloRequest) (*HelloReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SayHello not implemented")
+}
+func (UnimplementedGreeterServer) mustEmbedUnimplementedGreeterServer() {}
+
+// UnsafeGreeterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GreeterServer will
+// result in compilation errors.
+type UnsafeGreeterServer interface {
+	mustEmbedUnimplementedGreeterServer()
+}
+
+func RegisterGreeterServer(s grpc.ServiceRegistrar, srv GreeterServer) {
+	s.RegisterService(&Greeter_ServiceDesc, srv)
+}
+
+func _Greeter_SayHello_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HelloRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).SayHello(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/main.Greeter/SayHello",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).SayHello(ctx, req.(*HelloRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Greeter_ServiceDesc is the grpc.ServiceDesc for Greeter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Greeter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "main.Greeter",
+	HandlerType: (*GreeterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SayHello",
+			Handler:    _Greeter_SayHello_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spec_intro.proto",
+}
+
+// GreeterServerStreamClient is the client API for GreeterServerStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GreeterServerStreamClient interface {
+	LotsOfReplies(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (GreeterServerStream_LotsOfRepliesClient, error)
+}
+
+type greeterServerStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGreeterServerStreamClient(cc grpc.ClientConnInterface) GreeterServerStreamClient {
+	return &greeterServerStreamClient{cc}
+}
+
+func (c *greeterServerStreamClient) LotsOfReplies(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (GreeterServerStream_LotsOfRepliesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GreeterServerStream_ServiceDesc.Streams[0], "/main.GreeterServerStream/LotsOfReplies", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &greeterServerStreamLotsOfRepliesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GreeterServerStream_LotsOfRepliesClient interface {
+	Recv() (*HelloReply, error)
+	grpc.ClientStream
+}
+
+type greeterServerStreamLotsOfRepliesClient struct {
+	grpc.ClientStream
+}
+
+func (x *greeterServerStreamLotsOfRepliesClient) Recv() (*HelloReply, error) {
+	m := new(HelloReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GreeterServerStreamServer is the server API for GreeterServerStream service.
+// All implementations must embed UnimplementedGreeterServerStreamServer
+// for forward compatibility
+type GreeterServerStreamServer interface {
+	LotsOfReplies(*HelloRequest, GreeterServerStream_LotsOfRepliesServer) error
+	mustEmbedUnimplementedGreeterServerStreamServer()
+}
+
+// UnimplementedGreeterServerStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedGreeterServerStreamServer struct {
+}
+
+func (UnimplementedGreeterServerStreamServer) LotsOfReplies(*HelloRequest, GreeterServerStream_LotsOfRepliesServer) error {
+	return status.Errorf(codes.Unimplemented, "method LotsOfReplies not implemented")
+}
+func (UnimplementedGreeterServerStreamServer) mustEmbedUnimplementedGreeterServerStreamServer() {}
+
+// UnsafeGreeterServerStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GreeterServerStreamServer will
+// result in compilation errors.
+type UnsafeGreeterServerStreamServer interface {
+	mustEmbedUnimplementedGreeterServerStreamServer()
+}
+
+func RegisterGreeterServerStreamServer(s grpc.ServiceRegistrar, srv GreeterServerStreamServer) {
+	s.RegisterService(&GreeterServerStream_ServiceDesc, srv)
+}
+
+func _GreeterServerStream_LotsOfReplies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(HelloRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GreeterServerStreamServer).LotsOfReplies(m, &greeterServerStreamLotsOfRepliesServer{stream})
+}
+
+type GreeterServerStream_LotsOfRepliesServer interface {
+	Send(*HelloReply) error
+	grpc.ServerStream
+}
+
+type greeterServerStreamLotsOfRepliesServer struct {
+	grpc.ServerStream
+}
+
+func (x *greeterServerStreamLotsOfRepliesServer) Send(m *HelloReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// GreeterServerStream_ServiceDesc is the grpc.ServiceDesc for GreeterServerStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GreeterServerStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "main.GreeterServerStream",
+	HandlerType: (*GreeterServerStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "LotsOfReplies",
+			Handler:       _GreeterServerStream_LotsOfReplies_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "spec_intro.proto",
+}
+
+// GreeterClientStreamClient is the client API for GreeterClientStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GreeterClientStreamClient interface {
+	LotsOfGreetings(ctx context.Context, opts ...grpc.CallOption) (GreeterClientStream_LotsOfGreetingsClient, error)
+}
+
+type greeterClientStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGreeterClientStreamClient(cc grpc.ClientConnInterface) GreeterClientStreamClient {
+	return &greeterClientStreamClient{cc}
+}
+
+func (c *greeterClientStreamClient) LotsOfGreetings(ctx context.Context, opts ...grpc.CallOption) (GreeterClientStream_LotsOfGreetingsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GreeterClientStream_ServiceDesc.Streams[0], "/main.GreeterClientStream/LotsOfGreetings", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &greeterClientStreamLotsOfGreetingsClient{stream}
+	return x, nil
+}
+
+type GreeterClientStream_LotsOfGreetingsClient interface {
+	Send(*HelloRequest) error
+	CloseAndRecv() (*HelloReply, error)
+	grpc.ClientStream
+}
+
+type greeterClientStreamLotsOfGreetingsClient struct {
+	grpc.ClientStream
+}
+
+func (x *greeterClientStreamLotsOfGreetingsClient) Send(m *HelloRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *greeterClientStreamLotsOfGreetingsClient) CloseAndRecv() (*HelloReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(HelloReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GreeterClientStreamServer is the server API for GreeterClientStream service.
+// All implementations must embed UnimplementedGreeterClientStreamServer
+// for forward compatibility
+type GreeterClientStreamServer interface {
+	LotsOfGreetings(GreeterClientStream_LotsOfGreetingsServer) error
+	mustEmbedUnimplementedGreeterClientStreamServer()
+}
+
+// UnimplementedGreeterClientStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedGreeterClientStreamServer struct {
+}
+
+func (UnimplementedGreeterClientStreamServer) LotsOfGreetings(GreeterClientStream_LotsOfGreetingsServer) error {
+	return status.Errorf(codes.Unimplemented, "method LotsOfGreetings not implemented")
+}
+func (UnimplementedGreeterClientStreamServer) mustEmbedUnimplementedGreeterClientStreamServer() {}
+
+// UnsafeGreeterClientStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GreeterClientStreamServer will
+// result in compilation errors.
+type UnsafeGreeterClientStreamServer interface {
+	mustEmbedUnimplementedGreeterClientStreamServer()
+}
+
+func RegisterGreeterClientStreamServer(s grpc.ServiceRegistrar, srv GreeterClientStreamServer) {
+	s.RegisterService(&GreeterClientStream_ServiceDesc, srv)
+}
+
+func _GreeterClientStream_LotsOfGreetings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GreeterClientStreamServer).LotsOfGreetings(&greeterClientStreamLotsOfGreetingsServer{stream})
+}
+
+type GreeterClientStream_LotsOfGreetingsServer interface {
+	SendAndClose(*HelloReply) error
+	Recv() (*HelloRequest, error)
+	grpc.ServerStream
+}
+
+type greeterClientStreamLotsOfGreetingsServer struct {
+	grpc.ServerStream
+}
+
+func (x *greeterClientStreamLotsOfGreetingsServer) SendAndClose(m *HelloReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *greeterClientStreamLotsOfGreetingsServer) Recv() (*HelloRequest, error) {
+	m := new(HelloRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GreeterClientStream_ServiceDesc is the grpc.ServiceDesc for GreeterClientStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GreeterClientStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "main.GreeterClientStream",
+	HandlerType: (*GreeterClientStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "LotsOfGreetings",
+			Handler:       _GreeterClientStream_LotsOfGreetings_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "spec_intro.proto",
+}
+
+// GreeterBidirectionalStreamClient is the client API for GreeterBidirectionalStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GreeterBidirectionalStreamClient interface {
+	BidiHello(ctx context.Context, opts ...grpc.CallOption) (GreeterBidirectionalStream_BidiHelloClient, error)
+}
+
+type greeterBidirectionalStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGreeterBidirectionalStreamClient(cc grpc.ClientConnInterface) GreeterBidirectionalStreamClient {
+	return &greeterBidirectionalStreamClient{cc}
+}
+
+func (c *greeterBidirectionalStreamClient) BidiHello(ctx context.Context, opts ...grpc.CallOption) (GreeterBidirectionalStream_BidiHelloClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GreeterBidirectionalStream_ServiceDesc.Streams[0], "/main.GreeterBidirectionalStream/BidiHello", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &greeterBidirectionalStreamBidiHelloClient{stream}
+	return x, nil
+}
+
+type GreeterBidirectionalStream_BidiHelloClient interface {
+	Send(*HelloRequest) error
+	Recv() (*HelloReply, error)
+	grpc.ClientStream
+}
+
+type greeterBidirectionalStreamBidiHelloClient struct {
+	grpc.ClientStream
+}
+
+func (x *greeterBidirectionalStreamBidiHelloClient) Send(m *HelloRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *greeterBidirectionalStreamBidiHelloClient) Recv() (*HelloReply, error) {
+	m := new(HelloReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GreeterBidirectionalStreamServer is the server API for GreeterBidirectionalStream service.
+// All implementations must embed UnimplementedGreeterBidirectionalStreamServer
+// for forward compatibility
+type GreeterBidirectionalStreamServer interface {
+	BidiHello(GreeterBidirectionalStream_BidiHelloServer) error
+	mustEmbedUnimplementedGreeterBidirectionalStreamServer()
+}
+
+// UnimplementedGreeterBidirectionalStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedGreeterBidirectionalStreamServer struct {
+}
+
+func (UnimplementedGreeterBidirectionalStreamServer) BidiHello(GreeterBidirectionalStream_BidiHelloServer) error {
+	return status.Errorf(codes.Unimplemented, "method BidiHello not implemented")
+}
+func (UnimplementedGreeterBidirectionalStreamServer) mustEmbedUnimplementedGreeterBidirectionalStreamServer() {
+}
+
+// UnsafeGreeterBidirectionalStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GreeterBidirectionalStreamServer will
+// result in compilation errors.
+type UnsafeGreeterBidirectionalStreamServer interface {
+	mustEmbedUnimplementedGreeterBidirectionalStreamServer()
+}
+
+func RegisterGreeterBidirectionalStreamServer(s grpc.ServiceRegistrar, srv GreeterBidirectionalStreamServer) {
+	s.RegisterService(&GreeterBidirectionalStream_ServiceDesc, srv)
+}
+
+func _GreeterBidirectionalStream_BidiHello_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GreeterBidirectionalStreamServer).BidiHello(&greeterBidirectionalStreamBidiHelloServer{stream})
+}
+
+type GreeterBidirectionalStream_BidiHelloServer interface {
+	Send(*HelloReply) error
+	Recv() (*HelloRequest, error)
+	grpc.ServerStream
+}
+
+type greeterBidirectionalStreamBidiHelloServer struct {
+	grpc.ServerStream
+}
+
+func (x *greeterBidirectionalStreamBidiHelloServer) Send(m *HelloReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *greeterBidirectionalStreamBidiHelloServer) Recv() (*HelloRequest, error) {
+	m := new(HelloRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GreeterBidirectionalStream_ServiceDesc is the grpc.ServiceDesc for GreeterBidirectionalStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GreeterBidirectionalStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "main.GreeterBidirectionalStream",
+	HandlerType: (*GreeterBidirectionalStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BidiHello",
+			Handler:       _GreeterBidirectionalStream_BidiHello_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "spec_intro.proto",
+}
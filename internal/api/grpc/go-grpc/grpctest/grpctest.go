@@ -0,0 +1,127 @@
+package grpctest
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+// grpctest runs gRPC servers in the test process, like net/http/httptest does for HTTP servers.
+// The server listens on a bufconn listener: an in-memory pipe, so that the tests do not need a free
+// TCP port and do not depend on the network. Any service can be registered on it, with the
+// interceptors under test.
+//
+//	server := grpctest.Start(t, func(s *grpc.Server) {
+//		pb.RegisterRouteGuideServer(s, routeGuide)
+//	}, grpctest.WithUnaryInterceptors(interceptors.Unary))
+//	client := pb.NewRouteGuideClient(server.Conn)
+//
+// The server and its connections are closed with the test.
+
+// Target is the address to dial with the Dialer option. Any address would do, since the dialer
+// ignores it.
+const Target = "bufnet"
+
+// bufferSize is the size of the in-memory buffer of each connection
+const bufferSize = 1024 * 1024
+
+// Server is a gRPC server listening in memory
+type Server struct {
+	// Server is the gRPC server, already serving
+	*grpc.Server
+	// Conn is a connection to the server, opened with the dial options of Start
+	Conn *grpc.ClientConn
+
+	t        testing.TB
+	listener *bufconn.Listener
+	options  options
+}
+
+// options holds the settings of the server and its connections
+type options struct {
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	serverOptions      []grpc.ServerOption
+	dialOptions        []grpc.DialOption
+}
+
+// Option configures the server or its connections
+type Option func(*options)
+
+// WithUnaryInterceptors chains interceptors to the unary calls of the server, in the given order
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors chains interceptors to the streaming calls of the server, in the given order
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.streamInterceptors = append(o.streamInterceptors, interceptors...)
+	}
+}
+
+// WithServerOptions adds options to the gRPC server, like message size limits
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *options) {
+		o.serverOptions = append(o.serverOptions, opts...)
+	}
+}
+
+// WithDialOptions adds options to every connection to the server, like per-RPC credentials or
+// client interceptors
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// Start creates a gRPC server, registers the services with the register function and serves them
+// in the background. The server is stopped at the end of the test.
+func Start(t testing.TB, register func(*grpc.Server), opts ...Option) *Server {
+	t.Helper()
+	s := &Server{t: t, listener: bufconn.Listen(bufferSize)}
+	for _, opt := range opts {
+		opt(&s.options)
+	}
+	serverOptions := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.options.unaryInterceptors...),
+		grpc.ChainStreamInterceptor(s.options.streamInterceptors...),
+	}, s.options.serverOptions...)
+	s.Server = grpc.NewServer(serverOptions...)
+	register(s.Server)
+	go s.Server.Serve(s.listener)
+	t.Cleanup(s.Server.Stop)
+
+	s.Conn = s.Dial()
+	return s
+}
+
+// Dial opens a new connection to the server, with the dial options of Start followed by the given
+// ones. The connection is closed at the end of the test.
+func (s *Server) Dial(opts ...grpc.DialOption) *grpc.ClientConn {
+	s.t.Helper()
+	dialOptions := append([]grpc.DialOption{
+		s.Dialer(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, s.options.dialOptions...)
+	dialOptions = append(dialOptions, opts...)
+	connection, err := grpc.Dial(Target, dialOptions...)
+	if err != nil {
+		s.t.Fatalf("grpc.Dial() = %v", err)
+	}
+	s.t.Cleanup(func() { connection.Close() })
+	return connection
+}
+
+// Dialer returns the dial option connecting to the in-memory listener, for the tests opening
+// connections on their own, like the client libraries: they must dial Target.
+func (s *Server) Dialer() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	})
+}
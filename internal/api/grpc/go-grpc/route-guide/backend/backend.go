@@ -1,12 +1,15 @@
 package backend
 
 import (
+	"encoding/json"
 	"fmt"
 	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
-	"math"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"os"
 )
 
-var eiffelTourPoint = pb.Point{Latitude: 48858370, Longitude: 2294481}
+// The sample points are in the E7 representation of the protobuf Point, ie. degrees * 10^7
+var eiffelTourPoint = pb.Point{Latitude: 488583700, Longitude: 22944810}
 
 var Features = []*pb.Feature{
 	{
@@ -15,12 +18,27 @@ var Features = []*pb.Feature{
 	},
 }
 
+// LoadFeatures reads a JSON file holding a list of features, like:
+//
+//	[{"name": "Eiffel Tour", "location": {"latitude": 488583700, "longitude": 22944810}}]
+func LoadFeatures(file string) ([]*pb.Feature, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var features []*pb.Feature
+	if err := json.Unmarshal(data, &features); err != nil {
+		return nil, fmt.Errorf("cannot read the features of %s: %w", file, err)
+	}
+	return features, nil
+}
+
 var SavedRoute = []*pb.Point{
-	{Latitude: 48862578, Longitude: 2287758},
-	{Latitude: 48860672, Longitude: 2290730},
+	{Latitude: 488625780, Longitude: 22877580},
+	{Latitude: 488606720, Longitude: 22907300},
 	&eiffelTourPoint,
-	{Latitude: 48856155, Longitude: 2298176},
-	{Latitude: 48852710, Longitude: 2302918},
+	{Latitude: 488561550, Longitude: 22981760},
+	{Latitude: 488527100, Longitude: 23029180},
 }
 
 var SavedNotes = []*pb.RouteNote{
@@ -32,45 +50,26 @@ var SavedNotes = []*pb.RouteNote{
 }
 
 // InRange takes a Point struct and tells if the Point's location (Longitude and Latitude) is inside
-// a given Rectangle (composed of 2 points)
+// a given Rectangle (composed of 2 points). See geo.Contains for the rectangles crossing the
+// antimeridian.
 func InRange(point *pb.Point, rect *pb.Rectangle) bool {
-	left := math.Min(float64(rect.Lo.Longitude), float64(rect.Hi.Longitude))
-	right := math.Max(float64(rect.Lo.Longitude), float64(rect.Hi.Longitude))
-	top := math.Max(float64(rect.Lo.Latitude), float64(rect.Hi.Latitude))
-	bottom := math.Min(float64(rect.Lo.Latitude), float64(rect.Hi.Latitude))
-
-	if float64(point.Longitude) >= left &&
-		float64(point.Longitude) <= right &&
-		float64(point.Latitude) >= bottom &&
-		float64(point.Latitude) <= top {
-		return true
-	}
-	return false
-}
-
-func toRadians(num float64) float64 {
-	return num * math.Pi / float64(180)
+	return geo.Contains(rect, point)
 }
 
-// CalcDistance calculates the distance between two points using the "haversine" formula.
-// The formula is based on http://mathforum.org/library/drmath/view/51879.html.
+// CalcDistance calculates the distance between two points using the "haversine" formula, rounded
+// down to the metre.
 func CalcDistance(p1 *pb.Point, p2 *pb.Point) int32 {
-	const CordFactor float64 = 1e7
-	const R = float64(6371000) // earth radius in metres
-	lat1 := toRadians(float64(p1.Latitude) / CordFactor)
-	lat2 := toRadians(float64(p2.Latitude) / CordFactor)
-	lng1 := toRadians(float64(p1.Longitude) / CordFactor)
-	lng2 := toRadians(float64(p2.Longitude) / CordFactor)
-	dlat := lat2 - lat1
-	dlng := lng2 - lng1
+	return int32(Distance(p1, p2))
+}
 
-	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
-		math.Cos(lat1)*math.Cos(lat2)*
-			math.Sin(dlng/2)*math.Sin(dlng/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+// Distance calculates the distance in metres between two points, see geo.Distance
+func Distance(p1 *pb.Point, p2 *pb.Point) float64 {
+	return geo.Distance(p1, p2)
+}
 
-	distance := R * c
-	return int32(distance)
+// SameLocation tells if two points have the same coordinates, whatever their timestamps
+func SameLocation(p1 *pb.Point, p2 *pb.Point) bool {
+	return p1.Latitude == p2.Latitude && p1.Longitude == p2.Longitude
 }
 
 // Serialize function for a point is just a string to collapse the location into a text
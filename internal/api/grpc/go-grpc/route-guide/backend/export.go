@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"time"
+)
+
+// Content types of the exported routes
+const (
+	GPXContentType     = "application/gpx+xml"
+	GeoJSONContentType = "application/geo+json"
+)
+
+// gpx is the root element of a GPX 1.1 file. See https://www.topografix.com/GPX/1/1/
+// Only the elements needed to describe a single track are defined.
+type gpx struct {
+	XMLName xml.Name `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Track   gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name    string          `xml:"name"`
+	Segment gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Latitude  float64    `xml:"lat,attr"`
+	Longitude float64    `xml:"lon,attr"`
+	Time      *time.Time `xml:"time,omitempty"`
+}
+
+// ExportGPX returns the route as a GPX file holding a single track
+func ExportGPX(route *pb.Route) ([]byte, error) {
+	file := gpx{
+		Version: "1.1",
+		Creator: "route-guide",
+		Track:   gpxTrack{Name: fmt.Sprintf("route %d", route.Id)},
+	}
+	for _, point := range route.Points {
+		trackPoint := gpxPoint{
+			Latitude:  geo.FromE7(point.Latitude),
+			Longitude: geo.FromE7(point.Longitude),
+		}
+		if point.Timestamp != nil {
+			timestamp := point.Timestamp.AsTime()
+			trackPoint.Time = &timestamp
+		}
+		file.Track.Segment.Points = append(file.Track.Segment.Points, trackPoint)
+	}
+	data, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// geoJSONFeature is a GeoJSON Feature object. See https://www.rfc-editor.org/rfc/rfc7946
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type string `json:"type"`
+	// GeoJSON positions are [longitude, latitude] arrays
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+// ExportGeoJSON returns the route as a GeoJSON Feature with a LineString geometry. The route summary
+// is exported as the feature's properties, and the point timestamps (if any) as the "times" property.
+func ExportGeoJSON(route *pb.Route) ([]byte, error) {
+	feature := geoJSONFeature{
+		Type:     "Feature",
+		Geometry: geoJSONGeometry{Type: "LineString", Coordinates: [][2]float64{}},
+		Properties: map[string]interface{}{
+			"id":           route.Id,
+			"pointCount":   route.Summary.GetPointCount(),
+			"featureCount": route.Summary.GetFeatureCount(),
+			"distance":     route.Summary.GetDistanceMeters(),
+			"elapsedTime":  route.Summary.GetElapsedSeconds(),
+			"averageSpeed": route.Summary.GetAverageSpeed(),
+			"maxSpeed":     route.Summary.GetMaxSpeed(),
+		},
+	}
+	var times []time.Time
+	for _, point := range route.Points {
+		feature.Geometry.Coordinates = append(feature.Geometry.Coordinates,
+			[2]float64{geo.FromE7(point.Longitude), geo.FromE7(point.Latitude)})
+		if point.Timestamp != nil {
+			times = append(times, point.Timestamp.AsTime())
+		}
+	}
+	if len(times) == len(route.Points) {
+		feature.Properties["times"] = times
+	}
+	return json.MarshalIndent(feature, "", "  ")
+}
@@ -0,0 +1,221 @@
+package backend
+
+import (
+	"errors"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultNoteBufferSize is the number of pending notes a subscriber can hold before the hub applies
+// its SlowConsumerPolicy
+const DefaultNoteBufferSize = 64
+
+// ErrSlowConsumer is the reason given to a subscription closed by the hub because it could not keep
+// up with the published notes
+var ErrSlowConsumer = errors.New("subscriber is too slow to receive route notes")
+
+// SlowConsumerPolicy tells the hub what to do when a subscriber's buffer is full
+type SlowConsumerPolicy int
+
+const (
+	// DropNote skips the note for the slow subscriber only. The other subscribers still receive it.
+	DropNote SlowConsumerPolicy = iota
+	// Disconnect closes the slow subscription with ErrSlowConsumer.
+	Disconnect
+)
+
+// ParseSlowConsumerPolicy converts a policy name ("drop" or "disconnect") into a SlowConsumerPolicy
+func ParseSlowConsumerPolicy(name string) (SlowConsumerPolicy, error) {
+	switch name {
+	case "drop":
+		return DropNote, nil
+	case "disconnect":
+		return Disconnect, nil
+	}
+	return DropNote, errors.New("unknown slow consumer policy: " + name)
+}
+
+// String returns the name of the policy
+func (p SlowConsumerPolicy) String() string {
+	if p == Disconnect {
+		return "disconnect"
+	}
+	return "drop"
+}
+
+// MarshalText encodes the policy as its name
+func (p SlowConsumerPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a policy name, so that the policy can be read from configuration files
+func (p *SlowConsumerPolicy) UnmarshalText(text []byte) error {
+	policy, err := ParseSlowConsumerPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// NoteHub is a publish/subscribe hub for RouteNotes.
+// Notes are stored by location and persist for the hub's lifetime, whatever the stream that posted
+// them. Each Subscription watches a set of locations and receives in real time the notes posted at
+// those locations by the other subscriptions.
+// A NoteHub is safe for concurrent use.
+type NoteHub struct {
+	bufferSize int
+	policy     SlowConsumerPolicy
+
+	// mu protects both the stored notes and the watchers index.
+	// A Mutex is a mutual exclusion lock. The zero value for a Mutex is an unlocked mutex.
+	// A Mutex must not be copied after first use.
+	// In the terminology of the Go memory model, the n'th call to Unlock “synchronizes before” the
+	// m'th call to Lock for any n < m.
+	mu       sync.Mutex
+	notes    map[string][]*pb.RouteNote
+	watchers map[string]map[*Subscription]struct{}
+}
+
+// NewNoteHub creates an empty hub. Every subscription gets a buffer of bufferSize notes, and the
+// policy is applied when this buffer is full.
+func NewNoteHub(bufferSize int, policy SlowConsumerPolicy) *NoteHub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultNoteBufferSize
+	}
+	return &NoteHub{
+		bufferSize: bufferSize,
+		policy:     policy,
+		notes:      make(map[string][]*pb.RouteNote),
+		watchers:   make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a new subscription watching no location yet.
+// The caller must Close the subscription once done with it.
+func (h *NoteHub) Subscribe() *Subscription {
+	return &Subscription{
+		hub:       h,
+		notes:     make(chan *pb.RouteNote, h.bufferSize),
+		done:      make(chan struct{}),
+		locations: make(map[string]struct{}),
+	}
+}
+
+// Notes returns a copy of the notes stored at the given location
+func (h *NoteHub) Notes(location *pb.Point) []*pb.RouteNote {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return copyNotes(h.notes[Serialize(location)])
+}
+
+// Publish stores the note and delivers it to every subscription watching its location, except the
+// sender (which may be nil).
+func (h *NoteHub) Publish(note *pb.RouteNote, sender *Subscription) {
+	key := Serialize(note.Location)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notes[key] = append(h.notes[key], note)
+	for sub := range h.watchers[key] {
+		if sub == sender {
+			continue
+		}
+		// never block the publisher: a full buffer means a slow consumer
+		select {
+		case sub.notes <- note:
+		default:
+			sub.dropped.Add(1)
+			if h.policy == Disconnect {
+				h.closeLocked(sub, ErrSlowConsumer)
+			}
+		}
+	}
+}
+
+// closeLocked removes the subscription from the watchers index and signals its end.
+// The hub's mutex must be held.
+func (h *NoteHub) closeLocked(sub *Subscription, reason error) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	sub.err = reason
+	for key := range sub.locations {
+		delete(h.watchers[key], sub)
+		if len(h.watchers[key]) == 0 {
+			delete(h.watchers, key)
+		}
+	}
+	close(sub.done)
+}
+
+// Subscription receives the notes published at the locations it watches.
+// The notes channel is never closed: use Done to know when the subscription has ended.
+type Subscription struct {
+	hub     *NoteHub
+	notes   chan *pb.RouteNote
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	// protected by hub.mu
+	locations map[string]struct{}
+	closed    bool
+	err       error
+}
+
+// Watch subscribes to the given location and returns the notes already stored there.
+// Watching an already watched location only returns its stored notes.
+func (s *Subscription) Watch(location *pb.Point) []*pb.RouteNote {
+	key := Serialize(location)
+
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if !s.closed {
+		if s.hub.watchers[key] == nil {
+			s.hub.watchers[key] = make(map[*Subscription]struct{})
+		}
+		s.hub.watchers[key][s] = struct{}{}
+		s.locations[key] = struct{}{}
+	}
+	// Note: this copy prevents the caller from reading the slice while other clients append to it.
+	// We don't need to do a deep copy, because elements in the slice are insert-only and never modified.
+	return copyNotes(s.hub.notes[key])
+}
+
+// Notes returns the channel delivering the published notes
+func (s *Subscription) Notes() <-chan *pb.RouteNote {
+	return s.notes
+}
+
+// Done is closed when the subscription ends, either by Close or by the hub
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the hub closed the subscription, or nil if it was closed by its owner or is still
+// open
+func (s *Subscription) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+// Dropped returns the number of notes this subscription missed because its buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the subscription. It is safe to call it several times.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.closeLocked(s, nil)
+}
+
+func copyNotes(notes []*pb.RouteNote) []*pb.RouteNote {
+	rn := make([]*pb.RouteNote, len(notes))
+	copy(rn, notes)
+	return rn
+}
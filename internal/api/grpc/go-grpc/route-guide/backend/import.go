@@ -0,0 +1,357 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportRoute reads the points of a route from a GPX, GeoJSON or CSV file. The format is given by
+// the extension of the file name: .gpx, .geojson (or .json) or .csv.
+func ImportRoute(name string, data []byte) ([]*pb.Point, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gpx":
+		return ImportGPX(data)
+	case ".geojson", ".json":
+		return ImportGeoJSON(data)
+	case ".csv":
+		return ImportCSV(data)
+	}
+	return nil, fmt.Errorf("unknown route format of %s: use a .gpx, .geojson or .csv file", name)
+}
+
+// ImportNotes reads route notes from a GPX, GeoJSON or CSV file. The format is given by the
+// extension of the file name, like for ImportRoute.
+func ImportNotes(name string, data []byte) ([]*pb.RouteNote, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gpx":
+		return ImportGPXNotes(data)
+	case ".geojson", ".json":
+		return ImportGeoJSONNotes(data)
+	case ".csv":
+		return ImportCSVNotes(data)
+	}
+	return nil, fmt.Errorf("unknown notes format of %s: use a .gpx, .geojson or .csv file", name)
+}
+
+// gpxInput holds the elements of a GPX file read by the import: the tracks, the routes and the
+// waypoints. Unlike the export, it does not check the GPX namespace.
+type gpxInput struct {
+	Tracks []struct {
+		Segments []gpxTrackSegment `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+	Waypoints []struct {
+		gpxPoint
+		Name        string `xml:"name"`
+		Description string `xml:"desc"`
+	} `xml:"wpt"`
+}
+
+// ImportGPX returns the points of the tracks of a GPX file, or of its routes when it has no track
+func ImportGPX(data []byte) ([]*pb.Point, error) {
+	var file gpxInput
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid GPX file: %w", err)
+	}
+	var points []*pb.Point
+	for _, track := range file.Tracks {
+		for _, segment := range track.Segments {
+			for _, gpxPoint := range segment.Points {
+				point, err := gpxPoint.toPoint()
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, point)
+			}
+		}
+	}
+	if len(points) == 0 {
+		for _, route := range file.Routes {
+			for _, gpxPoint := range route.Points {
+				point, err := gpxPoint.toPoint()
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, point)
+			}
+		}
+	}
+	if len(points) == 0 {
+		return nil, errors.New("the GPX file has no track or route point")
+	}
+	return points, nil
+}
+
+// ImportGPXNotes returns the waypoints of a GPX file as notes. The message of a note is the name of
+// its waypoint, or its description when it has no name.
+func ImportGPXNotes(data []byte) ([]*pb.RouteNote, error) {
+	var file gpxInput
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid GPX file: %w", err)
+	}
+	var notes []*pb.RouteNote
+	for _, waypoint := range file.Waypoints {
+		message := waypoint.Name
+		if message == "" {
+			message = waypoint.Description
+		}
+		point, err := waypoint.toPoint()
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, &pb.RouteNote{Location: point, Message: message})
+	}
+	if len(notes) == 0 {
+		return nil, errors.New("the GPX file has no waypoint")
+	}
+	return notes, nil
+}
+
+// toPoint returns the point of the lat and lon attributes, or a *geo.RangeError when they are out
+// of range
+func (p gpxPoint) toPoint() (*pb.Point, error) {
+	point, err := geo.NewPoint(p.Latitude, p.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GPX point lat=%v lon=%v: %w", p.Latitude, p.Longitude, err)
+	}
+	if p.Time != nil {
+		point.Timestamp = timestamppb.New(*p.Time)
+	}
+	return point, nil
+}
+
+// geoJSONInput is any GeoJSON object: a FeatureCollection, a Feature or a geometry.
+// The coordinates are decoded once the geometry type is known.
+type geoJSONInput struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSONInput         `json:"features"`
+	Geometry    *geoJSONInput          `json:"geometry"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+	Properties  map[string]interface{} `json:"properties"`
+}
+
+// ImportGeoJSON returns the points of the LineString, MultiLineString and Point geometries of a
+// GeoJSON object, in order. The "times" property of a Feature, as written by ExportGeoJSON, sets
+// the timestamps of its points.
+func ImportGeoJSON(data []byte) ([]*pb.Point, error) {
+	var object geoJSONInput
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON file: %w", err)
+	}
+	points, err := object.points()
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, errors.New("the GeoJSON file has no point")
+	}
+	return points, nil
+}
+
+func (o geoJSONInput) points() ([]*pb.Point, error) {
+	var positions [][]float64
+	switch o.Type {
+	case "FeatureCollection":
+		var points []*pb.Point
+		for _, feature := range o.Features {
+			featurePoints, err := feature.points()
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, featurePoints...)
+		}
+		return points, nil
+	case "Feature":
+		if o.Geometry == nil {
+			return nil, nil
+		}
+		points, err := o.Geometry.points()
+		if err != nil {
+			return nil, err
+		}
+		return points, setTimes(points, o.Properties["times"])
+	case "Point":
+		var position []float64
+		if err := json.Unmarshal(o.Coordinates, &position); err != nil {
+			return nil, fmt.Errorf("invalid Point coordinates: %w", err)
+		}
+		positions = [][]float64{position}
+	case "LineString", "MultiPoint":
+		if err := json.Unmarshal(o.Coordinates, &positions); err != nil {
+			return nil, fmt.Errorf("invalid %s coordinates: %w", o.Type, err)
+		}
+	case "MultiLineString":
+		var lines [][][]float64
+		if err := json.Unmarshal(o.Coordinates, &lines); err != nil {
+			return nil, fmt.Errorf("invalid MultiLineString coordinates: %w", err)
+		}
+		for _, line := range lines {
+			positions = append(positions, line...)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type: %q", o.Type)
+	}
+
+	points := make([]*pb.Point, 0, len(positions))
+	for _, position := range positions {
+		// GeoJSON positions are [longitude, latitude] arrays, with an optional altitude
+		if len(position) < 2 {
+			return nil, fmt.Errorf("invalid GeoJSON position: %v", position)
+		}
+		point, err := geo.NewPoint(position[1], position[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON position %v: %w", position, err)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// setTimes sets the timestamps of the points from a list of RFC 3339 times, ignoring the list if it
+// does not match the points
+func setTimes(points []*pb.Point, property interface{}) error {
+	times, ok := property.([]interface{})
+	if !ok || len(times) != len(points) {
+		return nil
+	}
+	for i, value := range times {
+		text, _ := value.(string)
+		timestamp, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return fmt.Errorf("invalid time %v: %w", value, err)
+		}
+		points[i].Timestamp = timestamppb.New(timestamp)
+	}
+	return nil
+}
+
+// ImportGeoJSONNotes returns the Point features of a GeoJSON object as notes, with the "message"
+// property as message
+func ImportGeoJSONNotes(data []byte) ([]*pb.RouteNote, error) {
+	var object geoJSONInput
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON file: %w", err)
+	}
+	features := object.Features
+	if object.Type == "Feature" {
+		features = []geoJSONInput{object}
+	}
+	var notes []*pb.RouteNote
+	for _, feature := range features {
+		if feature.Geometry == nil || feature.Geometry.Type != "Point" {
+			continue
+		}
+		points, err := feature.Geometry.points()
+		if err != nil {
+			return nil, err
+		}
+		message, _ := feature.Properties["message"].(string)
+		notes = append(notes, &pb.RouteNote{Location: points[0], Message: message})
+	}
+	if len(notes) == 0 {
+		return nil, errors.New("the GeoJSON file has no Point feature")
+	}
+	return notes, nil
+}
+
+// ImportCSV returns the points of a CSV file with the "latitude,longitude[,time]" columns, in
+// degrees and RFC 3339. The first line is skipped when it is a header.
+func ImportCSV(data []byte) ([]*pb.Point, error) {
+	var points []*pb.Point
+	err := readCSV(data, 2, func(record []string) error {
+		point, err := csvPoint(record)
+		if err != nil {
+			return err
+		}
+		if len(record) > 2 && record[2] != "" {
+			timestamp, err := time.Parse(time.RFC3339, record[2])
+			if err != nil {
+				return fmt.Errorf("invalid time %q", record[2])
+			}
+			point.Timestamp = timestamppb.New(timestamp)
+		}
+		points = append(points, point)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, errors.New("the CSV file has no point")
+	}
+	return points, nil
+}
+
+// ImportCSVNotes returns the notes of a CSV file with the "latitude,longitude,message" columns.
+// The first line is skipped when it is a header.
+func ImportCSVNotes(data []byte) ([]*pb.RouteNote, error) {
+	var notes []*pb.RouteNote
+	err := readCSV(data, 3, func(record []string) error {
+		point, err := csvPoint(record)
+		if err != nil {
+			return err
+		}
+		notes = append(notes, &pb.RouteNote{Location: point, Message: record[2]})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, errors.New("the CSV file has no note")
+	}
+	return notes, nil
+}
+
+// readCSV calls read with every record of the CSV data, which must have at least the given number
+// of columns. A first record whose latitude is not a number is a header, and is skipped.
+func readCSV(data []byte, columns int, read func(record []string) error) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid CSV file: %w", err)
+		}
+		if len(record) < columns {
+			return fmt.Errorf("line %d: %d columns, want at least %d", line, len(record), columns)
+		}
+		if _, err := strconv.ParseFloat(record[0], 64); err != nil && line == 1 {
+			continue
+		}
+		if err := read(record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// csvPoint reads the point of the first two columns, in degrees
+func csvPoint(record []string) (*pb.Point, error) {
+	latitude, err := strconv.ParseFloat(record[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude %q", record[0])
+	}
+	longitude, err := strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude %q", record[1])
+	}
+	return geo.NewPoint(latitude, longitude)
+}
@@ -0,0 +1,18 @@
+package backend
+
+import (
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"math/rand"
+)
+
+// CreateRandomPoints returns a route of random points, drawn from r.
+// r is not safe for concurrent use: give each goroutine its own.
+func CreateRandomPoints(r *rand.Rand) []*pb.Point {
+	pointCount := int(r.Int31n(100)) + 2 // Traverse at least two points
+	var points []*pb.Point
+	for i := 0; i < pointCount; i++ {
+		points = append(points, geo.RandomPoint(r))
+	}
+	return points
+}
@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"sort"
+	"time"
+)
+
+// Stop detection settings: a stop is a run of consecutive points staying within StopRadius metres
+// of its first point for at least MinStopDuration.
+const (
+	StopRadius      = 25.0
+	MinStopDuration = time.Minute
+)
+
+// PointTimes returns the time each point was reached: the client timestamps if every point has one,
+// or else the times the server received the points.
+// Mixing both would compare the client's clock with the server's one.
+func PointTimes(points []*pb.Point, receivedAt []time.Time) []time.Time {
+	times := make([]time.Time, len(points))
+	for i, point := range points {
+		if point.Timestamp == nil {
+			copy(times, receivedAt)
+			return times
+		}
+		times[i] = point.Timestamp.AsTime()
+	}
+	return times
+}
+
+// CheckTimestamps returns an error when the client timestamps of the points are not strictly
+// increasing: the segments would have a negative or zero duration. The points without timestamp are
+// skipped, since PointTimes ignores the timestamps of a route when a point has none.
+func CheckTimestamps(points []*pb.Point) error {
+	previous := -1
+	for i, point := range points {
+		if point.Timestamp == nil {
+			continue
+		}
+		if previous >= 0 && !point.Timestamp.AsTime().After(points[previous].Timestamp.AsTime()) {
+			return fmt.Errorf("point %d: timestamp %v is not after the timestamp %v of point %d",
+				i, point.Timestamp.AsTime(), points[previous].Timestamp.AsTime(), previous)
+		}
+		previous = i
+	}
+	return nil
+}
+
+// Summarize computes the RouteSummary of the points reached at the given times.
+// The features are counted each time the route passes on their location.
+func Summarize(points []*pb.Point, times []time.Time, features []*pb.Feature) *pb.RouteSummary {
+	summary := &pb.RouteSummary{PointCount: int32(len(points))}
+	if len(points) == 0 {
+		return summary
+	}
+
+	for _, point := range points {
+		for _, feature := range features {
+			if SameLocation(feature.Location, point) {
+				summary.FeatureCount++
+			}
+		}
+	}
+
+	for i := 1; i < len(points); i++ {
+		segment := &pb.RouteSegment{
+			StartIndex:     int32(i - 1),
+			DistanceMeters: Distance(points[i-1], points[i]),
+			ElapsedSeconds: times[i].Sub(times[i-1]).Seconds(),
+		}
+		if segment.ElapsedSeconds > 0 {
+			segment.Speed = segment.DistanceMeters / segment.ElapsedSeconds
+		}
+		if segment.Speed > summary.MaxSpeed {
+			summary.MaxSpeed = segment.Speed
+		}
+		summary.DistanceMeters += segment.DistanceMeters
+		summary.Segments = append(summary.Segments, segment)
+	}
+	summary.ElapsedSeconds = times[len(times)-1].Sub(times[0]).Seconds()
+	if summary.ElapsedSeconds > 0 {
+		summary.AverageSpeed = summary.DistanceMeters / summary.ElapsedSeconds
+	}
+	// keep filling the rounded fields for the clients which do not know the new ones
+	summary.Distance = int32(summary.DistanceMeters)
+	summary.ElapsedTime = int32(summary.ElapsedSeconds)
+
+	summary.BoundingBox = boundingBox(points)
+	summary.Stops = detectStops(points, times)
+	return summary
+}
+
+// boundingBox returns the smallest rectangle containing every point, from its south-west corner
+// to its north-east one. The rectangle crosses the antimeridian when it is the narrowest one, like
+// for a route from 179.9 to -179.9: its Lo corner is then east of its Hi corner, and
+// CrossesAntimeridian is set, see geo.Contains.
+func boundingBox(points []*pb.Point) *pb.Rectangle {
+	lo := &pb.Point{Latitude: points[0].Latitude}
+	hi := &pb.Point{Latitude: points[0].Latitude}
+	longitudes := make([]int64, 0, len(points))
+	for _, point := range points {
+		if point.Latitude < lo.Latitude {
+			lo.Latitude = point.Latitude
+		}
+		if point.Latitude > hi.Latitude {
+			hi.Latitude = point.Latitude
+		}
+		longitude := int64(point.Longitude)
+		// 180 is the same meridian as -180
+		if longitude == int64(geo.MaxLongitude*geo.E7) {
+			longitude = int64(geo.MinLongitude * geo.E7)
+		}
+		longitudes = append(longitudes, longitude)
+	}
+	sort.Slice(longitudes, func(i, j int) bool { return longitudes[i] < longitudes[j] })
+
+	// the rectangle leaves out the widest gap between the longitudes of the points, going east. The
+	// gap from the easternmost longitude back to the westernmost one crosses the antimeridian: when it
+	// is the widest, the rectangle does not cross it.
+	last := len(longitudes) - 1
+	widest := longitudes[0] + int64(360*geo.E7) - longitudes[last]
+	lo.Longitude, hi.Longitude = int32(longitudes[0]), int32(longitudes[last])
+	for i := 0; i < last; i++ {
+		if gap := longitudes[i+1] - longitudes[i]; gap > widest {
+			widest = gap
+			lo.Longitude, hi.Longitude = int32(longitudes[i+1]), int32(longitudes[i])
+		}
+	}
+	return &pb.Rectangle{Lo: lo, Hi: hi, CrossesAntimeridian: lo.Longitude > hi.Longitude}
+}
+
+// detectStops looks for the runs of consecutive points staying within StopRadius of their first
+// point for at least MinStopDuration
+func detectStops(points []*pb.Point, times []time.Time) []*pb.RouteStop {
+	var stops []*pb.RouteStop
+	for start := 0; start < len(points); {
+		end := start
+		for end+1 < len(points) && Distance(points[start], points[end+1]) <= StopRadius {
+			end++
+		}
+		duration := times[end].Sub(times[start])
+		if end == start || duration < MinStopDuration {
+			start++
+			continue
+		}
+		stops = append(stops, &pb.RouteStop{
+			Location:        &pb.Point{Latitude: points[start].Latitude, Longitude: points[start].Longitude},
+			StartIndex:      int32(start),
+			EndIndex:        int32(end),
+			DurationSeconds: duration.Seconds(),
+		})
+		start = end + 1
+	}
+	return stops
+}
@@ -0,0 +1,218 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/client"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"golang_starter/internal/stats"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The bench drives a mix of the four RouteGuide methods from concurrent workers, and measures the
+// latency of every call. Each worker loops on: pick a method at random, following the weights of
+// the mix, then call it and wait for its end.
+
+// The methods of the mix
+const (
+	GetFeature   = "GetFeature"
+	ListFeatures = "ListFeatures"
+	RecordRoute  = "RecordRoute"
+	RouteChat    = "RouteChat"
+)
+
+// Methods lists the methods in the order of the reports
+var Methods = []string{GetFeature, ListFeatures, RecordRoute, RouteChat}
+
+// DefaultMix calls every method equally. RecordRoute writes the routes to the database of the
+// server: leave it out with a weight of 0 to bench a production server.
+var DefaultMix = map[string]int{GetFeature: 1, ListFeatures: 1, RecordRoute: 1, RouteChat: 1}
+
+// Config holds the settings of a run
+type Config struct {
+	// Workers is the number of concurrent workers, each waiting for its call before the next one
+	Workers int
+	// Duration bounds the run when Requests is zero
+	Duration time.Duration
+	// Requests is the total number of calls of the run, shared by the workers
+	Requests int
+	// Mix holds the weight of each method. The methods without weight are not called.
+	Mix map[string]int
+}
+
+// Report holds the results of a run, per method
+type Report struct {
+	Workers int            `json:"workers"`
+	Elapsed time.Duration  `json:"-"`
+	Methods []MethodReport `json:"methods"`
+}
+
+// MethodReport holds the results of a method
+type MethodReport struct {
+	Method string `json:"method"`
+	// Throughput is the number of calls per second
+	Throughput float64       `json:"throughput"`
+	Latency    stats.Summary `json:"latency"`
+}
+
+// ParseMix reads a mix like "GetFeature=4,RecordRoute=1". The method names are not case sensitive,
+// and a weight of 0 leaves the method out.
+func ParseMix(value string) (map[string]int, error) {
+	mix := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		name, weight, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid mix item %q: want METHOD=WEIGHT", item)
+		}
+		method := ""
+		for _, m := range Methods {
+			if strings.EqualFold(m, name) {
+				method = m
+			}
+		}
+		if method == "" {
+			return nil, fmt.Errorf("unknown method %q: use %s", name, strings.Join(Methods, ", "))
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight %q of %s: want a non-negative integer", weight, method)
+		}
+		mix[method] = w
+	}
+	return mix, nil
+}
+
+// Run calls the server until Requests calls are done, or until Duration has elapsed, or until the
+// context is done. The calls interrupted by the end of the run are not reported.
+func Run(ctx context.Context, c *client.Client, config Config) (*Report, error) {
+	if config.Workers < 1 {
+		return nil, errors.New("the bench needs at least one worker")
+	}
+	if config.Requests <= 0 && config.Duration <= 0 {
+		return nil, errors.New("the bench needs a number of requests or a duration")
+	}
+	pick, err := picker(config.Mix)
+	if err != nil {
+		return nil, err
+	}
+	if config.Requests <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	recorders := make(map[string]*stats.Recorder, len(Methods))
+	for _, method := range Methods {
+		recorders[method] = &stats.Recorder{}
+	}
+	var started atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < config.Workers; i++ {
+		wg.Add(1)
+		// rand.Rand is not safe for concurrent use: each worker has its own
+		r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if config.Requests > 0 && started.Add(1) > int64(config.Requests) {
+					return
+				}
+				method := pick(r)
+				callStart := time.Now()
+				err := call(ctx, c, method, r)
+				latency := time.Since(callStart)
+				if ctx.Err() != nil {
+					// the run has ended during the call
+					return
+				}
+				recorders[method].Record(latency, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Workers: config.Workers, Elapsed: time.Since(start)}
+	for _, method := range Methods {
+		summary := recorders[method].Summary()
+		if summary.Count == 0 {
+			continue
+		}
+		report.Methods = append(report.Methods, MethodReport{
+			Method:     method,
+			Throughput: float64(summary.Count) / report.Elapsed.Seconds(),
+			Latency:    summary,
+		})
+	}
+	return report, nil
+}
+
+// picker returns a function choosing a method at random, following the weights of the mix
+func picker(mix map[string]int) (func(r *rand.Rand) string, error) {
+	total := 0
+	for method, weight := range mix {
+		if _, found := DefaultMix[method]; !found {
+			return nil, fmt.Errorf("unknown method %q", method)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return nil, errors.New("the mix has no method to call")
+	}
+	return func(r *rand.Rand) string {
+		n := r.Intn(total)
+		for _, method := range Methods {
+			if n < mix[method] {
+				return method
+			}
+			n -= mix[method]
+		}
+		// unreachable: n < total
+		return Methods[len(Methods)-1]
+	}, nil
+}
+
+// noteLocations are the locations of the RouteChat notes: the server keeps the notes of every
+// location, a random location per call would add a location on each of them
+var noteLocations = func() []*pb.Point {
+	r := rand.New(rand.NewSource(1))
+	locations := make([]*pb.Point, 8)
+	for i := range locations {
+		locations[i] = geo.RandomPoint(r)
+	}
+	return locations
+}()
+
+// call calls a method with random arguments
+func call(ctx context.Context, c *client.Client, method string, r *rand.Rand) error {
+	switch method {
+	case GetFeature:
+		feature := backend.Features[r.Intn(len(backend.Features))]
+		_, err := c.GetFeature(ctx, feature.Location)
+		return err
+	case ListFeatures:
+		// a rectangle of about 2 km around a feature
+		location := backend.Features[r.Intn(len(backend.Features))].Location
+		_, err := c.ListFeatures(ctx, &pb.Rectangle{
+			Lo: &pb.Point{Latitude: location.Latitude - 100000, Longitude: location.Longitude - 100000},
+			Hi: &pb.Point{Latitude: location.Latitude + 100000, Longitude: location.Longitude + 100000},
+		})
+		return err
+	case RecordRoute:
+		_, err := c.RecordRoute(ctx, backend.CreateRandomPoints(r))
+		return err
+	case RouteChat:
+		location := noteLocations[r.Intn(len(noteLocations))]
+		note := &pb.RouteNote{Location: location, Message: "bench"}
+		return c.RouteChat(ctx, []*pb.RouteNote{note}, true, func(*pb.RouteNote) {})
+	}
+	return fmt.Errorf("unknown method %q", method)
+}
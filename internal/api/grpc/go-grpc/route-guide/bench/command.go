@@ -0,0 +1,121 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang_starter/internal/api/grpc/go-grpc/auth"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/client"
+	"golang_starter/internal/api/grpc/go-grpc/tlsconfig"
+	"golang_starter/internal/stats"
+	"google.golang.org/grpc"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// NewCommand creates the route-guide-bench command line
+func NewCommand() *cobra.Command {
+	var host, token, mix, output string
+	var port int
+	var tls tlsconfig.Client
+	config := Config{}
+	timeout := client.DefaultTimeout
+
+	cmd := &cobra.Command{
+		Use:   "route-guide-bench",
+		Short: "Load a RouteGuide server and report the latencies of its methods",
+		Long: "Call a mix of the RouteGuide methods from concurrent workers, then report the throughput " +
+			"and the latency percentiles of each method. RecordRoute writes to the database of the server: " +
+			"leave it out of the mix with RecordRoute=0 on a production server.",
+		Example: "  route-guide-bench --workers 50 --duration 30s --mix GetFeature=4,RecordRoute=1,RouteChat=1",
+		Args:    cobra.NoArgs,
+		// the usage is not helpful when the run fails
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return fmt.Errorf("unknown output format %q: use table or json", output)
+			}
+			var err error
+			if config.Mix, err = ParseMix(mix); err != nil {
+				return err
+			}
+			credentials, err := tls.DialOption()
+			if err != nil {
+				return err
+			}
+			opts := []grpc.DialOption{credentials}
+			if token != "" {
+				opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials{Token: token, AllowInsecure: !tls.UsesTLS()}))
+			}
+			c, err := client.Dial(net.JoinHostPort(host, strconv.Itoa(port)), opts...)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			c.Timeout = timeout
+
+			report, err := Run(cmd.Context(), c, config)
+			if err != nil {
+				return err
+			}
+			if output == "json" {
+				return WriteJSON(cmd.OutOrStdout(), report)
+			}
+			return WriteTable(cmd.OutOrStdout(), report)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&host, "host", "localhost", "The server address")
+	flags.IntVar(&port, "port", 8080, "The server port")
+	flags.BoolVar(&tls.Enabled, "tls", false, "Connect with TLS, checking the server with the system CAs unless --tls-ca is set")
+	flags.StringVar(&tls.CAFile, "tls-ca", "", "The CA file checking the server certificate. Enables TLS")
+	flags.StringVar(&token, "token", "", "The bearer token sent with every call")
+	flags.DurationVar(&timeout, "timeout", client.DefaultTimeout, "The deadline of each call")
+	flags.IntVarP(&config.Workers, "workers", "w", 10, "The number of concurrent workers")
+	flags.DurationVarP(&config.Duration, "duration", "d", 10*time.Second, "The duration of the run, unless --requests is set")
+	flags.IntVarP(&config.Requests, "requests", "n", 0, "The total number of calls. The run lasts --duration when not set")
+	flags.StringVar(&mix, "mix", "GetFeature=1,ListFeatures=1,RecordRoute=1,RouteChat=1", "The weight of each method")
+	flags.StringVarP(&output, "output", "o", "table", "The output format. Can be [table, json]")
+	return cmd
+}
+
+// Execute is the entry point of the command
+func Execute() {
+	if err := NewCommand().Execute(); err != nil {
+		// cobra has already printed the error
+		os.Exit(1)
+	}
+}
+
+// WriteTable writes the report as a table, with the latencies in milliseconds
+func WriteTable(w io.Writer, report *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tCALLS\tERRORS\tCALLS/S\tMEAN (ms)\tP50 (ms)\tP95 (ms)\tP99 (ms)\tMAX (ms)")
+	for _, method := range report.Methods {
+		l := method.Latency
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\n", method.Method, l.Count, l.Errors,
+			method.Throughput, stats.Milliseconds(l.Mean), stats.Milliseconds(l.P50), stats.Milliseconds(l.P95),
+			stats.Milliseconds(l.P99), stats.Milliseconds(l.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d workers during %v\n", report.Workers, report.Elapsed.Round(time.Millisecond))
+	return err
+}
+
+// WriteJSON writes the report as an indented JSON object, with the latencies in milliseconds
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		*Report
+		ElapsedSeconds float64 `json:"elapsedSeconds"`
+	}{report, report.Elapsed.Seconds()})
+}
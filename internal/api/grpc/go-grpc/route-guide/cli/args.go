@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"regexp"
+	"strings"
+)
+
+// negativeNumber matches the arguments starting like a negative number, such as -33.86,151.2
+var negativeNumber = regexp.MustCompile(`^-\.?[0-9]`)
+
+// SetArgs sets the arguments of a command line created by NewCommand, like cobra.Command.SetArgs.
+// The arguments starting like a negative number are read as points rather than as shorthand flags,
+// so that `feature -33.86,151.2` needs no "--": no flag of the command line starts with a digit.
+func SetArgs(cmd *cobra.Command, args []string) {
+	cmd.SetArgs(escapeNegativeNumbers(cmd, args))
+}
+
+// escapeNegativeNumbers moves the positional arguments of the command after a "--" when one of them
+// starts like a negative number, keeping their order. The command names stay first and the flags
+// keep their values.
+func escapeNegativeNumbers(root *cobra.Command, args []string) []string {
+	found := false
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		found = found || negativeNumber.MatchString(arg)
+	}
+	if !found {
+		return args
+	}
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		return args
+	}
+
+	var options, positionals []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positionals = append(positionals, args[i+1:]...)
+			i = len(args)
+		case !strings.HasPrefix(arg, "-") || arg == "-" || negativeNumber.MatchString(arg):
+			positionals = append(positionals, arg)
+		default:
+			options = append(options, arg)
+			if takesValue(cmd, arg) && i+1 < len(args) {
+				i++
+				options = append(options, args[i])
+			}
+		}
+	}
+	// the names of the sub commands are the first positional arguments
+	names := len(strings.Fields(cmd.CommandPath())) - 1
+	if names > len(positionals) {
+		return args
+	}
+	escaped := append(append([]string{}, positionals[:names]...), options...)
+	return append(append(escaped, "--"), positionals[names:]...)
+}
+
+// takesValue tells if the flag argument is followed by its value, like --output json or -o json
+func takesValue(cmd *cobra.Command, arg string) bool {
+	var flag *pflag.Flag
+	if name := strings.TrimPrefix(arg, "--"); name != arg {
+		if strings.Contains(name, "=") {
+			return false
+		}
+		if flag = cmd.LocalFlags().Lookup(name); flag == nil {
+			flag = cmd.InheritedFlags().Lookup(name)
+		}
+	} else if len(arg) == 2 {
+		if flag = cmd.LocalFlags().ShorthandLookup(arg[1:]); flag == nil {
+			flag = cmd.InheritedFlags().ShorthandLookup(arg[1:])
+		}
+	}
+	return flag != nil && flag.NoOptDefVal == ""
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"os"
+	"os/signal"
+)
+
+var noteHeaders = []string{"LATITUDE", "LONGITUDE", "MESSAGE"}
+
+// chatCommand calls RouteChat
+func (a *app) chatCommand() *cobra.Command {
+	var file, message string
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "chat [LATITUDE,LONGITUDE]",
+		Short: "Post notes and print the notes of their locations",
+		Long: "Post a note at a point, or the notes read from a GPX (waypoints), GeoJSON (Point features " +
+			"with a 'message' property) or CSV ('latitude,longitude,message' columns) file.\n" +
+			"The notes already posted at the same locations are printed. A note without message only " +
+			"subscribes to its location.",
+		Example: "  route-guide-client chat 48.85837,2.294481 --message 'Eiffel tower !!'\n" +
+			"  route-guide-client chat --file notes.csv --follow",
+		Args: cobra.MaximumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var notes []*pb.RouteNote
+			switch {
+			case len(args) == 1 && file == "":
+				point, err := parsePoint(args[0])
+				if err != nil {
+					return err
+				}
+				notes = []*pb.RouteNote{{Location: point, Message: message}}
+			case len(args) == 0 && file != "":
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				if notes, err = backend.ImportNotes(file, data); err != nil {
+					return err
+				}
+			default:
+				return errors.New("give either a point or a --file")
+			}
+
+			ctx := cmd.Context()
+			if follow {
+				// the chat only ends on Ctrl-C
+				var stop func()
+				ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+				defer stop()
+			}
+			p := a.printer(cmd)
+			var printErr error
+			err := a.client.RouteChat(ctx, notes, !follow, func(note *pb.RouteNote) {
+				row := []string{degrees(note.Location.GetLatitude()), degrees(note.Location.GetLongitude()), note.Message}
+				if err := p.item(note, noteHeaders, row); err != nil && printErr == nil {
+					printErr = err
+				}
+			})
+			if follow && ctx.Err() != nil {
+				// interrupted by the user
+				return printErr
+			}
+			if err != nil {
+				return err
+			}
+			return printErr
+		},
+	}
+	cmd.Flags().StringVarP(&message, "message", "m", "", "The message of the note posted at the point")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "The GPX, GeoJSON or CSV file of the notes to post")
+	cmd.Flags().BoolVar(&follow, "follow", false, "Keep printing the notes posted by the other clients until interrupted")
+	return cmd
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang_starter/internal/api/grpc/go-grpc/auth"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/client"
+	"golang_starter/internal/api/grpc/go-grpc/tlsconfig"
+	"golang_starter/internal/tracing"
+	"google.golang.org/grpc"
+	"net"
+	"os"
+	"strconv"
+)
+
+// app holds the values of the global flags, and the client opened for the command
+type app struct {
+	host   string
+	port   int
+	tls    tlsconfig.Client
+	token  string
+	output string
+
+	client *client.Client
+	// shutdownTracing sends the last spans
+	shutdownTracing func(context.Context) error
+}
+
+// NewCommand creates the route-guide-client command line.
+// Unlike a cli declared with package variables, every call returns new commands with new flags, so
+// that the tests can run several command lines. Set its arguments with SetArgs, to read the negative
+// coordinates.
+func NewCommand() *cobra.Command {
+	cmd, _ := newCommand()
+	return cmd
+}
+
+// newCommand creates the command line, and returns the app closed by Execute
+func newCommand() (*cobra.Command, *app) {
+	a := &app{}
+	timeout := client.DefaultTimeout
+
+	rootCmd := &cobra.Command{
+		Use:   "route-guide-client",
+		Short: "Call the methods of a RouteGuide server",
+		Long: "Call the methods of a RouteGuide server.\n" +
+			"The points are given as 'latitude,longitude' in degrees, like 48.85837,2.294481 or -33.86,151.2.",
+		// the usage is not helpful when the call fails
+		SilenceUsage: true,
+
+		// open the connection before running any sub command
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutput(a.output); err != nil {
+				return err
+			}
+			// the traces are exported with the OTEL_TRACES_EXPORTER environment variable
+			var err error
+			if a.shutdownTracing, err = tracing.Setup(cmd.Context(), tracing.ConfigFromEnv("route-guide-client")); err != nil {
+				return err
+			}
+			// the flags are only read here, once they are parsed
+			serverAddr := net.JoinHostPort(a.host, strconv.Itoa(a.port))
+			opts, err := a.dialOptions()
+			if err != nil {
+				return err
+			}
+			if a.client, err = client.Dial(serverAddr, opts...); err != nil {
+				return err
+			}
+			a.client.Timeout = timeout
+			return nil
+		},
+		// cobra skips it when the command fails, Execute closes the app in any case
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return a.close()
+		},
+	}
+
+	// global flags, accessed by the sub commands
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&a.host, "host", "localhost", "The server address")
+	flags.IntVar(&a.port, "port", 8080, "The server port")
+	flags.BoolVar(&a.tls.Enabled, "tls", false, "Connect with TLS, checking the server with the system CAs unless --tls-ca is set")
+	flags.StringVar(&a.tls.CAFile, "tls-ca", "", "The CA file checking the server certificate. Enables TLS")
+	flags.StringVar(&a.tls.CertFile, "tls-cert", "", "The client certificate file, for mutual TLS, with --tls-key. Enables TLS")
+	flags.StringVar(&a.tls.KeyFile, "tls-key", "", "The client private key file, for mutual TLS, with --tls-cert. Enables TLS")
+	flags.StringVar(&a.tls.ServerName, "tls-server-name", "", "Overrides the server name checked in its certificate. Enables TLS")
+	flags.StringVar(&a.token, "token", "", "The bearer token sent with every call")
+	flags.DurationVar(&timeout, "timeout", client.DefaultTimeout, "The deadline of each call")
+	flags.StringVarP(&a.output, "output", "o", "table", "The output format. Can be [table, json, yaml]")
+
+	// sub commands, one per method
+	rootCmd.AddCommand(
+		a.featureCommand(),
+		a.featuresCommand(),
+		a.recordCommand(),
+		a.chatCommand(),
+		a.routeCommand(),
+		a.routesCommand(),
+		a.exportCommand(),
+		a.healthCommand(),
+	)
+	return rootCmd, a
+}
+
+// close sends the last spans and closes the client. It does nothing the second time.
+func (a *app) close() error {
+	var err error
+	if a.shutdownTracing != nil {
+		err = a.shutdownTracing(context.Background())
+		a.shutdownTracing = nil
+	}
+	if a.client != nil {
+		if closeErr := a.client.Close(); err == nil {
+			err = closeErr
+		}
+		a.client = nil
+	}
+	return err
+}
+
+// dialOptions returns the dial options setting the credentials of the flags
+func (a *app) dialOptions() ([]grpc.DialOption, error) {
+	credentials, err := a.tls.DialOption()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{credentials}
+	if a.token != "" {
+		// the token is only sent in clear text when TLS is disabled on purpose
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials{
+			Token:         a.token,
+			AllowInsecure: !a.tls.UsesTLS(),
+		}))
+	}
+	return opts, nil
+}
+
+// printer returns the printer of the output flag, writing to the command output
+func (a *app) printer(cmd *cobra.Command) *printer {
+	return &printer{out: cmd.OutOrStdout(), format: a.output}
+}
+
+// Execute is the entry point of the cli
+func Execute() {
+	cmd, a := newCommand()
+	SetArgs(cmd, os.Args[1:])
+	err := cmd.Execute()
+	// the failed commands are not closed by PersistentPostRunE
+	if closeErr := a.close(); closeErr != nil {
+		fmt.Fprintln(os.Stderr, "Error:", closeErr)
+		err = closeErr
+	}
+	if err != nil {
+		// cobra has already printed the error
+		os.Exit(1)
+	}
+}
+
+// checkOutput checks the value of the output flag
+func checkOutput(output string) error {
+	switch output {
+	case tableOutput, jsonOutput, yamlOutput:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q: use table, json or yaml", output)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"google.golang.org/protobuf/proto"
+)
+
+var featureHeaders = []string{"NAME", "LATITUDE", "LONGITUDE"}
+
+func featureRow(feature *pb.Feature) []string {
+	return []string{feature.Name, degrees(feature.Location.GetLatitude()), degrees(feature.Location.GetLongitude())}
+}
+
+// featureCommand calls GetFeature
+func (a *app) featureCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "feature LATITUDE,LONGITUDE",
+		Short:   "Get the feature at a point",
+		Example: "  route-guide-client feature 48.85837,2.294481",
+		Args:    cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			point, err := parsePoint(args[0])
+			if err != nil {
+				return err
+			}
+			feature, err := a.client.GetFeature(cmd.Context(), point)
+			if err != nil {
+				return err
+			}
+			return a.printer(cmd).one(feature, table{headers: featureHeaders, rows: [][]string{featureRow(feature)}})
+		},
+	}
+}
+
+// featuresCommand calls ListFeatures
+func (a *app) featuresCommand() *cobra.Command {
+	var crossesAntimeridian bool
+	cmd := &cobra.Command{
+		Use:   "features LATITUDE,LONGITUDE LATITUDE,LONGITUDE",
+		Short: "List the features inside a rectangle",
+		Long: "List the features inside the rectangle whose opposite corners are given, in any order. With " +
+			"--crosses-antimeridian, the rectangle spans east from the first corner to the second one: it crosses the " +
+			"antimeridian when the first corner is east of the second one.",
+		Example: "  route-guide-client features 48.860806,2.290437 48.855989,2.297761\n" +
+			"  route-guide-client features --crosses-antimeridian -15,177 -20,-178",
+		Args: cobra.ExactArgs(2),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rectangle, err := parseRectangle(args[0], args[1], crossesAntimeridian)
+			if err != nil {
+				return err
+			}
+			features, err := a.client.ListFeatures(cmd.Context(), rectangle)
+			if err != nil {
+				return err
+			}
+			messages := make([]proto.Message, 0, len(features))
+			rows := table{headers: featureHeaders}
+			for _, feature := range features {
+				messages = append(messages, feature)
+				rows.rows = append(rows.rows, featureRow(feature))
+			}
+			return a.printer(cmd).list(messages, rows)
+		},
+	}
+	cmd.Flags().BoolVar(&crossesAntimeridian, "crosses-antimeridian", false, "The rectangle spans east from the first corner to the second one")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCommand calls the Check method of the health service
+func (a *app) healthCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "health [SERVICE]",
+		Short: "Check the health of the server",
+		Long: "Check the health of a service, or of the whole server when no service is given.\n" +
+			"The command fails when the service is not serving.",
+		Example: "  route-guide-client health\n  route-guide-client health main.RouteGuide",
+		Args:    cobra.MaximumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service := ""
+			if len(args) == 1 {
+				service = args[0]
+			}
+			servingStatus, err := a.client.HealthCheck(cmd.Context(), service)
+			if err != nil {
+				return err
+			}
+			response := &healthpb.HealthCheckResponse{Status: servingStatus}
+			err = a.printer(cmd).one(response, table{
+				headers: []string{"SERVICE", "STATUS"},
+				rows:    [][]string{{service, servingStatus.String()}},
+			})
+			if err != nil {
+				return err
+			}
+			if servingStatus != healthpb.HealthCheckResponse_SERVING {
+				return fmt.Errorf("the server is not healthy: %v", servingStatus)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats of the results
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+	yamlOutput  = "yaml"
+)
+
+// printer writes the results of the calls in the output format.
+// The json and yaml formats follow the protobuf JSON mapping, and the table format prints the given
+// columns.
+type printer struct {
+	out    io.Writer
+	format string
+	// streamed tells if an item has already been written by item
+	streamed bool
+}
+
+// table holds the columns printed by the table format
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// one prints a single result
+func (p *printer) one(message proto.Message, rows table) error {
+	switch p.format {
+	case jsonOutput, yamlOutput:
+		value, err := toValue(message)
+		if err != nil {
+			return err
+		}
+		return p.encode(value)
+	}
+	return p.table(rows)
+}
+
+// list prints a list of results
+func (p *printer) list(messages []proto.Message, rows table) error {
+	switch p.format {
+	case jsonOutput, yamlOutput:
+		// an empty list is printed as an empty array rather than null
+		values := make([]interface{}, 0, len(messages))
+		for _, message := range messages {
+			value, err := toValue(message)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		return p.encode(values)
+	}
+	return p.table(rows)
+}
+
+// item prints a result received from a stream, as soon as it is received: a table row, a JSON line,
+// or a YAML document
+func (p *printer) item(message proto.Message, headers []string, row []string) error {
+	first := !p.streamed
+	p.streamed = true
+	switch p.format {
+	case jsonOutput:
+		data, err := protojson.Marshal(message)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "%s\n", data)
+		return err
+	case yamlOutput:
+		if !first {
+			if _, err := fmt.Fprintln(p.out, "---"); err != nil {
+				return err
+			}
+		}
+		return p.one(message, table{})
+	}
+	rows := table{rows: [][]string{row}}
+	if first {
+		rows.headers = headers
+	}
+	return p.table(rows)
+}
+
+func (p *printer) encode(value interface{}) error {
+	if p.format == yamlOutput {
+		encoder := yaml.NewEncoder(p.out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(p.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// table prints aligned columns, separated by at least two spaces
+func (p *printer) table(rows table) error {
+	writer := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if len(rows.headers) > 0 {
+		fmt.Fprintln(writer, strings.Join(rows.headers, "\t"))
+	}
+	for _, row := range rows.rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// toValue converts the message into the generic JSON value of its protobuf JSON mapping, so that it
+// can be encoded in JSON or YAML
+func toValue(message proto.Message) (interface{}, error) {
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
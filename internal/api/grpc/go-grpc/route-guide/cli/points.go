@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"strconv"
+	"strings"
+)
+
+// parsePoint reads a 'latitude,longitude' argument in degrees
+func parsePoint(arg string) (*pb.Point, error) {
+	lat, long, found := strings.Cut(arg, ",")
+	if !found {
+		return nil, fmt.Errorf("invalid point %q: want latitude,longitude", arg)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude %q: want degrees", lat)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(long), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude %q: want degrees", long)
+	}
+	return geo.NewPoint(latitude, longitude)
+}
+
+// parseRectangle reads the two opposite corners of a rectangle. When crossesAntimeridian is set, the
+// rectangle crosses the antimeridian when lo is east of hi.
+func parseRectangle(lo string, hi string, crossesAntimeridian bool) (*pb.Rectangle, error) {
+	loPoint, err := parsePoint(lo)
+	if err != nil {
+		return nil, err
+	}
+	hiPoint, err := parsePoint(hi)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Rectangle{Lo: loPoint, Hi: hiPoint, CrossesAntimeridian: crossesAntimeridian}, nil
+}
+
+// degrees formats an E7 coordinate in degrees
+func degrees(coordinate int32) string {
+	return strconv.FormatFloat(geo.FromE7(coordinate), 'f', -1, 64)
+}
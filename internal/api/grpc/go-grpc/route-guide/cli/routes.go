@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var summaryHeaders = []string{"ROUTE", "POINTS", "FEATURES", "DISTANCE (m)", "ELAPSED (s)", "AVERAGE SPEED (m/s)", "MAX SPEED (m/s)", "STOPS"}
+
+func summaryRow(summary *pb.RouteSummary) []string {
+	return []string{
+		strconv.FormatUint(summary.GetRouteId(), 10),
+		strconv.Itoa(int(summary.GetPointCount())),
+		strconv.Itoa(int(summary.GetFeatureCount())),
+		fmt.Sprintf("%.1f", summary.GetDistanceMeters()),
+		fmt.Sprintf("%.1f", summary.GetElapsedSeconds()),
+		fmt.Sprintf("%.2f", summary.GetAverageSpeed()),
+		fmt.Sprintf("%.2f", summary.GetMaxSpeed()),
+		strconv.Itoa(len(summary.GetStops())),
+	}
+}
+
+var routeHeaders = []string{"ID", "POINTS", "START", "END", "DISTANCE (m)"}
+
+func routeRow(route *pb.Route) []string {
+	return []string{
+		strconv.FormatUint(route.Id, 10),
+		strconv.Itoa(len(route.Points)),
+		route.StartTime.AsTime().Format(time.RFC3339),
+		route.EndTime.AsTime().Format(time.RFC3339),
+		fmt.Sprintf("%.1f", route.Summary.GetDistanceMeters()),
+	}
+}
+
+// recordCommand calls RecordRoute
+func (a *app) recordCommand() *cobra.Command {
+	var random bool
+	cmd := &cobra.Command{
+		Use:   "record FILE",
+		Short: "Record a route read from a GPX, GeoJSON or CSV file",
+		Long: "Record a route read from a GPX, GeoJSON or CSV file, depending on the file extension.\n" +
+			"The CSV file has the 'latitude,longitude[,time]' columns, in degrees and RFC 3339.",
+		Example: "  route-guide-client record route.gpx\n  route-guide-client record --random",
+
+		Args: func(cmd *cobra.Command, args []string) error {
+			if random {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var points []*pb.Point
+			if random {
+				points = backend.CreateRandomPoints(rand.New(rand.NewSource(time.Now().UnixNano())))
+			} else {
+				data, err := os.ReadFile(args[0])
+				if err != nil {
+					return err
+				}
+				if points, err = backend.ImportRoute(args[0], data); err != nil {
+					return err
+				}
+			}
+			summary, err := a.client.RecordRoute(cmd.Context(), points)
+			if err != nil {
+				return err
+			}
+			return a.printer(cmd).one(summary, table{headers: summaryHeaders, rows: [][]string{summaryRow(summary)}})
+		},
+	}
+	cmd.Flags().BoolVar(&random, "random", false, "Record a route of random points rather than a file")
+	return cmd
+}
+
+// routeCommand calls GetRoute
+func (a *app) routeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "route ID",
+		Short: "Get a recorded route",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid route id %q", args[0])
+			}
+			route, err := a.client.GetRoute(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			return a.printer(cmd).one(route, table{headers: routeHeaders, rows: [][]string{routeRow(route)}})
+		},
+	}
+}
+
+// routesCommand calls ListRoutes
+func (a *app) routesCommand() *cobra.Command {
+	var pageSize int32
+	var pageToken, from, to string
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "List the recorded routes",
+		Long: "List a page of the recorded routes. The next page token is printed on the error output " +
+			"when there are more routes.",
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request := &pb.ListRoutesRequest{PageSize: pageSize, PageToken: pageToken}
+			var err error
+			if request.StartFrom, err = parseTime(from); err != nil {
+				return err
+			}
+			if request.StartTo, err = parseTime(to); err != nil {
+				return err
+			}
+			response, err := a.client.ListRoutes(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+			p := a.printer(cmd)
+			if p.format != tableOutput {
+				// the JSON and YAML outputs hold the next page token
+				return p.one(response, table{})
+			}
+			messages := make([]proto.Message, 0, len(response.Routes))
+			rows := table{headers: routeHeaders}
+			for _, route := range response.Routes {
+				messages = append(messages, route)
+				rows.rows = append(rows.rows, routeRow(route))
+			}
+			if response.NextPageToken != "" {
+				cmd.PrintErrln("Next page token:", response.NextPageToken)
+			}
+			return p.list(messages, rows)
+		},
+	}
+	cmd.Flags().Int32Var(&pageSize, "page-size", 0, "The maximum number of routes. The server chooses it when not set")
+	cmd.Flags().StringVar(&pageToken, "page-token", "", "The token of the page, printed by the previous call")
+	cmd.Flags().StringVar(&from, "from", "", "Only list the routes started at or after this RFC 3339 time")
+	cmd.Flags().StringVar(&to, "to", "", "Only list the routes started before this RFC 3339 time")
+	return cmd
+}
+
+// exportCommand calls ExportRoute
+func (a *app) exportCommand() *cobra.Command {
+	var format, file string
+	cmd := &cobra.Command{
+		Use:   "export ID",
+		Short: "Export a recorded route as GPX or GeoJSON",
+		Long:  "Export a recorded route as GPX or GeoJSON. The --output flag does not apply.",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid route id %q", args[0])
+			}
+			value, found := pb.ExportFormat_value[strings.ToUpper(format)]
+			if !found {
+				return fmt.Errorf("unknown export format %q: use gpx or geojson", format)
+			}
+			response, err := a.client.ExportRoute(cmd.Context(), id, pb.ExportFormat(value))
+			if err != nil {
+				return err
+			}
+			if file != "" {
+				return os.WriteFile(file, response.Data, 0644)
+			}
+			_, err = cmd.OutOrStdout().Write(response.Data)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "gpx", "The export format. Can be [gpx, geojson]")
+	cmd.Flags().StringVar(&file, "file", "", "The file written. The route is written on the standard output when not set")
+	return cmd
+}
+
+// parseTime reads an optional RFC 3339 time
+func parseTime(value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.New("invalid time " + value + ": want RFC 3339, like 2006-01-02T15:04:05Z")
+	}
+	return timestamppb.New(t), nil
+}
@@ -3,171 +3,234 @@ package client
 import (
 	"context"
 	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
-	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"io"
-	"log"
-	"math/rand"
 	"time"
 )
 
-func FatalIfError(err error) {
+// DefaultTimeout is the deadline given to the calls whose context has none
+const DefaultTimeout = 10 * time.Second
+
+// ServiceConfig is the default service configuration of the connections opened by Dial.
+// The gRPC library retries the idempotent methods when the server is unavailable, with an exponential
+// backoff. RecordRoute and RouteChat are not retried: the server stores what they send.
+// A server streaming method like ListFeatures is only retried until the first feature is received.
+const ServiceConfig = `{
+	"methodConfig": [{
+		"name": [
+			{"service": "main.RouteGuide", "method": "GetFeature"},
+			{"service": "main.RouteGuide", "method": "ListFeatures"},
+			{"service": "main.RouteGuide", "method": "GetRoute"},
+			{"service": "main.RouteGuide", "method": "ListRoutes"},
+			{"service": "main.RouteGuide", "method": "ExportRoute"},
+			{"service": "grpc.health.v1.Health", "method": "Check"}
+		],
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// Client calls the methods of a RouteGuide server.
+// Every method returns the error of the call, with its gRPC status (see status.Code).
+// A Client is safe for concurrent use.
+type Client struct {
+	// Timeout is the deadline of the calls whose context has none. RouteChat never gets one, since
+	// the chat lasts as long as the caller wants. Zero disables it.
+	Timeout time.Duration
+
+	routeGuide pb.RouteGuideClient
+	health     healthpb.HealthClient
+	// connection is only set when the Client opened it, to close it with Close
+	connection *grpc.ClientConn
+}
+
+// New creates a client using the given connection, like a *grpc.ClientConn. The connection stays
+// owned by the caller: Close does not close it.
+func New(connection grpc.ClientConnInterface) *Client {
+	return &Client{
+		Timeout:    DefaultTimeout,
+		routeGuide: pb.NewRouteGuideClient(connection),
+		health:     healthpb.NewHealthClient(connection),
+	}
+}
+
+// Dial opens a connection to the server and creates a client using it.
+// Use the dial options to set the auth credentials (for example, TLS, GCE credentials, or JWT
+// credentials). Without any transport credentials, the connection is not encrypted.
+// The connection uses ServiceConfig unless the server or the options give another one. The calls are
+// traced with the global tracer provider, see the tracing package.
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	defaults := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(ServiceConfig),
+	}, tracing.DialOptions()...)
+	// the last options override the first ones
+	connection, err := grpc.Dial(target, append(defaults, opts...)...)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	c := New(connection)
+	c.connection = connection
+	return c, nil
 }
 
-func randomPoint(r *rand.Rand) *pb.Point {
-	lat := (r.Int31n(180) - 90) * 1e7
-	long := (r.Int31n(360) - 180) * 1e7
-	return &pb.Point{Latitude: lat, Longitude: long}
+// Close closes the connection opened by Dial
+func (c *Client) Close() error {
+	if c.connection == nil {
+		return nil
+	}
+	return c.connection.Close()
 }
 
-func createRandomPoints() []*pb.Point {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	pointCount := int(r.Int31n(100)) + 2 // Traverse at least two points
-	var points []*pb.Point
-	for i := 0; i < pointCount; i++ {
-		points = append(points, randomPoint(r))
+// withTimeout gives the client Timeout to the context if it has no deadline yet
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.Timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	return points
+	return context.WithTimeout(ctx, c.Timeout)
 }
 
-// getFeature simply calls the simple RPC method GetFeature
-// pass a context.Context object which lets us change our RPC’s behavior if necessary, such as
-// time-out/cancel an RPC in flight.
-func sendGetFeature(client pb.RouteGuideClient, ctx context.Context, point *pb.Point) (*pb.Feature, error) {
-	ft, err := client.GetFeature(ctx, point)
-	return ft, err
+// GetFeature returns the feature at the given point. The feature is named "Unknown" when there is
+// none.
+// The context lets the caller change the RPC's behavior, such as time-out/cancel an RPC in flight.
+func (c *Client) GetFeature(ctx context.Context, point *pb.Point) (*pb.Feature, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.routeGuide.GetFeature(ctx, point)
 }
 
-// sendListFeatures sends a rectangle and receives a stream of points inside this rectangle
-func sendListFeatures(client pb.RouteGuideClient, ctx context.Context, rectangle *pb.Rectangle) []*pb.Feature {
+// ListFeatures returns the features inside the rectangle
+func (c *Client) ListFeatures(ctx context.Context, rectangle *pb.Rectangle) ([]*pb.Feature, error) {
+	// cancelling the context also closes the stream when we stop reading it early
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	stream, err := c.routeGuide.ListFeatures(ctx, rectangle)
+	if err != nil {
+		return nil, err
+	}
+
 	// init the result slice of features
 	var features []*pb.Feature
-	// open the stream
-	stream, err := client.ListFeatures(ctx, rectangle)
-	FatalIfError(err)
-
-	// compute the stream for each time clock
 	for {
-		streamFeature, err := stream.Recv()
-		// if error returns EOF, simply close the stream
+		feature, err := stream.Recv()
+		// the server has sent every feature
 		if err == io.EOF {
-			break
+			return features, nil
 		}
-		// any other error should fail the code
-		FatalIfError(err)
-		// extract the feature from the proto message
-		features = append(features, streamFeature)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, feature)
 	}
-	err = stream.CloseSend()
-	FatalIfError(err)
-	return features
 }
 
-// sendRecordRoute sends a stream of points and the server registers them
-func sendRecordRoute(client pb.RouteGuideClient, ctx context.Context, points []*pb.Point) *pb.RouteSummary {
-	stream, err := client.RecordRoute(ctx)
-	FatalIfError(err)
-
+// RecordRoute sends the points of a route and returns the summary computed by the server
+func (c *Client) RecordRoute(ctx context.Context, points []*pb.Point) (*pb.RouteSummary, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	stream, err := c.routeGuide.RecordRoute(ctx)
+	if err != nil {
+		return nil, err
+	}
 	for _, point := range points {
 		if err := stream.Send(point); err != nil {
-			log.Fatalf("%v.Send(%v) = %v", stream, point, err)
+			// Send returns io.EOF when the server has ended the call: CloseAndRecv returns its status
+			if err == io.EOF {
+				break
+			}
+			return nil, err
 		}
 	}
 	// This is a client stream, so we get the response by ending the stream from the client side
 	// Since the server is waiting for the client to finish sending requests, it returns its response at
-	//the end of the communication.
-	reply, err := stream.CloseAndRecv()
-	FatalIfError(err)
-
-	return reply
+	// the end of the communication.
+	return stream.CloseAndRecv()
 }
 
-func sendRouteChat(client pb.RouteGuideClient, ctx context.Context) {
-	stream, err := client.RouteChat(ctx)
-	FatalIfError(err)
-
-	// channel to wait for incoming values
-	waitChannel := make(chan struct{})
+// RouteChat posts the notes, and calls receive with every note sent back by the server: the notes
+// already posted at the same locations, then the notes posted there by the other clients.
+// The chat ends when the context is done, or once the notes are posted when closeSend is set.
+func (c *Client) RouteChat(ctx context.Context, notes []*pb.RouteNote, closeSend bool, receive func(*pb.RouteNote)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	// cancelling the context closes the stream, and ends the receiving goroutine
+	defer cancel()
+	stream, err := c.routeGuide.RouteChat(ctx)
+	if err != nil {
+		return err
+	}
 
-	// go routine to listen to incoming data
+	// goroutine to listen to incoming data. Only this goroutine calls receive.
+	received := make(chan error, 1)
 	go func() {
 		for {
-			in, err := stream.Recv()
-			if err == io.EOF {
-				close(waitChannel)
+			note, err := stream.Recv()
+			if err != nil {
+				received <- err
 				return
 			}
-			FatalIfError(err)
-			log.Printf("Point: (%d, %d) has a message: '%s'", in.Location.Latitude,
-				in.Location.Latitude, in.Message)
+			receive(note)
 		}
 	}()
 
-	for _, note := range backend.SavedNotes {
-		err := stream.Send(note)
-		FatalIfError(err)
+	for _, note := range notes {
+		if err := stream.Send(note); err != nil {
+			if err == io.EOF {
+				// the server has ended the call: Recv returns its status
+				break
+			}
+			return err
+		}
+	}
+	if closeSend {
+		if err := stream.CloseSend(); err != nil {
+			return err
+		}
 	}
-	stream.CloseSend()
 
-	// blocking code execution until waitChannel receives a data or is closed
-	<-waitChannel
+	// blocking code execution until the server closes the stream or the context is done
+	if err := <-received; err != io.EOF {
+		return err
+	}
+	return nil
 }
 
-func start(serverAddr string, method string) {
-	// You can use DialOptions to set the auth credentials (for example, TLS, GCE credentials, or JWT
-	// credentials) in grpc.Dial when a service requires them.
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-
-	// Init the connection
-	connection, err := grpc.Dial(serverAddr, opts...)
-	FatalIfError(err)
-
-	// Will close the connection after the 'main' function has ended
-	defer connection.Close()
-
-	// Init the client using our definition from the protobuf and the initialised connection
-	client := pb.NewRouteGuideClient(connection)
-	log.Println("Open client to", serverAddr)
-
-	switch {
-	case method == "getfeature":
-
-		// Eiffel tower point
-		point := &pb.Point{Latitude: 48858370, Longitude: 2294481}
-		log.Println("Get feature for point:", point)
-		feature, err := sendGetFeature(client, context.Background(), point)
-		FatalIfError(err)
-		log.Println(feature)
-	case method == "listfeatures":
-		log.Println("Send listFeatures message")
-		// Rectangle points that should contain effeil tower point
-		p1 := &pb.Point{Latitude: 48860806, Longitude: 2290437}
-		p2 := &pb.Point{Latitude: 48855989, Longitude: 2297761}
-		r := &pb.Rectangle{Lo: p1, Hi: p2}
-		features := sendListFeatures(client, context.Background(), r)
-		log.Printf("Features in Rectangle '%v' are: '%v'", r, features)
-	case method == "sendrecordroute":
-		// create a random number of random points of 10 points
-		routePoints := createRandomPoints()
-		log.Printf("Traversing %d points.", len(routePoints))
-		route := sendRecordRoute(client, context.Background(), routePoints)
-		log.Printf("Recorded route: {points: %d, distance: %dm, features: %d}",
-			route.PointCount, route.Distance, route.FeatureCount)
-	case method == "routechat":
-		sendRouteChat(client, context.Background())
-	case method == "debug":
-		log.Println("It works")
-	default:
-		log.Fatal(&backend.UnknownGrpcMethodError{Name: "getfeature"})
-	}
+// GetRoute returns a route recorded by RecordRoute
+func (c *Client) GetRoute(ctx context.Context, id uint64) (*pb.Route, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.routeGuide.GetRoute(ctx, &pb.GetRouteRequest{Id: id})
+}
 
+// ListRoutes returns a page of the recorded routes
+func (c *Client) ListRoutes(ctx context.Context, request *pb.ListRoutesRequest) (*pb.ListRoutesResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.routeGuide.ListRoutes(ctx, request)
 }
 
-func Run(serverAddr string, method string) {
-	start(serverAddr, method)
+// ExportRoute returns a recorded route in the given format
+func (c *Client) ExportRoute(ctx context.Context, id uint64, format pb.ExportFormat) (*pb.ExportRouteResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.routeGuide.ExportRoute(ctx, &pb.ExportRouteRequest{Id: id, Format: format})
+}
+
+// HealthCheck asks the health service of the server for the status of a service, or of the whole
+// server when the service is empty
+func (c *Client) HealthCheck(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	response, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN, err
+	}
+	return response.Status, nil
 }
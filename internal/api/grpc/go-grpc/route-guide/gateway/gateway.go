@@ -0,0 +1,379 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"golang_starter/internal/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// The gateway is a REST/JSON front of the RouteGuide service, for the clients which cannot speak
+// gRPC, like the web browsers. It translates each HTTP request into a call to a gRPC server, in the
+// same way as grpc-gateway: the messages follow the protobuf JSON mapping of route_guide.proto, and
+// the gRPC status codes are translated into HTTP status codes.
+//
+//	GET  /v1/features?lat=&lng=                                 GetFeature
+//	GET  /v1/features:list?lo.lat=&lo.lng=&hi.lat=&hi.lng=      ListFeatures, with
+//	                      [&crossesAntimeridian=true]
+//	POST /v1/routes                                             RecordRoute, with a JSON array of points
+//	POST /v1/notes                                              RouteChat, posting a single note
+//	GET  /v1/notes:watch?lat=&lng=                              RouteChat, as server-sent events
+//
+// The lat and lng query parameters are in degrees, while the points of the messages are in the E7
+// representation of the Point message.
+
+// gateway forwards the HTTP requests to the gRPC server
+type gateway struct {
+	client pb.RouteGuideClient
+	// done ends the watch streams
+	done <-chan struct{}
+}
+
+// NewRouter creates the gin router of the gateway, calling the gRPC server through the connection.
+// The watch streams, which only end when the client disconnects, also end once ctx is done: cancel
+// it when the HTTP server shuts down, so that they do not hold the shutdown until its deadline.
+func NewRouter(ctx context.Context, connection grpc.ClientConnInterface) *gin.Engine {
+	g := &gateway{client: pb.NewRouteGuideClient(connection), done: ctx.Done()}
+	router := gin.Default()
+	// the span of the request is the parent of the spans of the gRPC calls
+	router.Use(tracing.Middleware())
+
+	// CORS config
+	// CONFIGURE IT BEFORE ROUTES !
+	config := cors.DefaultConfig()
+	config.AllowAllOrigins = true
+	config.AddAllowHeaders("Authorization")
+	router.Use(cors.New(config))
+
+	// gin cannot declare a path like /v1/features:list next to /v1/features, since a colon starts a
+	// path parameter. The resource parameter holds the collection and its custom method, if any.
+	router.GET("/v1/:resource", dispatch(map[string]gin.HandlerFunc{
+		"features":      g.getFeature,
+		"features:list": g.listFeatures,
+		"notes:watch":   g.watchNotes,
+	}))
+	router.POST("/v1/:resource", dispatch(map[string]gin.HandlerFunc{
+		"routes": g.recordRoute,
+		"notes":  g.postNote,
+	}))
+	return router
+}
+
+// dispatch calls the handler of the resource, or answers 404
+func dispatch(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler, found := handlers[c.Param("resource")]
+		if !found {
+			writeError(c, status.Error(codes.NotFound, "unknown resource: "+c.Param("resource")))
+			return
+		}
+		handler(c)
+	}
+}
+
+// getFeature calls GetFeature
+func (g *gateway) getFeature(c *gin.Context) {
+	point, err := queryPoint(c, "lat", "lng")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	feature, err := g.client.GetFeature(outgoingContext(c), point)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	writeMessage(c, http.StatusOK, feature)
+}
+
+// listFeatures calls ListFeatures, and answers with all the features once they are received
+func (g *gateway) listFeatures(c *gin.Context) {
+	lo, err := queryPoint(c, "lo.lat", "lo.lng")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	hi, err := queryPoint(c, "hi.lat", "hi.lng")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	rectangle := &pb.Rectangle{Lo: lo, Hi: hi}
+	if value, found := c.GetQuery("crossesAntimeridian"); found {
+		if rectangle.CrossesAntimeridian, err = strconv.ParseBool(value); err != nil {
+			writeError(c, status.Errorf(codes.InvalidArgument, "invalid crossesAntimeridian query parameter %q: want true or false", value))
+			return
+		}
+	}
+	stream, err := g.client.ListFeatures(outgoingContext(c), rectangle)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var features []proto.Message
+	for {
+		feature, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		features = append(features, feature)
+	}
+	writeList(c, "features", features)
+}
+
+// recordRoute calls RecordRoute with the JSON array of points of the request body
+func (g *gateway) recordRoute(c *gin.Context) {
+	var body []json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+		writeError(c, status.Error(codes.InvalidArgument, "the body must be a JSON array of points: "+err.Error()))
+		return
+	}
+	points := make([]*pb.Point, len(body))
+	for i, data := range body {
+		points[i] = &pb.Point{}
+		if err := protojson.Unmarshal(data, points[i]); err != nil {
+			writeError(c, status.Errorf(codes.InvalidArgument, "invalid point %d: %v", i, err))
+			return
+		}
+	}
+
+	stream, err := g.client.RecordRoute(outgoingContext(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	for _, point := range points {
+		if err := stream.Send(point); err != nil {
+			// the server has ended the call: CloseAndRecv returns its status
+			break
+		}
+	}
+	summary, err := stream.CloseAndRecv()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	writeMessage(c, http.StatusCreated, summary)
+}
+
+// postNote calls RouteChat to post the note of the request body, and answers with the notes already
+// posted at the same location
+func (g *gateway) postNote(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	note := &pb.RouteNote{}
+	if err := protojson.Unmarshal(body, note); err != nil {
+		writeError(c, status.Error(codes.InvalidArgument, "invalid note: "+err.Error()))
+		return
+	}
+	stream, err := g.client.RouteChat(outgoingContext(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := stream.Send(note); err == nil {
+		// the server ends the chat once the client has nothing more to send
+		stream.CloseSend()
+	}
+	var notes []proto.Message
+	for {
+		received, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		notes = append(notes, received)
+	}
+	writeList(c, "notes", notes)
+}
+
+// watchNotes calls RouteChat to subscribe to a location, and sends the notes as server-sent events
+// until the client disconnects or the gateway shuts down: the notes already posted there first, then
+// the new ones.
+func (g *gateway) watchNotes(c *gin.Context) {
+	point, err := queryPoint(c, "lat", "lng")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	// the call is cancelled when the client disconnects or the gateway shuts down
+	ctx, cancel := context.WithCancel(outgoingContext(c))
+	defer cancel()
+	go func() {
+		select {
+		case <-g.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	stream, err := g.client.RouteChat(ctx)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	// a note without message only subscribes to its location
+	if err := stream.Send(&pb.RouteNote{Location: point}); err != nil && err != io.EOF {
+		writeError(c, err)
+		return
+	}
+
+	// send the headers right away, so that the client knows that it is subscribed even when no note
+	// has been posted yet
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	// the events are flushed one by one by c.Stream
+	c.Stream(func(w io.Writer) bool {
+		note, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) != codes.Canceled {
+				data, _ := json.Marshal(errorBody(err))
+				c.SSEvent("error", string(data))
+			}
+			return false
+		}
+		data, err := protojson.Marshal(note)
+		if err != nil {
+			return false
+		}
+		c.SSEvent("note", string(data))
+		return true
+	})
+}
+
+// outgoingContext returns the context of the gRPC calls. The calls are cancelled with the request,
+// and forward its Authorization header as the authorization metadata.
+func outgoingContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authorization)
+	}
+	return ctx
+}
+
+// queryPoint reads a point from two query parameters in degrees
+func queryPoint(c *gin.Context, latitudeKey string, longitudeKey string) (*pb.Point, error) {
+	latitude, err := queryDegrees(c, latitudeKey, geo.CheckLatitude)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := queryDegrees(c, longitudeKey, geo.CheckLongitude)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Point{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// queryDegrees reads a coordinate query parameter in degrees, checks its range, and returns it as an
+// E7 coordinate
+func queryDegrees(c *gin.Context, key string, check func(float64) error) (int32, error) {
+	value, found := c.GetQuery(key)
+	if !found {
+		return 0, status.Errorf(codes.InvalidArgument, "missing %s query parameter", key)
+	}
+	degrees, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %s query parameter %q: want degrees", key, value)
+	}
+	if err := check(degrees); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %s query parameter: %v", key, err)
+	}
+	return geo.ToE7(degrees), nil
+}
+
+// writeMessage answers with the JSON mapping of the message
+func writeMessage(c *gin.Context, code int, message proto.Message) {
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Data(code, "application/json", data)
+}
+
+// writeList answers with a JSON object holding the messages in an array field, like the List
+// responses of the proto file
+func writeList(c *gin.Context, field string, messages []proto.Message) {
+	list := make([]json.RawMessage, 0, len(messages))
+	for _, message := range messages {
+		data, err := protojson.Marshal(message)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		list = append(list, data)
+	}
+	c.JSON(http.StatusOK, gin.H{field: list})
+}
+
+// writeError answers with the HTTP status of the gRPC status of the error, and a JSON body holding
+// the gRPC code and message
+func writeError(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(HTTPStatus(status.Code(err)), errorBody(err))
+}
+
+// errorBody is the JSON body of an error, in the format of the google.rpc.Status message
+func errorBody(err error) gin.H {
+	s, ok := status.FromError(err)
+	if !ok {
+		s = status.New(codes.Unknown, err.Error())
+	}
+	return gin.H{"code": s.Code(), "message": s.Message()}
+}
+
+// HTTPStatus returns the HTTP status code matching a gRPC status code, following the mapping of
+// google/rpc/code.proto
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// 499 Client Closed Request is not a standard status code
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	// Unknown, Internal, DataLoss
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,244 @@
+// Package geo converts and checks the coordinates of the RouteGuide points, and computes distances,
+// bearings and destinations on the earth sphere.
+//
+// The protobuf Point holds its coordinates in the E7 representation, ie. degrees * 10^7 rounded to
+// the nearest integer. The functions of this package take and return degrees, and convert them with
+// ToE7 and FromE7.
+package geo
+
+import (
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"math"
+	"math/rand"
+)
+
+// E7 is the factor between the degrees and the E7 coordinates
+const E7 = 1e7
+
+// EarthRadius is the mean radius of the earth, in metres
+const EarthRadius = 6371000.0
+
+// The valid coordinates, in degrees. A longitude of 180 is the same meridian as -180.
+const (
+	MinLatitude  = -90.0
+	MaxLatitude  = 90.0
+	MinLongitude = -180.0
+	MaxLongitude = 180.0
+)
+
+// RangeError tells that a coordinate is out of its valid range, or is not a number
+type RangeError struct {
+	// Name is "latitude" or "longitude"
+	Name  string
+	Value float64
+	Min   float64
+	Max   float64
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("invalid %s %v: want degrees between %v and %v", e.Name, e.Value, e.Min, e.Max)
+}
+
+// ToE7 converts a coordinate in degrees into an E7 coordinate
+func ToE7(degrees float64) int32 {
+	return int32(math.Round(degrees * E7))
+}
+
+// FromE7 converts an E7 coordinate into degrees
+func FromE7(coordinate int32) float64 {
+	return float64(coordinate) / E7
+}
+
+// CheckLatitude returns a *RangeError when the latitude is not a number of degrees between -90 and 90
+func CheckLatitude(latitude float64) error {
+	return checkRange("latitude", latitude, MinLatitude, MaxLatitude)
+}
+
+// CheckLongitude returns a *RangeError when the longitude is not a number of degrees between -180
+// and 180
+func CheckLongitude(longitude float64) error {
+	return checkRange("longitude", longitude, MinLongitude, MaxLongitude)
+}
+
+func checkRange(name string, value float64, min float64, max float64) error {
+	// the comparisons are false for NaN
+	if !(value >= min && value <= max) {
+		return &RangeError{Name: name, Value: value, Min: min, Max: max}
+	}
+	return nil
+}
+
+// NewPoint returns the point at the given coordinates in degrees, or a *RangeError when they are out
+// of range
+func NewPoint(latitude float64, longitude float64) (*pb.Point, error) {
+	if err := CheckLatitude(latitude); err != nil {
+		return nil, err
+	}
+	if err := CheckLongitude(longitude); err != nil {
+		return nil, err
+	}
+	return &pb.Point{Latitude: ToE7(latitude), Longitude: ToE7(longitude)}, nil
+}
+
+// Validate returns a *RangeError when the coordinates of the point are out of range. A nil point
+// is the point (0, 0).
+func Validate(point *pb.Point) error {
+	if err := CheckLatitude(FromE7(point.GetLatitude())); err != nil {
+		return err
+	}
+	return CheckLongitude(FromE7(point.GetLongitude()))
+}
+
+// ValidateRectangle returns a *RangeError when a corner of the rectangle is out of range
+func ValidateRectangle(rectangle *pb.Rectangle) error {
+	if err := Validate(rectangle.GetLo()); err != nil {
+		return err
+	}
+	return Validate(rectangle.GetHi())
+}
+
+// NormalizeLongitude wraps a longitude in degrees into [-180, 180)
+func NormalizeLongitude(longitude float64) float64 {
+	longitude = math.Mod(longitude+180, 360)
+	if longitude < 0 {
+		longitude += 360
+	}
+	return longitude - 180
+}
+
+// Contains tells if the point is inside the rectangle, borders included.
+// The corners can be in any order. With CrossesAntimeridian, the rectangle spans east from the
+// longitude of Lo to the longitude of Hi: when Lo is east of Hi, the rectangle crosses the
+// antimeridian, like the Fiji islands rectangle from (-15, 177) to (-20, -178).
+func Contains(rectangle *pb.Rectangle, point *pb.Point) bool {
+	lo, hi := rectangle.GetLo(), rectangle.GetHi()
+	bottom := min(lo.GetLatitude(), hi.GetLatitude())
+	top := max(lo.GetLatitude(), hi.GetLatitude())
+	if point.GetLatitude() < bottom || point.GetLatitude() > top {
+		return false
+	}
+
+	west, east := lo.GetLongitude(), hi.GetLongitude()
+	if !rectangle.GetCrossesAntimeridian() {
+		west, east = min(west, east), max(west, east)
+	} else if west > east {
+		// the rectangle crosses the antimeridian: it holds the longitudes east of its west side, and
+		// west of its east side
+		return point.GetLongitude() >= west || point.GetLongitude() <= east
+	}
+	// 180 and -180 are the same meridian, on both sides of the rectangle
+	for _, longitude := range sameMeridian(point.GetLongitude()) {
+		if longitude >= west && longitude <= east {
+			return true
+		}
+	}
+	return false
+}
+
+// sameMeridian returns the E7 longitudes of the meridian of the given one: 180 and -180 are the
+// antimeridian
+func sameMeridian(longitude int32) []int32 {
+	switch antimeridian := int32(MaxLongitude * E7); longitude {
+	case antimeridian, -antimeridian:
+		return []int32{antimeridian, -antimeridian}
+	}
+	return []int32{longitude}
+}
+
+func min(a int32, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int32, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// radians returns the coordinates of the point in radians
+func radians(point *pb.Point) (latitude float64, longitude float64) {
+	return toRadians(FromE7(point.GetLatitude())), toRadians(FromE7(point.GetLongitude()))
+}
+
+// Distance calculates the distance in metres between two points using the "haversine" formula.
+// The formula is based on http://mathforum.org/library/drmath/view/51879.html.
+func Distance(p1 *pb.Point, p2 *pb.Point) float64 {
+	lat1, lng1 := radians(p1)
+	lat2, lng2 := radians(p2)
+	dlat := lat2 - lat1
+	dlng := lng2 - lng1
+
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*
+			math.Sin(dlng/2)*math.Sin(dlng/2)
+	// rounding errors can push 'a' slightly out of [0, 1] for antipodal points, and the square roots
+	// would then return NaN
+	a = math.Max(0, math.Min(1, a))
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return EarthRadius * c
+}
+
+// Bearing returns the initial bearing of the great circle from one point to another, in degrees
+// clockwise from the north in [0, 360). The bearing changes along the way, except on the meridians
+// and the equator. It is 0 when both points are the same.
+// See https://www.movable-type.co.uk/scripts/latlong.html for the formulas.
+func Bearing(from *pb.Point, to *pb.Point) float64 {
+	lat1, lng1 := radians(from)
+	lat2, lng2 := radians(to)
+	dlng := lng2 - lng1
+
+	y := math.Sin(dlng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlng)
+	bearing := math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+	// -0 and rounding errors just below 0 would give 360
+	if bearing >= 360 {
+		bearing = 0
+	}
+	return bearing
+}
+
+// Destination returns the point reached by travelling the distance in metres from a point, along
+// the great circle starting with the bearing in degrees. The longitude of the destination is
+// normalized into [-180, 180).
+func Destination(from *pb.Point, bearing float64, distance float64) *pb.Point {
+	lat1, lng1 := radians(from)
+	theta := toRadians(bearing)
+	delta := distance / EarthRadius // angular distance
+
+	sinLat2 := math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta)
+	lat2 := math.Asin(math.Max(-1, math.Min(1, sinLat2)))
+	y := math.Sin(theta) * math.Sin(delta) * math.Cos(lat1)
+	x := math.Cos(delta) - math.Sin(lat1)*sinLat2
+	lng2 := lng1 + math.Atan2(y, x)
+
+	return &pb.Point{
+		Latitude:  ToE7(toDegrees(lat2)),
+		Longitude: ToE7(NormalizeLongitude(toDegrees(lng2))),
+	}
+}
+
+// RandomPoint returns a valid point drawn uniformly among the E7 coordinates, from r.
+// r is not safe for concurrent use: give each goroutine its own.
+func RandomPoint(r *rand.Rand) *pb.Point {
+	latitudes := int64((MaxLatitude - MinLatitude) * E7)
+	longitudes := int64((MaxLongitude - MinLongitude) * E7)
+	return &pb.Point{
+		// both poles are valid latitudes, while 180 is the same longitude as -180
+		Latitude:  int32(r.Int63n(latitudes+1) + int64(MinLatitude*E7)),
+		Longitude: int32(r.Int63n(longitudes) + int64(MinLongitude*E7)),
+	}
+}
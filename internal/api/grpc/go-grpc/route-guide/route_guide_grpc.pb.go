@@ -46,7 +46,17 @@ type RouteGuideClient interface {
 	// they like: for example, the server could wait to receive all the client messages before writing its
 	// responses, or it could alternately read a message then write a message, or some other combination of
 	// reads and writes. The order of messages in each stream is preserved.
+	// Every note subscribes the client to its location: the server answers with the notes already
+	// posted there, then forwards in real time the notes posted there by the other clients.
+	// A note with an empty message only subscribes to its location.
 	RouteChat(ctx context.Context, opts ...grpc.CallOption) (RouteGuide_RouteChatClient, error)
+	// routes for the 'Route' objects stored by RecordRoute.
+	// GetRoute returns a stored route with all its points.
+	GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*Route, error)
+	// ListRoutes returns a page of stored routes, optionally filtered by start time.
+	ListRoutes(ctx context.Context, in *ListRoutesRequest, opts ...grpc.CallOption) (*ListRoutesResponse, error)
+	// ExportRoute returns a stored route as a GPX or GeoJSON file.
+	ExportRoute(ctx context.Context, in *ExportRouteRequest, opts ...grpc.CallOption) (*ExportRouteResponse, error)
 }
 
 type routeGuideClient struct {
@@ -163,6 +173,33 @@ func (x *routeGuideRouteChatClient) Recv() (*RouteNote, error) {
 	return m, nil
 }
 
+func (c *routeGuideClient) GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*Route, error) {
+	out := new(Route)
+	err := c.cc.Invoke(ctx, "/main.RouteGuide/GetRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routeGuideClient) ListRoutes(ctx context.Context, in *ListRoutesRequest, opts ...grpc.CallOption) (*ListRoutesResponse, error) {
+	out := new(ListRoutesResponse)
+	err := c.cc.Invoke(ctx, "/main.RouteGuide/ListRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routeGuideClient) ExportRoute(ctx context.Context, in *ExportRouteRequest, opts ...grpc.CallOption) (*ExportRouteResponse, error) {
+	out := new(ExportRouteResponse)
+	err := c.cc.Invoke(ctx, "/main.RouteGuide/ExportRoute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RouteGuideServer is the server API for RouteGuide service.
 // All implementations must embed UnimplementedRouteGuideServer
 // for forward compatibility
@@ -191,7 +228,17 @@ type RouteGuideServer interface {
 	// they like: for example, the server could wait to receive all the client messages before writing its
 	// responses, or it could alternately read a message then write a message, or some other combination of
 	// reads and writes. The order of messages in each stream is preserved.
+	// Every note subscribes the client to its location: the server answers with the notes already
+	// posted there, then forwards in real time the notes posted there by the other clients.
+	// A note with an empty message only subscribes to its location.
 	RouteChat(RouteGuide_RouteChatServer) error
+	// routes for the 'Route' objects stored by RecordRoute.
+	// GetRoute returns a stored route with all its points.
+	GetRoute(context.Context, *GetRouteRequest) (*Route, error)
+	// ListRoutes returns a page of stored routes, optionally filtered by start time.
+	ListRoutes(context.Context, *ListRoutesRequest) (*ListRoutesResponse, error)
+	// ExportRoute returns a stored route as a GPX or GeoJSON file.
+	ExportRoute(context.Context, *ExportRouteRequest) (*ExportRouteResponse, error)
 	mustEmbedUnimplementedRouteGuideServer()
 }
 
@@ -211,6 +258,15 @@ func (UnimplementedRouteGuideServer) RecordRoute(RouteGuide_RecordRouteServer) e
 func (UnimplementedRouteGuideServer) RouteChat(RouteGuide_RouteChatServer) error {
 	return status.Errorf(codes.Unimplemented, "method RouteChat not implemented")
 }
+func (UnimplementedRouteGuideServer) GetRoute(context.Context, *GetRouteRequest) (*Route, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoute not implemented")
+}
+func (UnimplementedRouteGuideServer) ListRoutes(context.Context, *ListRoutesRequest) (*ListRoutesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRoutes not implemented")
+}
+func (UnimplementedRouteGuideServer) ExportRoute(context.Context, *ExportRouteRequest) (*ExportRouteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportRoute not implemented")
+}
 func (UnimplementedRouteGuideServer) mustEmbedUnimplementedRouteGuideServer() {}
 
 // UnsafeRouteGuideServer may be embedded to opt out of forward compatibility for this service.
@@ -315,6 +371,60 @@ func (x *routeGuideRouteChatServer) Recv() (*RouteNote, error) {
 	return m, nil
 }
 
+func _RouteGuide_GetRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouteGuideServer).GetRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/main.RouteGuide/GetRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouteGuideServer).GetRoute(ctx, req.(*GetRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouteGuide_ListRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouteGuideServer).ListRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/main.RouteGuide/ListRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouteGuideServer).ListRoutes(ctx, req.(*ListRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouteGuide_ExportRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouteGuideServer).ExportRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/main.RouteGuide/ExportRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouteGuideServer).ExportRoute(ctx, req.(*ExportRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RouteGuide_ServiceDesc is the grpc.ServiceDesc for RouteGuide service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -326,6 +436,18 @@ var RouteGuide_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetFeature",
 			Handler:    _RouteGuide_GetFeature_Handler,
 		},
+		{
+			MethodName: "GetRoute",
+			Handler:    _RouteGuide_GetRoute_Handler,
+		},
+		{
+			MethodName: "ListRoutes",
+			Handler:    _RouteGuide_ListRoutes_Handler,
+		},
+		{
+			MethodName: "ExportRoute",
+			Handler:    _RouteGuide_ExportRoute_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
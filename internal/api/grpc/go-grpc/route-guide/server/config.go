@@ -0,0 +1,242 @@
+package server
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"golang_starter/internal/api/grpc/go-grpc/auth"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/tlsconfig"
+	"golang_starter/internal/tracing"
+	"strings"
+	"time"
+)
+
+// EnvPrefix prefixes the environment variables overriding the configuration, like
+// ROUTE_GUIDE_PORT or ROUTE_GUIDE_CHAT_BUFFERSIZE for chat.bufferSize
+const EnvPrefix = "ROUTE_GUIDE"
+
+// Config holds the settings of the RouteGuide server.
+// See res/conf/route-guide-server.yaml for a configuration file example.
+type Config struct {
+	Host string
+	Port int
+	// DatabasePath is the SQLite database storing the recorded routes
+	DatabasePath string `mapstructure:"database"`
+	// FeaturesFile is a JSON file of features replacing backend.Features. The server loads it in the
+	// background, and reports itself as not serving until it is loaded.
+	FeaturesFile string
+	Chat         ChatConfig
+	// TLS holds the certificate files. The server does not use TLS when they are not set.
+	TLS tlsconfig.Server
+	// Auth holds the tokens and rules authenticating the calls. Every call is accepted when no token
+	// is configured. It is read from AuthFile only: viper splits the keys at the dots, which breaks
+	// the method names of the rules, like /main.RouteGuide/RecordRoute.
+	Auth auth.Config `mapstructure:"-"`
+	// AuthFile is the YAML file of Auth
+	AuthFile  string
+	Keepalive KeepaliveConfig
+	Limits    LimitsConfig
+	// ShutdownTimeout is how long the running calls have to end once the server is stopping
+	ShutdownTimeout time.Duration
+	// Reflection registers the reflection service, to inspect the server with tools like grpcurl
+	Reflection bool
+	Gateway    GatewayConfig
+	// Tracing sets where the spans of the calls are exported. The trace context of the clients is
+	// propagated even without exporter.
+	Tracing tracing.Config
+}
+
+// GatewayConfig holds the settings of the REST/JSON gateway, served alongside the gRPC server.
+// The gateway is disabled when Port is zero. It serves HTTPS with the TLS settings of the server.
+type GatewayConfig struct {
+	Host string
+	Port int
+}
+
+// ChatConfig holds the settings of the RouteChat method
+type ChatConfig struct {
+	// BufferSize is the number of notes buffered for each client
+	BufferSize int
+	// SlowConsumer tells what to do with the clients whose buffer is full
+	SlowConsumer backend.SlowConsumerPolicy
+}
+
+// KeepaliveConfig holds the keepalive settings. The zero values keep the gRPC defaults.
+type KeepaliveConfig struct {
+	// MinTime is the minimum time between the pings of a client. Clients pinging more often are
+	// disconnected.
+	MinTime time.Duration
+	// PermitWithoutStream allows the clients to ping when they have no running call
+	PermitWithoutStream bool
+	// MaxConnectionIdle closes the connections without call for this duration
+	MaxConnectionIdle time.Duration
+	// MaxConnectionAge closes the connections after this duration, MaxConnectionAgeGrace later for
+	// the running calls
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+	// Time is the inactivity duration after which the server pings the client, and Timeout how long it
+	// waits for the answer before closing the connection
+	Time    time.Duration
+	Timeout time.Duration
+}
+
+// LimitsConfig limits the resources used by each connection. The zero values keep the gRPC defaults.
+type LimitsConfig struct {
+	// MaxRecvMsgSize and MaxSendMsgSize are the maximum size of a message in bytes
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+	// MaxConcurrentStreams is the maximum number of concurrent calls on a connection
+	MaxConcurrentStreams uint32
+}
+
+// DefaultConfig returns the configuration used for the settings not set elsewhere
+func DefaultConfig() Config {
+	return Config{
+		Host:         "localhost",
+		Port:         8080,
+		DatabasePath: "route_guide.db",
+		Chat: ChatConfig{
+			BufferSize:   backend.DefaultNoteBufferSize,
+			SlowConsumer: backend.DropNote,
+		},
+		Keepalive: KeepaliveConfig{
+			MinTime:             10 * time.Second,
+			PermitWithoutStream: true,
+			Time:                2 * time.Minute,
+			Timeout:             20 * time.Second,
+		},
+		Limits: LimitsConfig{
+			MaxRecvMsgSize:       4 * 1024 * 1024,
+			MaxSendMsgSize:       4 * 1024 * 1024,
+			MaxConcurrentStreams: 100,
+		},
+		ShutdownTimeout: 10 * time.Second,
+		Gateway:         GatewayConfig{Host: "localhost"},
+		Tracing:         tracing.Config{ServiceName: "route-guide-server", Exporter: tracing.NoExporter, Insecure: true},
+	}
+}
+
+// flagKeys maps the command line flags to their configuration keys
+var flagKeys = map[string]string{
+	"host":           "host",
+	"port":           "port",
+	"database":       "database",
+	"features":       "featuresFile",
+	"chat-buffer":    "chat.bufferSize",
+	"slow-consumer":  "chat.slowConsumer",
+	"tls-cert":       "tls.certFile",
+	"tls-key":        "tls.keyFile",
+	"tls-client-ca":  "tls.clientCAFile",
+	"auth-config":    "authFile",
+	"reflection":     "reflection",
+	"gateway-port":   "gateway.port",
+	"trace-exporter": "tracing.exporter",
+	"trace-endpoint": "tracing.endpoint",
+}
+
+// RegisterFlags defines the command line flags overriding the configuration
+func RegisterFlags(flags *flag.FlagSet) {
+	defaults := DefaultConfig()
+	flags.String("host", defaults.Host, "The server address")
+	flags.Int("port", defaults.Port, "The server port")
+	flags.String("database", defaults.DatabasePath, "The SQLite database storing the recorded routes")
+	flags.String("features", "", "The JSON file of the features. The built-in features are used when it is not set")
+	flags.Int("chat-buffer", defaults.Chat.BufferSize, "Number of RouteChat notes buffered per client")
+	flags.String("slow-consumer", defaults.Chat.SlowConsumer.String(), "What to do with RouteChat clients whose buffer is full. Can be [drop, disconnect]")
+	flags.String("tls-cert", "", "The server certificate file. Enables TLS")
+	flags.String("tls-key", "", "The server private key file")
+	flags.String("tls-client-ca", "", "The CA file checking the client certificates. Enables mutual TLS")
+	flags.String("auth-config", "", "The YAML file of the authentication tokens and rules. Enables authentication")
+	flags.Bool("reflection", false, "Register the reflection service")
+	flags.Int("gateway-port", 0, "The port of the REST/JSON gateway. The gateway is disabled when not set")
+	flags.String("trace-exporter", defaults.Tracing.Exporter, "Where the spans are exported. Can be [none, stdout, otlp]")
+	flags.String("trace-endpoint", "", "The host:port of the OTLP collector receiving the spans")
+}
+
+// LoadConfig reads the configuration. The settings are taken, by order of precedence, from the
+// flags set on the command line, the EnvPrefix environment variables, the configuration file, and
+// DefaultConfig. The file and the flags are optional.
+func LoadConfig(file string, flags *flag.FlagSet) (Config, error) {
+	// use a dedicated viper instance rather than the global one, to not mix up with other configurations
+	v := viper.New()
+	setDefaults(v, "", DefaultConfig())
+
+	if file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return Config{}, err
+		}
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if flags != nil {
+		// viper binds the pflag flags, which wrap the standard ones but do not know if they were set
+		pflags := pflag.NewFlagSet("", pflag.ContinueOnError)
+		pflags.AddGoFlagSet(flags)
+		flags.Visit(func(set *flag.Flag) {
+			pflags.Lookup(set.Name).Changed = true
+		})
+		for name, key := range flagKeys {
+			if flag := pflags.Lookup(name); flag != nil {
+				// a bound flag is only used when it is set on the command line
+				if err := v.BindPFlag(key, flag); err != nil {
+					return Config{}, err
+				}
+			}
+		}
+	}
+
+	// the auth section would be ignored, and the server would accept every call
+	if v.IsSet("auth") {
+		return Config{}, errors.New("the authentication cannot be set in the server configuration, set authFile")
+	}
+
+	var config Config
+	err := v.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+	if err != nil {
+		return Config{}, err
+	}
+	if config.AuthFile != "" {
+		if config.Auth, err = auth.LoadConfig(config.AuthFile); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := config.TLS.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid tls configuration, set tls.certFile and tls.keyFile: %w", err)
+	}
+	return config, nil
+}
+
+// setDefaults registers the values of the defaults as viper defaults.
+// Viper only reads the environment variables of the keys it knows, so every key needs a default.
+func setDefaults(v *viper.Viper, prefix string, defaults Config) {
+	var values map[string]interface{}
+	// decode the struct into a map with the same key names as the ones used to read the configuration
+	_ = mapstructure.Decode(defaults, &values)
+	setDefaultValues(v, prefix, values)
+}
+
+func setDefaultValues(v *viper.Viper, prefix string, values map[string]interface{}) {
+	for key, value := range values {
+		if nested, ok := value.(map[string]interface{}); ok {
+			setDefaultValues(v, prefix+key+".", nested)
+			continue
+		}
+		if text, ok := value.(interface{ MarshalText() ([]byte, error) }); ok {
+			data, _ := text.MarshalText()
+			value = string(data)
+		}
+		v.SetDefault(prefix+key, value)
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/gateway"
+	"golang_starter/internal/api/grpc/go-grpc/tlsconfig"
+	"golang_starter/internal/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// gatewayServer serves the REST gateway over HTTP.
+// The gateway calls an in-memory gRPC server, which shares the services and the interceptors of the
+// main one but does not use TLS: the TLS settings apply to the HTTP server instead.
+type gatewayServer struct {
+	http       *http.Server
+	grpc       *grpc.Server
+	connection *grpc.ClientConn
+	// endWatches ends the watch streams of the gateway
+	endWatches context.CancelFunc
+}
+
+// startGateway listens on the gateway address and starts serving the gateway in the background
+func startGateway(config Config, routeGuide pb.RouteGuideServer, healthServer healthpb.HealthServer) (*gatewayServer, error) {
+	inMemory := config
+	inMemory.TLS = tlsconfig.Server{}
+	inMemory.Reflection = false
+	grpcServer, err := newGrpcServer(inMemory, routeGuide, healthServer)
+	if err != nil {
+		return nil, err
+	}
+	listener := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(listener)
+	connection, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// the gateway continues the traces of the HTTP requests in the gRPC calls
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor))
+	if err != nil {
+		grpcServer.Stop()
+		return nil, err
+	}
+	watching, endWatches := context.WithCancel(context.Background())
+	gw := &gatewayServer{
+		http:       &http.Server{Handler: gateway.NewRouter(watching, connection)},
+		grpc:       grpcServer,
+		connection: connection,
+		endWatches: endWatches,
+	}
+	// the watch streams only end with their client: end them as soon as the shutdown starts
+	gw.http.RegisterOnShutdown(endWatches)
+
+	httpListener, err := net.Listen("tcp", net.JoinHostPort(config.Gateway.Host, strconv.Itoa(config.Gateway.Port)))
+	if err != nil {
+		gw.close()
+		return nil, fmt.Errorf("failed to listen for the gateway: %w", err)
+	}
+	if config.TLS.Enabled() {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = config.TLS.TLSConfig(); err != nil {
+			httpListener.Close()
+			gw.close()
+			return nil, fmt.Errorf("failed to load the TLS configuration: %w", err)
+		}
+		httpListener = tls.NewListener(httpListener, tlsConfig)
+	}
+	log.Println("Gateway listen on", httpListener.Addr())
+	go func() {
+		if err := gw.http.Serve(httpListener); !errors.Is(err, http.ErrServerClosed) {
+			log.Println("Gateway failed:", err)
+		}
+	}()
+	return gw, nil
+}
+
+// shutdown stops the gateway gracefully: it ends the server-sent events, waits for the other requests
+// and closes the remaining ones once the context is done
+func (gw *gatewayServer) shutdown(ctx context.Context) {
+	if err := gw.http.Shutdown(ctx); err != nil {
+		log.Println("Gateway shutdown timeout reached, closing the remaining requests")
+	}
+	gw.close()
+}
+
+func (gw *gatewayServer) close() {
+	gw.endWatches()
+	gw.http.Close()
+	gw.connection.Close()
+	gw.grpc.Stop()
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"strconv"
+)
+
+// default and maximum number of routes in a ListRoutes page
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// GetRoute expects a route identifier and returns the stored route with all its points
+func (s *routeGuideServer) GetRoute(ctx context.Context, request *pb.GetRouteRequest) (*pb.Route, error) {
+	log.Println("Received GetRoute message for route:", request.Id)
+	return s.getRoute(request.Id)
+}
+
+// ListRoutes expects a page request and returns a page of stored routes, without their points.
+// The page token is the identifier of the last route of the previous page.
+func (s *routeGuideServer) ListRoutes(ctx context.Context, request *pb.ListRoutesRequest) (*pb.ListRoutesResponse, error) {
+	log.Println("Received ListRoutes message:", request)
+	filter := store.Filter{Limit: int(request.PageSize)}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	if request.PageToken != "" {
+		afterID, err := strconv.ParseUint(request.PageToken, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token %q", request.PageToken)
+		}
+		filter.AfterID = afterID
+	}
+	if request.StartFrom != nil {
+		filter.StartFrom = request.StartFrom.AsTime()
+	}
+	if request.StartTo != nil {
+		filter.StartTo = request.StartTo.AsTime()
+	}
+
+	// ask for one more route than the page size to know if there is a next page
+	filter.Limit++
+	routes, err := s.routes.ListRoutes(filter)
+	if err != nil {
+		log.Println("Failed to list the routes:", err)
+		return nil, status.Error(codes.Internal, "cannot list the routes")
+	}
+	response := &pb.ListRoutesResponse{Routes: routes}
+	if len(routes) == filter.Limit {
+		response.Routes = routes[:len(routes)-1]
+		lastID := response.Routes[len(response.Routes)-1].Id
+		response.NextPageToken = strconv.FormatUint(lastID, 10)
+	}
+	return response, nil
+}
+
+// ExportRoute expects a route identifier and a file format, and returns the stored route in this
+// format
+func (s *routeGuideServer) ExportRoute(ctx context.Context, request *pb.ExportRouteRequest) (*pb.ExportRouteResponse, error) {
+	log.Println("Received ExportRoute message:", request)
+	route, err := s.getRoute(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.ExportRouteResponse{}
+	switch request.Format {
+	case pb.ExportFormat_GPX:
+		response.ContentType = backend.GPXContentType
+		response.Data, err = backend.ExportGPX(route)
+	case pb.ExportFormat_GEOJSON:
+		response.ContentType = backend.GeoJSONContentType
+		response.Data, err = backend.ExportGeoJSON(route)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown export format %v", request.Format)
+	}
+	if err != nil {
+		log.Println("Failed to export the route:", err)
+		return nil, status.Error(codes.Internal, "cannot export the route")
+	}
+	return response, nil
+}
+
+// getRoute reads a route from the store and converts the store errors into gRPC status
+func (s *routeGuideServer) getRoute(id uint64) (*pb.Route, error) {
+	route, err := s.routes.GetRoute(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "route %d not found", id)
+	}
+	if err != nil {
+		log.Println("Failed to read the route:", err)
+		return nil, status.Error(codes.Internal, "cannot read the route")
+	}
+	return route, nil
+}
@@ -3,14 +3,25 @@ package server
 import (
 	"context"
 	"fmt"
-	"github.com/golang/protobuf/proto"
 	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
 	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"golang_starter/internal/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"io"
 	"log"
 	"net"
-	"sync"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -22,27 +33,28 @@ import (
 type routeGuideServer struct {
 	pb.UnimplementedRouteGuideServer
 	savedFeatures []*pb.Feature // read-only after initialized
+	// loaded is closed once savedFeatures is initialized
+	loaded chan struct{}
 
-	// the Mutex will protect our routeNotes.
-	// Mutex stands for 'mutual exclusion locks'.
-	// Package 'sync' provides basic synchronization primitives such as mutual exclusion locks.
-	// Other than the Once and WaitGroup types, most are intended for use by low-level library routines.
-	// Higher-level synchronization is better done via channels and communication.
-	//
-	// A Mutex is a mutual exclusion lock. The zero value for a Mutex is an unlocked mutex.
-	// A Mutex must not be copied after first use.
-	// In the terminology of the Go memory model, the n'th call to Unlock “synchronizes before” the
-	// m'th call to Lock for any n < m.
-	// A successful call to TryLock is equivalent to a call to Lock.
-	// A failed call to TryLock does not establish any “synchronizes before” relation at all.
-	mu         sync.Mutex
-	routeNotes map[string][]*pb.RouteNote
+	// notes stores the RouteChat notes and broadcasts them to the connected clients.
+	// It is shared by all the streams, and protects its content with a Mutex.
+	notes *backend.NoteHub
+
+	// routes stores the routes recorded by RecordRoute
+	routes *store.Store
 }
 
 // GetFeature expects a Point and returns a unique feature from this Point
 func (s *routeGuideServer) GetFeature(ctx context.Context, point *pb.Point) (*pb.Feature, error) {
 	log.Println("Received GetFeature message for point:", point)
-	for _, feature := range backend.Features {
+	if err := geo.Validate(point); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	features, err := s.features()
+	if err != nil {
+		return nil, err
+	}
+	for _, feature := range features {
 		if feature.Location.Latitude == point.Latitude && feature.Location.Longitude == point.Longitude {
 			// return the feature AND a nil error to tell gROC that we have finished dealing with the
 			// client
@@ -59,7 +71,14 @@ func (s *routeGuideServer) GetFeature(ctx context.Context, point *pb.Point) (*pb
 // server.
 func (s *routeGuideServer) ListFeatures(rectangle *pb.Rectangle, stream pb.RouteGuide_ListFeaturesServer) error {
 	log.Println("Received ListFeatures message for rectangle:", rectangle)
-	for _, feature := range s.savedFeatures {
+	if err := geo.ValidateRectangle(rectangle); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	features, err := s.features()
+	if err != nil {
+		return err
+	}
+	for _, feature := range features {
 		// Use the backend function InRange to check if the feature's Point location is inside the
 		// given rectangle
 		if backend.InRange(feature.Location, rectangle) {
@@ -80,10 +99,14 @@ func (s *routeGuideServer) ListFeatures(rectangle *pb.Rectangle, stream pb.Route
 // client.
 func (s *routeGuideServer) RecordRoute(stream pb.RouteGuide_RecordRouteServer) error {
 	log.Println("Received RecordRoute stream connexion. Listen for route points ...")
-	// init RouteSummary fields
-	var pointCount, featureCount, distance int32
+	// the summary counts the features of the route
+	features, err := s.features()
+	if err != nil {
+		return err
+	}
 	// start computing route
-	var lastPoint *pb.Point
+	var points []*pb.Point
+	var receivedAt []time.Time
 	startTime := time.Now()
 	// infinite loop
 	// while the client does not close the stream
@@ -98,31 +121,40 @@ func (s *routeGuideServer) RecordRoute(stream pb.RouteGuide_RecordRouteServer) e
 		if err == io.EOF {
 			log.Println("The client has closed the stream.")
 			endTime := time.Now()
-			return stream.SendAndClose(&pb.RouteSummary{
-				PointCount:   pointCount,
-				FeatureCount: featureCount,
-				Distance:     distance,
-				ElapsedTime:  int32(endTime.Sub(startTime).Seconds()),
-			})
+			// compute the summary with the client timestamps if any, and store the points with the
+			// times used for the summary
+			if err := backend.CheckTimestamps(points); err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+			times := backend.PointTimes(points, receivedAt)
+			summary := backend.Summarize(points, times, features)
+			for i, point := range points {
+				point.Timestamp = timestamppb.New(times[i])
+			}
+			route := &pb.Route{
+				Points:    points,
+				Summary:   summary,
+				StartTime: timestamppb.New(startTime),
+				EndTime:   timestamppb.New(endTime),
+			}
+			// store the route before answering, so that the summary holds the route ID
+			if err := s.routes.SaveRoute(route); err != nil {
+				log.Println("Failed to store the route:", err)
+				return status.Error(codes.Internal, "cannot store the route")
+			}
+			return stream.SendAndClose(route.Summary)
 		}
 		// if the stream fails, return an error
 		if err != nil {
 			return err
 		}
-		// else, continue to count the points and the feature into the summary
+		// else, keep the point for the summary
 		log.Println("Received new point:", point)
-		pointCount++
-		for _, feature := range s.savedFeatures {
-			if proto.Equal(feature.Location, point) {
-				featureCount++
-			}
-		}
-		// the first time, the last point is nil because we need at least one point
-		if lastPoint != nil {
-			distance += backend.CalcDistance(lastPoint, point)
+		if err := geo.Validate(point); err != nil {
+			return status.Errorf(codes.InvalidArgument, "point %d: %v", len(points), err)
 		}
-		// update last point
-		lastPoint = point
+		points = append(points, point)
+		receivedAt = append(receivedAt, time.Now())
 	}
 }
 
@@ -130,75 +162,294 @@ func (s *routeGuideServer) RecordRoute(stream pb.RouteGuide_RecordRouteServer) e
 // RouteGuide_RouteChatServer is the compiled interface by protoc for grpc stream, from your
 // route definition in the protobuf file. We need to use this interface to answer with stream.
 // We need only one stream to handle both the client and the server streams.
+// Every note received subscribes the client to the note's location: the client gets back the notes
+// already posted there, then every note posted there later by any other client.
+// A note with an empty message only subscribes to its location and is not stored.
 func (s *routeGuideServer) RouteChat(streamClient pb.RouteGuide_RouteChatServer) error {
 	log.Println("Received RouteChat stream connexion. Listen for locations ...")
-	// initialize the route notes
-	s.routeNotes = make(map[string][]*pb.RouteNote)
-	// infinite loop for the chat
-	for {
-		in, err := streamClient.Recv()
-		// client has closed the communication
-		if err == io.EOF {
-			return nil
-		}
-		// stream failed
-		if err != nil {
-			return err
+	subscription := s.notes.Subscribe()
+	defer subscription.Close()
+
+	// gRPC lets one goroutine call Recv() while another one calls Send(), but two goroutines must not
+	// call Send() at the same time. The receiving goroutine hands the notes over to this goroutine,
+	// which is the only one writing to the stream. It watches their location itself: the live notes
+	// of the location wait in the subscription until the stored ones are sent, which keeps them in
+	// order.
+	received := make(chan *pb.RouteNote)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			in, err := streamClient.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			if in.Location == nil {
+				recvErr <- status.Error(codes.InvalidArgument, "route note without location")
+				return
+			}
+			log.Println("Received new location:", in.Location)
+			select {
+			case received <- in:
+			case <-streamClient.Context().Done():
+				return
+			}
 		}
+	}()
 
-		// serialize the location into a string text
-		log.Println("Received new location:", in.Location)
-		key := backend.Serialize(in.Location)
-
-		// we will write the new location to a routeNote
-		// protect the server's routeNotes with a mutex first
-		s.mu.Lock()
-		// add to the routeNotes the client input location, using the serialized location as a key
-		s.routeNotes[key] = append(s.routeNotes[key], in)
-		// Note: this copy prevents blocking other clients while serving this one.
-		// We don't need to do a deep copy, because elements in the slice are
-		// insert-only and never modified.
-		rn := make([]*pb.RouteNote, len(s.routeNotes[key]))
-		copy(rn, s.routeNotes[key])
-		// unlocking the routeNotes for usage
-		s.mu.Unlock()
-
-		// look for notes to be sent to the client
-		for _, note := range s.routeNotes[key] {
-			// The server uses the stream’s Send() method rather than SendAndClose() because it’s
-			// writing multiple responses.
+	// infinite loop for the chat
+	for {
+		select {
+		case in := <-received:
+			for _, note := range subscription.Watch(in.Location) {
+				// The server uses the stream’s Send() method rather than SendAndClose() because it’s
+				// writing multiple responses.
+				if err := streamClient.Send(note); err != nil {
+					return err
+				}
+			}
+			if in.Message != "" {
+				s.notes.Publish(in, subscription)
+			}
+		case note := <-subscription.Notes():
 			log.Println("Send note back to the client:", note)
 			if err := streamClient.Send(note); err != nil {
 				return err
 			}
+		case err := <-recvErr:
+			// client has closed the communication
+			if err == io.EOF {
+				return nil
+			}
+			// stream failed
+			return err
+		case <-subscription.Done():
+			// the hub only ends a subscription when the client cannot keep up
+			return status.Error(codes.ResourceExhausted, subscription.Err().Error())
+		case <-streamClient.Context().Done():
+			return streamClient.Context().Err()
 		}
 	}
+}
 
+// NewServer initializes the server and its data, storing the recorded routes in the given store.
+// The features are loaded before returning. Register the returned server on a grpc.Server to serve
+// it.
+func NewServer(config Config, routes *store.Store) (pb.RouteGuideServer, error) {
+	s := newServer(config, routes)
+	if err := s.loadFeatures(config.FeaturesFile); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
-// newServer function is used to initialize the server and its data
-func newServer() *routeGuideServer {
+// newServer function is used to initialize the server and its data.
+// The server answers Unavailable to the methods using the features until loadFeatures is called.
+func newServer(config Config, routes *store.Store) *routeGuideServer {
 	// init empty server
-	// maybe init the saved feature to an empty list in the server ?
-	s := &routeGuideServer{}
-	// load the backend's Features list into the server
-	s.savedFeatures = backend.Features
+	s := &routeGuideServer{loaded: make(chan struct{})}
+	s.notes = backend.NewNoteHub(config.Chat.BufferSize, config.Chat.SlowConsumer)
+	s.routes = routes
 	return s
 }
 
-func start(host string, port int) {
-	listen, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
-	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+// loadFeatures loads the features from the JSON file, or the backend's Features list when the file
+// is empty, into the server. It must be called only once.
+func (s *routeGuideServer) loadFeatures(file string) error {
+	features := backend.Features
+	if file != "" {
+		var err error
+		if features, err = backend.LoadFeatures(file); err != nil {
+			return err
+		}
+	}
+	s.savedFeatures = features
+	// closing the channel publishes savedFeatures to the goroutines waiting on it
+	close(s.loaded)
+	return nil
+}
+
+// features returns the loaded features, or an Unavailable error while they are loading
+func (s *routeGuideServer) features() ([]*pb.Feature, error) {
+	select {
+	case <-s.loaded:
+		return s.savedFeatures, nil
+	default:
+		return nil, status.Error(codes.Unavailable, "the features are loading")
 	}
-	var opts []grpc.ServerOption
+}
+
+// newGrpcServer creates the gRPC server with the options of the configuration, and registers the
+// services on it
+func newGrpcServer(config Config, routeGuide pb.RouteGuideServer, healthServer healthpb.HealthServer) (*grpc.Server, error) {
+	// the tracing interceptors come first, so that the spans cover the authentication
+	opts := append(tracing.ServerOptions(),
+		// the enforcement policy closes the connections of the clients pinging too often
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             config.Keepalive.MinTime,
+			PermitWithoutStream: config.Keepalive.PermitWithoutStream,
+		}),
+		// the server parameters ping the idle clients and close the old connections
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     config.Keepalive.MaxConnectionIdle,
+			MaxConnectionAge:      config.Keepalive.MaxConnectionAge,
+			MaxConnectionAgeGrace: config.Keepalive.MaxConnectionAgeGrace,
+			Time:                  config.Keepalive.Time,
+			Timeout:               config.Keepalive.Timeout,
+		}),
+	)
+	if config.Limits.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(config.Limits.MaxRecvMsgSize))
+	}
+	if config.Limits.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(config.Limits.MaxSendMsgSize))
+	}
+	if config.Limits.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(config.Limits.MaxConcurrentStreams))
+	}
+	if config.TLS.Enabled() {
+		credentials, err := config.TLS.ServerOption()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS configuration: %w", err)
+		}
+		opts = append(opts, credentials)
+	}
+	// a configuration without authenticator would accept every call
+	if err := config.Auth.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to load the authentication configuration: %w", err)
+	}
+	if config.Auth.Enabled() {
+		interceptors, err := config.Auth.Interceptors()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the authentication configuration: %w", err)
+		}
+		opts = append(opts, interceptors.ServerOptions()...)
+	}
+
 	grpcServer := grpc.NewServer(opts...)
-	pb.RegisterRouteGuideServer(grpcServer, newServer())
-	log.Printf("Listen on %s:%d", host, port)
-	// Serve until the process is killed or Stop() is called
-	grpcServer.Serve(listen)
+	pb.RegisterRouteGuideServer(grpcServer, routeGuide)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	if config.Reflection {
+		// the reflection service lets tools like grpcurl list the services and describe their
+		// messages without the proto files
+		reflection.Register(grpcServer)
+	}
+	return grpcServer, nil
+}
+
+// setServingStatus sets the status of the whole server and of the RouteGuide service
+func setServingStatus(healthServer *health.Server, servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+	healthServer.SetServingStatus("", servingStatus)
+	healthServer.SetServingStatus(pb.RouteGuide_ServiceDesc.ServiceName, servingStatus)
+}
+
+// Serve serves the RouteGuide service on the listener until the context is done, and the REST
+// gateway when it is enabled. The server then stops gracefully: it stops accepting new calls and
+// waits for the running ones to end, at most during config.ShutdownTimeout before closing them.
+// Serve stops and returns the error when the features cannot be loaded.
+func Serve(ctx context.Context, config Config, listener net.Listener) error {
+	routes, err := store.Open(config.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to open the route database: %w", err)
+	}
+	defer routes.Close()
+
+	// The health service tells the load balancers if the server can take calls, for the whole server
+	// (the empty service name) and for each service. Nothing is served until the features are loaded.
+	routeGuide := newServer(config, routes)
+	healthServer := health.NewServer()
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+	loadFailed := make(chan error, 1)
+	go func() {
+		if err := routeGuide.loadFeatures(config.FeaturesFile); err != nil {
+			loadFailed <- fmt.Errorf("failed to load the features: %w", err)
+			return
+		}
+		log.Println("Features loaded")
+		setServingStatus(healthServer, healthpb.HealthCheckResponse_SERVING)
+	}()
+
+	grpcServer, err := newGrpcServer(config, routeGuide, healthServer)
+	if err != nil {
+		return err
+	}
+	var gw *gatewayServer
+	if config.Gateway.Port != 0 {
+		// the gateway shares the RouteGuide service, so that the REST and gRPC clients chat together
+		if gw, err = startGateway(config, routeGuide, healthServer); err != nil {
+			return err
+		}
+	}
+	log.Println("Listen on", listener.Addr())
+	// Serve until Stop() or GracefulStop() is called
+	served := make(chan error, 1)
+	go func() {
+		served <- grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		if gw != nil {
+			gw.close()
+		}
+		return err
+	case err := <-loadFailed:
+		// the server never served without its features, there are no calls to wait for
+		grpcServer.Stop()
+		if gw != nil {
+			gw.close()
+		}
+		<-served
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Stopping the server ...")
+	// tell the load balancers to stop sending calls, before waiting for the running ones.
+	// Shutdown also ignores the status set later by the features loading.
+	healthServer.Shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	// the gateway and the gRPC server stop in parallel, each of them having the whole timeout
+	gatewayStopped := make(chan struct{})
+	go func() {
+		if gw != nil {
+			gw.shutdown(shutdownCtx)
+		}
+		close(gatewayStopped)
+	}()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		log.Println("Shutdown timeout reached, closing the remaining calls")
+		grpcServer.Stop()
+	}
+	<-gatewayStopped
+	return <-served
 }
 
-func Run(host string, port int) {
-	start(host, port)
+// Run listens on the configured address and serves until the process receives SIGINT or SIGTERM
+func Run(config Config) error {
+	listen, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Host, config.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shutdownTracing, err := tracing.Setup(ctx, config.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// send the last spans
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Println("Failed to export the last spans:", err)
+		}
+	}()
+	return Serve(ctx, config, listen)
 }
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"errors"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+// This package stores the routes recorded by the RouteGuide server in a SQLite database, using
+// the Gorm ORM. See the [Gorm tutorial](../../../../../../cmd/orm/gorm).
+
+// InMemory is the database path to use for a throwaway database, lost when the Store is closed
+const InMemory = ":memory:"
+
+// pointBatchSize is the number of points inserted by statement: SQLite limits the number of
+// variables of a statement, 32766 by default, and a point takes 5 of them
+const pointBatchSize = 1000
+
+// ErrNotFound is returned when the requested route is not stored
+var ErrNotFound = errors.New("route not found")
+
+// routeRecord is the table of the recorded routes
+type routeRecord struct {
+	ID        uint64    `gorm:"primaryKey"`
+	StartTime time.Time `gorm:"index"`
+	EndTime   time.Time
+	// Summary is the RouteSummary serialized as a protocol buffer, so that new summary fields do
+	// not need a schema migration
+	Summary []byte
+	Points  []pointRecord `gorm:"foreignKey:RouteID;constraint:OnDelete:CASCADE"`
+}
+
+func (routeRecord) TableName() string {
+	return "routes"
+}
+
+// pointRecord is the table of the points of the recorded routes
+type pointRecord struct {
+	ID        uint64 `gorm:"primaryKey"`
+	RouteID   uint64 `gorm:"index"`
+	Position  int
+	Latitude  int32
+	Longitude int32
+	// Timestamp is nil for the points stored without timestamp
+	Timestamp *time.Time
+}
+
+func (pointRecord) TableName() string {
+	return "route_points"
+}
+
+// Store saves and reads the recorded routes. It is safe for concurrent use.
+type Store struct {
+	db *gorm.DB
+}
+
+// Open opens (and creates if needed) the SQLite database at the given path and migrates its schema.
+// Use InMemory as path for a throwaway database.
+func Open(path string) (*Store, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		// the points of the long routes are inserted in several statements
+		CreateBatchSize: pointBatchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// each connection to an in-memory database opens a new empty database: use only one
+	if path == InMemory {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	if err := db.AutoMigrate(&routeRecord{}, &pointRecord{}); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// SaveRoute stores the route with its points and summary.
+// The route is given a new identifier, set to both route.Id and route.Summary.RouteId.
+func (s *Store) SaveRoute(route *pb.Route) error {
+	record := routeRecord{
+		StartTime: route.StartTime.AsTime(),
+		EndTime:   route.EndTime.AsTime(),
+	}
+	for i, point := range route.Points {
+		pointRecord := pointRecord{
+			Position:  i,
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+		}
+		if point.Timestamp != nil {
+			timestamp := point.Timestamp.AsTime()
+			pointRecord.Timestamp = &timestamp
+		}
+		record.Points = append(record.Points, pointRecord)
+	}
+
+	// the summary holds the route ID, which is only known once the route is inserted
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		if route.Summary == nil {
+			route.Summary = &pb.RouteSummary{}
+		}
+		route.Id = record.ID
+		route.Summary.RouteId = record.ID
+		summary, err := proto.Marshal(route.Summary)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&record).Update("summary", summary).Error
+	})
+}
+
+// GetRoute returns the route with the given identifier and all its points, or ErrNotFound
+func (s *Store) GetRoute(id uint64) (*pb.Route, error) {
+	var record routeRecord
+	err := s.db.Preload("Points", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&record, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toRoute(record)
+}
+
+// Filter selects the routes returned by ListRoutes
+type Filter struct {
+	// AfterID only keeps the routes with a greater identifier, for paging
+	AfterID uint64
+	// StartFrom and StartTo only keep the routes started in [StartFrom, StartTo). Zero values are
+	// not applied.
+	StartFrom time.Time
+	StartTo   time.Time
+	// Limit is the maximum number of routes to return, or all of them if not positive
+	Limit int
+}
+
+// ListRoutes returns the routes matching the filter, ordered by identifier, without their points
+func (s *Store) ListRoutes(filter Filter) ([]*pb.Route, error) {
+	// SQLite compares the times as text: always use UTC, like the stored times
+	query := s.db.Where("id > ?", filter.AfterID)
+	if !filter.StartFrom.IsZero() {
+		query = query.Where("start_time >= ?", filter.StartFrom.UTC())
+	}
+	if !filter.StartTo.IsZero() {
+		query = query.Where("start_time < ?", filter.StartTo.UTC())
+	}
+	if filter.Limit <= 0 {
+		// a negative limit cancels the limit condition
+		filter.Limit = -1
+	}
+	var records []routeRecord
+	if err := query.Order("id").Limit(filter.Limit).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	routes := make([]*pb.Route, 0, len(records))
+	for _, record := range records {
+		route, err := toRoute(record)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// toRoute converts a database record into its protocol buffer message
+func toRoute(record routeRecord) (*pb.Route, error) {
+	summary := &pb.RouteSummary{}
+	if err := proto.Unmarshal(record.Summary, summary); err != nil {
+		return nil, err
+	}
+	route := &pb.Route{
+		Id:        record.ID,
+		Summary:   summary,
+		StartTime: timestamppb.New(record.StartTime),
+		EndTime:   timestamppb.New(record.EndTime),
+	}
+	for _, record := range record.Points {
+		point := &pb.Point{Latitude: record.Latitude, Longitude: record.Longitude}
+		if record.Timestamp != nil {
+			point.Timestamp = timestamppb.New(*record.Timestamp)
+		}
+		route.Points = append(route.Points, point)
+	}
+	return route, nil
+}
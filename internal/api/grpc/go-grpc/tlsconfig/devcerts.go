@@ -0,0 +1,143 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DevCertificates are the paths of the files written by GenerateDevCertificates.
+// They are only meant for local development and tests: the CA key is thrown away.
+type DevCertificates struct {
+	CAFile         string
+	ServerCertFile string
+	ServerKeyFile  string
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// Server returns the server configuration for mutual TLS with these certificates
+func (d *DevCertificates) Server() Server {
+	return Server{CertFile: d.ServerCertFile, KeyFile: d.ServerKeyFile, ClientCAFile: d.CAFile}
+}
+
+// Client returns the client configuration for mutual TLS with these certificates
+func (d *DevCertificates) Client() Client {
+	return Client{CAFile: d.CAFile, CertFile: d.ClientCertFile, KeyFile: d.ClientKeyFile}
+}
+
+// devValidity is the lifetime of the generated certificates
+const devValidity = 30 * 24 * time.Hour
+
+// GenerateDevCertificates creates a throwaway CA and uses it to sign a server certificate valid for
+// the given hosts (DNS names or IP addresses) and a client certificate. The PEM files are written to
+// dir, which is created if needed.
+func GenerateDevCertificates(dir string, hosts []string) (*DevCertificates, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	files := &DevCertificates{
+		CAFile:         filepath.Join(dir, "ca.pem"),
+		ServerCertFile: filepath.Join(dir, "server.pem"),
+		ServerKeyFile:  filepath.Join(dir, "server-key.pem"),
+		ClientCertFile: filepath.Join(dir, "client.pem"),
+		ClientKeyFile:  filepath.Join(dir, "client-key.pem"),
+	}
+
+	// the CA signs both certificates
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate, err := newTemplate("route-guide development CA")
+	if err != nil {
+		return nil, err
+	}
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writePEM(files.CAFile, "CERTIFICATE", caDER, 0o644); err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	serverTemplate, err := newTemplate("route-guide server")
+	if err != nil {
+		return nil, err
+	}
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	if err := writeSignedPair(files.ServerCertFile, files.ServerKeyFile, serverTemplate, ca, caKey); err != nil {
+		return nil, err
+	}
+
+	clientTemplate, err := newTemplate("route-guide client")
+	if err != nil {
+		return nil, err
+	}
+	clientTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	if err := writeSignedPair(files.ClientCertFile, files.ClientKeyFile, clientTemplate, ca, caKey); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func newTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(devValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, nil
+}
+
+// writeSignedPair generates a key, signs its certificate with the CA and writes both files
+func writeSignedPair(certFile, keyFile string, template, ca *x509.Certificate, caKey *ecdsa.PrivateKey) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writePEM(certFile, "CERTIFICATE", der, 0o644); err != nil {
+		return err
+	}
+	return writePEM(keyFile, "EC PRIVATE KEY", keyDER, 0o600)
+}
+
+func writePEM(file, blockType string, der []byte, perm os.FileMode) error {
+	return os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), perm)
+}
@@ -0,0 +1,157 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+)
+
+// This package builds the transport credentials of the gRPC servers and clients from PEM files.
+// With TLS, the client checks the server certificate. With mutual TLS (mTLS), the server also asks
+// the client for a certificate, and checks it against its client CA.
+
+// Server holds the PEM files of a gRPC server. TLS is disabled when no file is set, and a partial set
+// of files is an error rather than a plaintext server, see Validate.
+type Server struct {
+	// CertFile and KeyFile are the server certificate and its private key
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS: the clients must present a certificate signed by this CA
+	ClientCAFile string
+}
+
+// Enabled tells if the server serves TLS: a file is set. Validate checks that the set is complete.
+func (s Server) Enabled() bool {
+	return s != Server{}
+}
+
+// Validate returns an error when some files are set but not the certificate and the key: a
+// forgotten file must fail the startup rather than serve plaintext
+func (s Server) Validate() error {
+	if s.Enabled() && (s.CertFile == "" || s.KeyFile == "") {
+		return errors.New("the server certificate and key files are both required when a TLS file is set")
+	}
+	return nil
+}
+
+// TLSConfig loads the files into a tls.Config
+func (s Server) TLSConfig() (*tls.Config, error) {
+	if s.CertFile == "" || s.KeyFile == "" {
+		return nil, errors.New("the server certificate and key files are both required")
+	}
+	certificate, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the server certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if s.ClientCAFile != "" {
+		pool, err := loadCertPool(s.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// ServerOption returns the grpc.ServerOption setting the server credentials, or nil when TLS is
+// disabled
+func (s Server) ServerOption() (grpc.ServerOption, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	config, err := s.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Creds(credentials.NewTLS(config)), nil
+}
+
+// Client holds the PEM files of a gRPC client. TLS is disabled unless Enabled is set or another field
+// is, and the client certificate comes with its key, see Validate.
+type Client struct {
+	Enabled bool
+	// CAFile is the CA checking the server certificate. The system CAs are used when it is empty.
+	CAFile string
+	// CertFile and KeyFile are the client certificate and its private key, for mutual TLS
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name checked in the server certificate, which is the dialed host by
+	// default
+	ServerName string
+}
+
+// UsesTLS tells if the client connects with TLS: a field is set. The TLS settings are never
+// ignored, a server name or a key without TLS would dial in plaintext unnoticed.
+func (c Client) UsesTLS() bool {
+	return c != Client{}
+}
+
+// Validate returns an error when the client certificate is set without its key, or the key without
+// the certificate
+func (c Client) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("the client certificate and key files are both required for mutual TLS")
+	}
+	return nil
+}
+
+// TLSConfig loads the files into a tls.Config
+func (c Client) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// DialOption returns the grpc.DialOption setting the client credentials, insecure ones when TLS is
+// disabled, or the Validate error
+func (c Client) DialOption() (grpc.DialOption, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if !c.UsesTLS() {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+	config, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificate found in %s", file)
+	}
+	return pool, nil
+}
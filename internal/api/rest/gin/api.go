@@ -1,9 +1,17 @@
 package gin
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	zaplogger "golang_starter/internal/logger/zap"
+	"golang_starter/internal/tracing"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 func getID(c *gin.Context) (int, error) {
@@ -17,94 +25,293 @@ func getID(c *gin.Context) (int, error) {
 	return idInt, nil
 }
 
+// TotalCountHeader is the response header of GET /albums holding the number of albums matching the
+// filters, whatever the page
+const TotalCountHeader = "X-Total-Count"
+
+// albumsAPI holds the handlers of the routes, serving the albums of its store
+type albumsAPI struct {
+	store *albumStore
+}
+
+// abortWithError answers with the status code and a JSON body holding the error message, and the
+// album id when there is one
+func abortWithError(c *gin.Context, code int, message string, id ...int) {
+	body := gin.H{"message": message}
+	if len(id) > 0 {
+		body["id"] = id[0]
+	}
+	c.AbortWithStatusJSON(code, body)
+}
+
+// pathID reads the album id of the path, and answers 400 when it is not a number
+func pathID(c *gin.Context) (int, bool) {
+	id, err := getID(c)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Sprintf("invalid album id %q", c.Param("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt reads an optional positive integer query parameter into value, and answers 400 when it
+// is invalid
+func queryInt(c *gin.Context, key string, value *int) bool {
+	text, found := c.GetQuery(key)
+	if !found {
+		return true
+	}
+	number, err := strconv.Atoi(text)
+	if err != nil || number < 0 {
+		abortWithError(c, http.StatusBadRequest, fmt.Sprintf("invalid %s query parameter %q: want a positive integer", key, text))
+		return false
+	}
+	*value = number
+	return true
+}
+
+// queryPrice reads an optional price query parameter into price, and answers 400 when it is invalid
+func queryPrice(c *gin.Context, key string, price **float64) bool {
+	text, found := c.GetQuery(key)
+	if !found {
+		return true
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, fmt.Sprintf("invalid %s query parameter %q: want a price", key, text))
+		return false
+	}
+	*price = &number
+	return true
+}
+
 // getAlbums route : creates JSON from the slice of album structs, writing the JSON into the response
 // `gin.Context` is the most important part of Gin. It carries request details, validates and
 // serializes JSON, and more.
-func getAlbums(c *gin.Context) {
-	c.Header("Content-Type", "application/json")
-	// Call Context.IndentedJSON to serialize the struct into JSON and add it to the response.
-	// Note that you can replace Context.IndentedJSON with a call to Context.JSON to send more compact
-	// JSON.
-	c.IndentedJSON(http.StatusOK, albums)
+// The query parameters filter the albums (artist, title, minPrice, maxPrice) and select a page of
+// them (offset, limit). The TotalCountHeader tells the number of albums of all the pages.
+func (a *albumsAPI) getAlbums(c *gin.Context) {
+	filter := albumFilter{Artist: c.Query("artist"), Title: c.Query("title")}
+	var offset, limit int
+	if !queryInt(c, "offset", &offset) || !queryInt(c, "limit", &limit) ||
+		!queryPrice(c, "minPrice", &filter.MinPrice) || !queryPrice(c, "maxPrice", &filter.MaxPrice) {
+		return
+	}
+
+	page, total := a.store.list(filter, offset, limit)
+	c.Header(TotalCountHeader, strconv.Itoa(total))
+	// writeCacheable serializes the albums like Context.IndentedJSON, and answers 304 Not Modified
+	// when the client already has them
+	writeCacheable(c, page, a.store.lastModified())
 }
 
-// postAlbums adds an album from JSON received in the request body.
-func postAlbums(c *gin.Context) {
+// bindAlbum reads the album of the request body, and answers 400 when it is invalid
+func bindAlbum(c *gin.Context) (postAlbumBody, bool) {
 	var body postAlbumBody
-
-	// Call BindJSON to bind the received JSON to
-	// newAlbum.
-	if err := c.BindJSON(&body); err != nil {
-		return
+	// Unlike BindJSON, ShouldBindJSON lets us write the error body
+	if err := c.ShouldBindJSON(&body); err != nil {
+		abortWithError(c, http.StatusBadRequest, "invalid album: "+err.Error())
+		return body, false
 	}
+	return body, true
+}
 
-	// create a new ID to create the new Album
-	lastAlbum := albums[len(albums)-1]
-	newID := lastAlbum.ID + 1
-	newAlbum := album{
-		newID, body.Title, body.Artist, body.Price,
+// postAlbums adds an album from JSON received in the request body.
+func (a *albumsAPI) postAlbums(c *gin.Context) {
+	body, ok := bindAlbum(c)
+	if !ok {
+		return
 	}
-
-	// add the new album to the slice
-	addAlbum(newAlbum)
+	// the store gives a new ID to the album
+	newAlbum := a.store.add(body)
+	c.Header("Location", fmt.Sprintf("/albums/%d", newAlbum.ID))
 	c.IndentedJSON(http.StatusCreated, newAlbum)
 }
 
 // getAlbumByID locates the album whose ID value matches the id
 // parameter sent by the client, then returns that album as a response.
-func getAlbumByID(c *gin.Context) {
-	id, err := getID(c)
-	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, err)
+func (a *albumsAPI) getAlbumByID(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
-	// Loop over the list of albums, looking for an album whose ID value matches the parameter.
-	for _, myAlbum := range albums {
-		if myAlbum.ID == id {
-			c.IndentedJSON(http.StatusOK, myAlbum)
-			return
-		}
+	myAlbum, found := a.store.get(id)
+	if !found {
+		// otherwise return 404
+		abortWithError(c, http.StatusNotFound, "album not found", id)
+		return
 	}
+	writeCacheable(c, myAlbum, a.store.lastModified())
+}
 
-	// otherwise return 404
-	c.IndentedJSON(http.StatusNotFound, gin.H{"message": "album bot found", "id": id})
+// putAlbumByID replaces the album whose ID value matches the id parameter with the album of the
+// request body
+func (a *albumsAPI) putAlbumByID(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	body, ok := bindAlbum(c)
+	if !ok {
+		return
+	}
+	updated, found := a.store.update(id, body)
+	if !found {
+		abortWithError(c, http.StatusNotFound, "album not found", id)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
 }
 
 // deleteAlbumByID locates the album whose ID value matches the id in request property
 // then deletes the album in backend
-func deleteAlbumByID(c *gin.Context) {
-	id, err := getID(c)
-	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, err)
+func (a *albumsAPI) deleteAlbumByID(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
 		return
 	}
-	for index, myAlbum := range albums {
-		if myAlbum.ID == id {
-			albums = removeFastByIndex(albums, index)
-		}
+	if !a.store.delete(id) {
+		abortWithError(c, http.StatusNotFound, "album not found", id)
+		return
 	}
-	c.IndentedJSON(http.StatusOK, albums)
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "album deleted", "id": id})
 }
 
-// Run defines the API configurations, routes and run the server
-func Run() {
-	// api init
-	// Initialize a Gin router using Default.
-	router := gin.Default()
+// accessLog logs every request with zap, with the trace_id and span_id of the tracing middleware
+func accessLog(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		tracing.Logger(c.Request.Context(), logger).Info("request",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+		)
+	}
+}
+
+// RouterOption configures a router
+type RouterOption func(*routerOptions)
+
+type routerOptions struct {
+	origins *AllowedOrigins
+}
+
+// WithAllowedOrigins allows the CORS requests of the origins only, rather than of every origin
+func WithAllowedOrigins(origins *AllowedOrigins) RouterOption {
+	return func(o *routerOptions) {
+		o.origins = origins
+	}
+}
+
+// NewRouter returns the router of the API, with its own albums seeded with the sample ones.
+// The requests are logged with the logger.
+func NewRouter(logger *zap.Logger, opts ...RouterOption) *gin.Engine {
+	options := routerOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	api := &albumsAPI{store: newAlbumStore(seedAlbums())}
+
+	// Initialize a Gin router using New rather than Default: the requests are logged by zap, with
+	// their trace id. The tracing middleware comes first so that its span covers the others.
+	router := gin.New()
+	router.Use(tracing.Middleware(), accessLog(logger), gin.Recovery())
 
 	// CORS config
 	// CONFIGURE IT BEFORE ROUTES !
 	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
+	if options.origins != nil {
+		// the origins are read at every request, so that they can change
+		config.AllowOriginFunc = options.origins.Allow
+	} else {
+		config.AllowAllOrigins = true
+	}
+	config.AddAllowHeaders("If-None-Match", "If-Modified-Since")
+	config.ExposeHeaders = []string{TotalCountHeader, "Location", "ETag", "Last-Modified"}
 	router.Use(cors.New(config))
 
 	// paths declarations
-	router.GET("/albums", getAlbums)
-	router.POST("/albums", postAlbums)
-	router.GET("/albums/:id", getAlbumByID)
-	router.DELETE("/albums/:id", deleteAlbumByID)
+	router.GET("/albums", api.getAlbums)
+	router.POST("/albums", api.postAlbums)
+	router.GET("/albums/:id", api.getAlbumByID)
+	router.PUT("/albums/:id", api.putAlbumByID)
+	router.DELETE("/albums/:id", api.deleteAlbumByID)
+	return router
+}
+
+// NewAdminHandler returns the handler of the admin endpoints, which must not be exposed with the API:
+//
+//	GET|PUT /log/level    the level of the logger, see zaplogger.Logger.LevelHandler
+func NewAdminHandler(logger *zaplogger.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/log/level", logger.LevelHandler())
+	return mux
+}
+
+// Run defines the API configurations, routes and run the server.
+// The configuration is read from the file when not empty, and reloaded when it changes.
+func Run(file string) {
+	watcher, err := NewConfigWatcher(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	conf := watcher.Current()
+
+	// tracing init
+	// The spans are exported with the OTEL_TRACES_EXPORTER environment variable (none, stdout or otlp)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.ConfigFromEnv("albums-api"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownTracing(context.Background())
+	// the level is atomic: it can change while the logger is used
+	loggerConfig := zaplogger.DefaultConfig()
+	loggerConfig.Level = conf.LogLevel
+	logger, err := zaplogger.New(loggerConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logger.Close()
+	level := logger.Level
+
+	// apply the changes of the configuration
+	origins := NewAllowedOrigins(conf.CORS.AllowOrigins...)
+	watcher.Subscribe(func(previous *Config, current *Config) {
+		// the level is validated by the configuration
+		_ = level.UnmarshalText([]byte(current.LogLevel))
+		origins.Set(current.CORS.AllowOrigins)
+		if current.Address != previous.Address {
+			logger.Warn("the address change requires a restart", zap.String("address", current.Address))
+		}
+		logger.Info("configuration reloaded", zap.String("logLevel", current.LogLevel),
+			zap.Strings("allowOrigins", current.CORS.AllowOrigins))
+	})
+	if file != "" {
+		go func() {
+			if err := watcher.Watch(context.Background(), func(err error) {
+				logger.Error("cannot reload the configuration", zap.Error(err))
+			}); err != nil {
+				logger.Error("cannot watch the configuration", zap.Error(err))
+			}
+		}()
+	}
+
+	// the level set on the admin address lasts until the next reload of the configuration
+	if conf.AdminAddress != "" {
+		go func() {
+			logger.Info("admin endpoints listening", zap.String("address", conf.AdminAddress))
+			if err := http.ListenAndServe(conf.AdminAddress, NewAdminHandler(logger)); err != nil {
+				logger.Error("cannot serve the admin endpoints", zap.Error(err))
+			}
+		}()
+	}
+
+	// api init
+	router := NewRouter(logger.Logger, WithAllowedOrigins(origins))
 
 	// start
 	// Use the Run function to attach the router to an http.Server and start the server
-	router.Run("localhost:8080")
+	router.Run(conf.Address)
 }
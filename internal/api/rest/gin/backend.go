@@ -1,5 +1,12 @@
 package gin
 
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
 // Go file used as backend for data
 // May be replaced by any storage like filesystem, minio, ...
 
@@ -11,13 +18,126 @@ type album struct {
 	Price  float64 `json:"price"`
 }
 
-// albums slice to seed record album data.
-var albums = []album{
-	{ID: 1, Title: "Blue Train", Artist: "John Coltrane", Price: 56.99},
-	{ID: 2, Title: "Jeru", Artist: "Gerry Mulligan", Price: 17.99},
-	{ID: 3, Title: "Sarah Vaughan and Clifford Brown", Artist: "Sarah Vaughan", Price: 39.99},
+// seedAlbums returns the record album data a new store starts with.
+func seedAlbums() []album {
+	return []album{
+		{ID: 1, Title: "Blue Train", Artist: "John Coltrane", Price: 56.99},
+		{ID: 2, Title: "Jeru", Artist: "Gerry Mulligan", Price: 17.99},
+		{ID: 3, Title: "Sarah Vaughan and Clifford Brown", Artist: "Sarah Vaughan", Price: 39.99},
+	}
+}
+
+// albumStore keeps the albums of a router in memory.
+// The gin handlers run concurrently, so the albums are protected by a Mutex.
+type albumStore struct {
+	mu     sync.Mutex
+	albums map[int]album
+	// lastID is the ID of the last created album. The IDs of the deleted albums are not reused.
+	lastID int
+	// modified is the time of the last change of the albums
+	modified time.Time
+}
+
+func newAlbumStore(albums []album) *albumStore {
+	s := &albumStore{albums: make(map[int]album, len(albums)), modified: time.Now()}
+	for _, a := range albums {
+		s.albums[a.ID] = a
+		if a.ID > s.lastID {
+			s.lastID = a.ID
+		}
+	}
+	return s
+}
+
+// albumFilter selects the albums listed by list. The zero values select every album.
+type albumFilter struct {
+	// Artist is the artist of the albums, whatever the case
+	Artist string
+	// Title is a part of the title of the albums, whatever the case
+	Title string
+	// MinPrice and MaxPrice bound the price of the albums when set
+	MinPrice *float64
+	MaxPrice *float64
+}
+
+func (f albumFilter) match(a album) bool {
+	return (f.Artist == "" || strings.EqualFold(a.Artist, f.Artist)) &&
+		(f.Title == "" || strings.Contains(strings.ToLower(a.Title), strings.ToLower(f.Title))) &&
+		(f.MinPrice == nil || a.Price >= *f.MinPrice) &&
+		(f.MaxPrice == nil || a.Price <= *f.MaxPrice)
+}
+
+// list returns a page of the albums matching the filter, sorted by ID, and the number of matching
+// albums. A zero limit returns every album after the offset.
+func (s *albumStore) list(filter albumFilter, offset int, limit int) ([]album, int) {
+	s.mu.Lock()
+	var matching []album
+	for _, a := range s.albums {
+		if filter.match(a) {
+			matching = append(matching, a)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(matching, func(i, j int) bool { return matching[i].ID < matching[j].ID })
+	total := len(matching)
+	if offset > total {
+		offset = total
+	}
+	page := matching[offset:]
+	if limit > 0 && limit < len(page) {
+		page = page[:limit]
+	}
+	// answer an empty JSON array rather than null
+	return append([]album{}, page...), total
+}
+
+// lastModified returns the time of the last change of the albums. Read it after the albums: it may
+// then be later than their last change, but never earlier.
+func (s *albumStore) lastModified() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.modified
+}
+
+func (s *albumStore) get(id int) (album, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, found := s.albums[id]
+	return a, found
+}
+
+// add creates an album with a new ID
+func (s *albumStore) add(body postAlbumBody) album {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	newAlbum := album{ID: s.lastID, Title: body.Title, Artist: body.Artist, Price: body.Price}
+	s.albums[newAlbum.ID] = newAlbum
+	s.modified = time.Now()
+	return newAlbum
+}
+
+// update replaces the fields of an existing album
+func (s *albumStore) update(id int, body postAlbumBody) (album, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.albums[id]; !found {
+		return album{}, false
+	}
+	updated := album{ID: id, Title: body.Title, Artist: body.Artist, Price: body.Price}
+	s.albums[id] = updated
+	s.modified = time.Now()
+	return updated, true
 }
 
-func addAlbum(newAlbum album) {
-	albums = append(albums, newAlbum)
+func (s *albumStore) delete(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, found := s.albums[id]
+	if found {
+		delete(s.albums, id)
+		s.modified = time.Now()
+	}
+	return found
 }
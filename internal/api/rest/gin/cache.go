@@ -0,0 +1,70 @@
+package gin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The GET responses carry an ETag, a hash of their content, and the Last-Modified time of the albums.
+// Cache-Control: no-cache lets the clients keep the responses, but makes them check that they are
+// still valid: they send the ETag in If-None-Match, or the time in If-Modified-Since, and the API
+// answers 304 Not Modified without body when nothing has changed.
+//
+// The times have a precision of one second: a change later in the same second would have the same
+// Last-Modified time as the response. The time is only sent once its second is over, the ETag
+// validates the responses meanwhile.
+
+// writeCacheable answers with the indented JSON of the object, or 304 Not Modified when the
+// conditional headers of the request match the response
+func writeCacheable(c *gin.Context, obj interface{}, modified time.Time) {
+	body, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// the headers written before, like the TotalCountHeader, are part of the response too
+	tag := etag(body, []byte(c.Writer.Header().Get(TotalCountHeader)))
+	c.Header("ETag", tag)
+	if modified.Truncate(time.Second).Before(time.Now().Truncate(time.Second)) {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Cache-Control", "no-cache")
+
+	if notModified(c.Request, tag, modified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etag returns a strong entity tag of the content
+func etag(content ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range content {
+		hash.Write(part)
+		// separate the parts, so that moving bytes from a part to another changes the hash
+		hash.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// notModified tells if the client already has the response. If-None-Match takes precedence over
+// If-Modified-Since, whose dates have a precision of one second.
+func notModified(request *http.Request, tag string, modified time.Time) bool {
+	if match := request.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == tag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(request.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,63 @@
+package gin
+
+import (
+	_ "embed"
+	"golang_starter/internal/marshaller/viper/config"
+	"sync/atomic"
+)
+
+//go:embed static/conf.yaml
+var defaultConfig []byte
+
+// Config is the configuration of the API, see res/conf/gin.yaml.
+// The log level and the CORS origins are applied without restart when the file changes.
+type Config struct {
+	// Address is the host:port the API listens to. A change requires a restart.
+	Address  string `validate:"required"`
+	LogLevel string `validate:"oneof=debug info warn error"`
+	CORS     CORSConfig
+	// AdminAddress is the host:port of the admin endpoints, like the log level one. They are not served
+	// when it is empty. Keep it internal, everyone reaching it can change the level. A change requires
+	// a restart.
+	AdminAddress string
+}
+
+// CORSConfig holds the Cross-Origin Resource Sharing settings
+type CORSConfig struct {
+	// AllowOrigins are the origins allowed to call the API from a browser, like
+	// https://albums.example.com. The origin * allows every origin.
+	AllowOrigins []string `validate:"min=1,dive,required"`
+}
+
+// NewConfigWatcher loads the configuration from the embedded defaults, the file when not empty and
+// the environment, and reloads it when the file changes
+func NewConfigWatcher(file string) (*config.Watcher[Config], error) {
+	return config.NewWatcher[Config](config.Loader{Defaults: defaultConfig, File: file})
+}
+
+// AllowedOrigins holds the origins allowed by CORS. They can be changed while the router runs.
+type AllowedOrigins struct {
+	origins atomic.Pointer[map[string]bool]
+}
+
+// NewAllowedOrigins returns the allowed origins. The origin * allows every origin.
+func NewAllowedOrigins(origins ...string) *AllowedOrigins {
+	a := &AllowedOrigins{}
+	a.Set(origins)
+	return a
+}
+
+// Set replaces the allowed origins
+func (a *AllowedOrigins) Set(origins []string) {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	a.origins.Store(&allowed)
+}
+
+// Allow tells if an origin is allowed
+func (a *AllowedOrigins) Allow(origin string) bool {
+	allowed := *a.origins.Load()
+	return allowed["*"] || allowed[origin]
+}
@@ -3,9 +3,10 @@ package gin
 // Go file used as backend for data
 // May be replaced by any storage like filesystem, minio, ...
 
-// album represents data about a record album.
+// postAlbumBody is the body of the requests creating or updating an album.
+// The binding tags are checked by ShouldBindJSON.
 type postAlbumBody struct {
-	Title  string  `json:"title"`
+	Title  string  `json:"title" binding:"required"`
 	Artist string  `json:"artist"`
-	Price  float64 `json:"price"`
+	Price  float64 `json:"price" binding:"gte=0"`
 }
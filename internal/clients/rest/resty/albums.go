@@ -0,0 +1,238 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"golang_starter/internal/tracing"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultBaseURL is the address of the albums API started by cmd/api/rest/gin
+const DefaultBaseURL = "http://localhost:8080"
+
+// DefaultTimeout is the default timeout of each request
+const DefaultTimeout = 10 * time.Second
+
+// TotalCountHeader is the header of the albums API holding the number of albums of all the pages
+const TotalCountHeader = "X-Total-Count"
+
+// Album is a record album of the albums API
+type Album struct {
+	ID     int     `json:"id"`
+	Title  string  `json:"title"`
+	Artist string  `json:"artist"`
+	Price  float64 `json:"price"`
+}
+
+// AlbumInput holds the fields of an album to create or update. The title is required.
+type AlbumInput struct {
+	Title  string  `json:"title"`
+	Artist string  `json:"artist"`
+	Price  float64 `json:"price"`
+}
+
+// ListOptions filters the albums returned by List, and selects a page of them.
+// The zero values select every album.
+type ListOptions struct {
+	// Artist is the artist of the albums, whatever the case
+	Artist string
+	// Title is a part of the title of the albums, whatever the case
+	Title string
+	// MinPrice and MaxPrice bound the price of the albums when set
+	MinPrice *float64
+	MaxPrice *float64
+	// Offset is the number of albums to skip, and Limit the maximum number of albums to return. A zero
+	// Limit returns every album.
+	Offset int
+	Limit  int
+}
+
+// AlbumPage is a page of the albums matching the ListOptions
+type AlbumPage struct {
+	Albums []Album
+	// Total is the number of albums matching the filters, in all the pages
+	Total int
+}
+
+// APIError is the error returned by the AlbumsClient methods when the API answers with an error
+// status. The message is read from the JSON error body when there is one.
+type APIError struct {
+	StatusCode int
+	Message    string `json:"message"`
+	// ID is the album id of the not found errors
+	ID int `json:"id"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("albums API: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("albums API: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound tells if the error is an APIError with the 404 status
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// AlbumsClient calls the albums API of the Gin tutorial. The requests are traced, see the tracing
+// package, and retried with the RetryPolicy. An AlbumsClient is safe for concurrent use.
+type AlbumsClient struct {
+	client  *resty.Client
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+	cache   *Cache
+}
+
+// Option configures an AlbumsClient
+type Option func(*AlbumsClient)
+
+// WithTimeout sets the timeout of each request, DefaultTimeout by default. Zero disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *AlbumsClient) {
+		c.client.SetTimeout(timeout)
+	}
+}
+
+// WithAuthToken sends the token as a bearer token in the Authorization header of every request
+func WithAuthToken(token string) Option {
+	return func(c *AlbumsClient) {
+		c.client.SetAuthToken(token)
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *AlbumsClient) {
+		c.client.SetHeader("User-Agent", userAgent)
+	}
+}
+
+// NewAlbumsClient returns a client of the albums API served at the base URL, like DefaultBaseURL
+func NewAlbumsClient(baseURL string, opts ...Option) *AlbumsClient {
+	c := &AlbumsClient{
+		client: resty.New().
+			SetBaseURL(baseURL).
+			SetTimeout(DefaultTimeout).
+			SetHeader("Accept", "application/json"),
+		retry: DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	transport := c.client.GetClient().Transport
+	if c.breaker != nil {
+		// the breaker wraps the transport, so that it sees every attempt
+		transport = c.breaker.RoundTripper(transport)
+	}
+	if c.cache != nil {
+		// the cache comes first: the responses it answers do not reach the breaker
+		transport = c.cache.RoundTripper(transport)
+	}
+	c.client.SetTransport(transport)
+	c.retry.apply(c.client)
+	// the hooks trace the requests, and send their trace context to the server
+	tracing.InstrumentResty(c.client)
+	return c
+}
+
+// request returns a request reading the APIError of the error responses
+func (c *AlbumsClient) request(ctx context.Context) *resty.Request {
+	return c.client.R().SetContext(ctx).SetError(&APIError{})
+}
+
+// check returns the error of the request, or the APIError of the response
+func check(response *resty.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if !response.IsError() {
+		return nil
+	}
+	apiErr, ok := response.Error().(*APIError)
+	if !ok {
+		// the error body is not JSON
+		apiErr = &APIError{}
+	}
+	apiErr.StatusCode = response.StatusCode()
+	return apiErr
+}
+
+// List returns a page of the albums matching the options
+func (c *AlbumsClient) List(ctx context.Context, options ListOptions) (*AlbumPage, error) {
+	request := c.request(ctx)
+	if options.Artist != "" {
+		request.SetQueryParam("artist", options.Artist)
+	}
+	if options.Title != "" {
+		request.SetQueryParam("title", options.Title)
+	}
+	if options.MinPrice != nil {
+		request.SetQueryParam("minPrice", strconv.FormatFloat(*options.MinPrice, 'f', -1, 64))
+	}
+	if options.MaxPrice != nil {
+		request.SetQueryParam("maxPrice", strconv.FormatFloat(*options.MaxPrice, 'f', -1, 64))
+	}
+	if options.Offset > 0 {
+		request.SetQueryParam("offset", strconv.Itoa(options.Offset))
+	}
+	if options.Limit > 0 {
+		request.SetQueryParam("limit", strconv.Itoa(options.Limit))
+	}
+
+	var albums []Album
+	response, err := request.SetResult(&albums).Get("/albums")
+	if err := check(response, err); err != nil {
+		return nil, err
+	}
+	page := &AlbumPage{Albums: albums, Total: len(albums)}
+	if total, err := strconv.Atoi(response.Header().Get(TotalCountHeader)); err == nil {
+		page.Total = total
+	}
+	return page, nil
+}
+
+// Get returns the album with the given id. The error is an APIError with the 404 status when there
+// is none, see IsNotFound.
+func (c *AlbumsClient) Get(ctx context.Context, id int) (*Album, error) {
+	var album Album
+	response, err := c.request(ctx).SetResult(&album).Get(albumPath(id))
+	if err := check(response, err); err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// Create creates an album, and returns it with its new id
+func (c *AlbumsClient) Create(ctx context.Context, input AlbumInput) (*Album, error) {
+	var album Album
+	response, err := c.request(ctx).SetBody(input).SetResult(&album).Post("/albums")
+	if err := check(response, err); err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// Update replaces the fields of the album with the given id, and returns the updated album
+func (c *AlbumsClient) Update(ctx context.Context, id int, input AlbumInput) (*Album, error) {
+	var album Album
+	response, err := c.request(ctx).SetBody(input).SetResult(&album).Put(albumPath(id))
+	if err := check(response, err); err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// Delete deletes the album with the given id
+func (c *AlbumsClient) Delete(ctx context.Context, id int) error {
+	return check(c.request(ctx).Delete(albumPath(id)))
+}
+
+func albumPath(id int) string {
+	return "/albums/" + strconv.Itoa(id)
+}
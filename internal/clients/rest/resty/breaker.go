@@ -0,0 +1,260 @@
+package resty
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped in the request error, when the circuit breaker rejects a
+// request without sending it
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a CircuitBreaker
+type State int
+
+const (
+	// StateClosed lets every request through, and counts the consecutive failures
+	StateClosed State = iota
+	// StateOpen rejects every request until the OpenTimeout is over
+	StateOpen
+	// StateHalfOpen lets a few requests through to probe the server: the circuit is closed once they
+	// succeed, and opened again at the first failure
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Result is the result of a request let through by a CircuitBreaker
+type Result int
+
+const (
+	// ResultSuccess is a request handled by the server
+	ResultSuccess Result = iota
+	// ResultFailure is a failure of the server, counted to open the circuit
+	ResultFailure
+	// ResultIgnored is a request telling nothing about the server, like a request cancelled by the
+	// caller. It is not counted, and gives its place back to another probe when half-open.
+	ResultIgnored
+)
+
+// BreakerSettings configures a CircuitBreaker. The zero values are replaced by the defaults.
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit, 5 by default
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before probing the server, 30s by default
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe requests let through when half-open, and which must
+	// succeed to close the circuit, 1 by default
+	HalfOpenRequests int
+	// IsFailure tells if the result of a request is a failure of the server. By default, the errors
+	// and the 5xx responses are failures. The requests cancelled by the caller are ignored, whatever
+	// IsFailure tells.
+	IsFailure func(request *http.Request, response *http.Response, err error) bool
+	// Hooks are called on the events of the breaker, to export metrics
+	Hooks BreakerHooks
+}
+
+// BreakerHooks are called by the CircuitBreaker, to export metrics like the number of rejected
+// requests or the time spent open. The nil hooks are ignored. The hooks are called after the breaker
+// is unlocked: they may call its methods.
+type BreakerHooks struct {
+	// OnStateChange is called when the circuit changes from a state to another
+	OnStateChange func(from State, to State)
+	// OnSuccess and OnFailure are called with the result of every request let through, except the
+	// ignored ones
+	OnSuccess func()
+	OnFailure func()
+	// OnRejected is called for every request rejected with ErrCircuitOpen
+	OnRejected func()
+}
+
+// CircuitBreaker stops sending the requests to a failing server for a while, rather than making
+// the callers wait for errors. A CircuitBreaker is safe for concurrent use.
+type CircuitBreaker struct {
+	settings BreakerSettings
+
+	mu    sync.Mutex
+	state State
+	// generation changes with the state, to ignore the results of the requests of a previous state
+	generation uint64
+	// failures counts the consecutive failures when closed
+	failures int
+	// openedAt is the time of the last opening
+	openedAt time.Time
+	// probes counts the requests let through, and successes their successes, when half-open
+	probes    int
+	successes int
+}
+
+// NewCircuitBreaker returns a closed circuit breaker
+func NewCircuitBreaker(settings BreakerSettings) *CircuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = isServerFailure
+	}
+	return &CircuitBreaker{settings: settings}
+}
+
+// isServerFailure is the default BreakerSettings.IsFailure
+func isServerFailure(request *http.Request, response *http.Response, err error) bool {
+	return err != nil || response.StatusCode >= http.StatusInternalServerError
+}
+
+// State returns the current state of the circuit
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// the open circuit becomes half-open once the timeout is over, without waiting for a request
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.settings.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow tells if a request can be sent. It returns ErrCircuitOpen when the circuit is open, or
+// half-open with all its probes running. Otherwise, done must be called with the result of the
+// request.
+func (b *CircuitBreaker) Allow() (done func(result Result), err error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.settings.OpenTimeout {
+		b.setState(StateHalfOpen)
+	}
+	allowed := b.state == StateClosed || (b.state == StateHalfOpen && b.probes < b.settings.HalfOpenRequests)
+	if allowed && b.state == StateHalfOpen {
+		b.probes++
+	}
+	generation, to := b.generation, b.state
+	b.mu.Unlock()
+
+	b.stateChanged(from, to)
+	if !allowed {
+		call(b.settings.Hooks.OnRejected)
+		return nil, ErrCircuitOpen
+	}
+	var once sync.Once
+	return func(result Result) {
+		once.Do(func() { b.record(generation, result) })
+	}, nil
+}
+
+// record counts the result of a request let through during the given generation
+func (b *CircuitBreaker) record(generation uint64, result Result) {
+	switch result {
+	case ResultSuccess:
+		call(b.settings.Hooks.OnSuccess)
+	case ResultFailure:
+		call(b.settings.Hooks.OnFailure)
+	}
+
+	b.mu.Lock()
+	from := b.state
+	// the results of the requests sent before the last change of state do not count
+	if generation == b.generation {
+		switch {
+		case result == ResultIgnored:
+			if b.state == StateHalfOpen {
+				// another request can probe the server in place of this one
+				b.probes--
+			}
+		case b.state == StateClosed && result == ResultSuccess:
+			b.failures = 0
+		case b.state == StateClosed:
+			b.failures++
+			if b.failures >= b.settings.FailureThreshold {
+				b.setState(StateOpen)
+			}
+		case b.state == StateHalfOpen && result == ResultSuccess:
+			b.successes++
+			if b.successes >= b.settings.HalfOpenRequests {
+				b.setState(StateClosed)
+			}
+		case b.state == StateHalfOpen:
+			b.setState(StateOpen)
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.stateChanged(from, to)
+}
+
+// setState changes the state and resets the counters. b.mu must be locked.
+func (b *CircuitBreaker) setState(state State) {
+	b.state = state
+	b.generation++
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if state == StateOpen {
+		b.openedAt = time.Now()
+	}
+}
+
+// stateChanged calls the OnStateChange hook when the state has changed
+func (b *CircuitBreaker) stateChanged(from State, to State) {
+	if from != to && b.settings.Hooks.OnStateChange != nil {
+		b.settings.Hooks.OnStateChange(from, to)
+	}
+}
+
+func call(hook func()) {
+	if hook != nil {
+		hook()
+	}
+}
+
+// RoundTripper returns a transport sending the requests with next, when the breaker allows them
+func (b *CircuitBreaker) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &breakerTransport{breaker: b, next: next}
+}
+
+// breakerTransport guards every attempt of the requests, retries included
+type breakerTransport struct {
+	breaker *CircuitBreaker
+	next    http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	done, err := t.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.next.RoundTrip(request)
+	switch {
+	case err != nil && request.Context().Err() != nil:
+		// the caller gave up, the server may be fine
+		done(ResultIgnored)
+	case t.breaker.settings.IsFailure(request, response, err):
+		done(ResultFailure)
+	default:
+		done(ResultSuccess)
+	}
+	return response, err
+}
+
+// WithCircuitBreaker guards the requests of the client with the breaker. The breaker can be shared
+// by the clients of the same server.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *AlbumsClient) {
+		c.breaker = breaker
+	}
+}
@@ -0,0 +1,368 @@
+package resty
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedResponse is a response kept by a Cache
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// StoredAt is the time the response was received, or last revalidated
+	StoredAt time.Time
+}
+
+// CacheStore keeps the cached responses by key. The stores must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse) error
+	Delete(key string)
+}
+
+// DefaultMemoryStoreSize is a maximum number of responses for a MemoryStore, fitting a client
+// browsing a few pages of albums
+const DefaultMemoryStoreSize = 1000
+
+// MemoryStore is a CacheStore keeping the responses in memory. It keeps at most maxEntries
+// responses: a new response evicts the least recently used one, so that a client listing many
+// pages does not grow without limit.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	// entries holds the memoryEntry values, the most recently used first
+	entries *list.List
+	keys    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryStore returns an empty MemoryStore keeping at most maxEntries responses, at least one
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &MemoryStore{maxEntries: maxEntries, entries: list.New(), keys: make(map[string]*list.Element)}
+}
+
+func (s *MemoryStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, found := s.keys[key]
+	if !found {
+		return nil, false
+	}
+	s.entries.MoveToFront(element)
+	return element.Value.(*memoryEntry).response, true
+}
+
+func (s *MemoryStore) Set(key string, response *CachedResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, found := s.keys[key]; found {
+		element.Value.(*memoryEntry).response = response
+		s.entries.MoveToFront(element)
+		return nil
+	}
+	s.keys[key] = s.entries.PushFront(&memoryEntry{key: key, response: response})
+	if s.entries.Len() > s.maxEntries {
+		oldest := s.entries.Back()
+		s.entries.Remove(oldest)
+		delete(s.keys, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, found := s.keys[key]; found {
+		s.entries.Remove(element)
+		delete(s.keys, key)
+	}
+}
+
+// Len returns the number of responses kept
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries.Len()
+}
+
+// DiskStore is a CacheStore keeping the responses in the files of a directory, so that they
+// survive the process. Every response is a JSON file named after the hash of its key.
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore returns a DiskStore keeping its files in the directory, created if needed
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (s *DiskStore) Get(key string) (*CachedResponse, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, false
+	}
+	return &response, true
+}
+
+func (s *DiskStore) Set(key string, response *CachedResponse) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	// write a temporary file then rename it, so that the readers never see a partial file
+	file, err := os.CreateTemp(s.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.path(key))
+}
+
+func (s *DiskStore) Delete(key string) {
+	os.Remove(s.path(key))
+}
+
+// CacheStats counts the GET requests handled by a Cache
+type CacheStats struct {
+	// Hits are answered from the cache, without request
+	Hits int64
+	// Revalidations are answered from the cache, after the server has answered 304 Not Modified
+	Revalidations int64
+	// Misses are downloaded from the server
+	Misses int64
+}
+
+// Cache keeps the GET responses of the server, like a browser: the responses are reused without
+// request while they are fresh (Cache-Control max-age), then revalidated with their ETag and
+// Last-Modified headers. The responses with Cache-Control no-store are not kept. The stale requests
+// holding their own If-None-Match or If-Modified-Since header are sent unchanged, and get the 304
+// of the server.
+// The cache is private: use one per user, since the responses may depend on the token.
+type Cache struct {
+	store         CacheStore
+	hits          atomic.Int64
+	revalidations atomic.Int64
+	misses        atomic.Int64
+}
+
+// NewCache returns a cache keeping its responses in the store
+func NewCache(store CacheStore) *Cache {
+	return &Cache{store: store}
+}
+
+// Stats returns the number of requests handled since the cache was created
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Revalidations: c.revalidations.Load(), Misses: c.misses.Load()}
+}
+
+// WithCache keeps the GET responses of the client in the cache
+func WithCache(cache *Cache) Option {
+	return func(c *AlbumsClient) {
+		c.cache = cache
+	}
+}
+
+// RoundTripper returns a transport answering from the cache when it can, and sending the other
+// requests with next
+func (c *Cache) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return &cacheTransport{cache: c, next: next}
+}
+
+type cacheTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != http.MethodGet {
+		response, err := t.next.RoundTrip(request)
+		if err == nil && response.StatusCode < 400 {
+			// the changes of a resource make its cached responses stale
+			t.cache.invalidate(request, response)
+		}
+		return response, err
+	}
+
+	key := request.URL.String()
+	cached, found := t.cache.store.Get(key)
+	if found && cached.fresh(time.Now()) {
+		t.cache.hits.Add(1)
+		return cached.response(request), nil
+	}
+
+	// the conditional requests of the caller are sent as they are: a 304 answers the validators of the
+	// caller, which may not match the cached response
+	conditional := request.Header.Get("If-None-Match") != "" || request.Header.Get("If-Modified-Since") != ""
+	revalidating := found && !conditional
+	if revalidating {
+		// ask the server to answer 304 when the cached response is still valid. The request of the
+		// caller must not be modified.
+		request = request.Clone(request.Context())
+		if tag := cached.Header.Get("ETag"); tag != "" {
+			request.Header.Set("If-None-Match", tag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			request.Header.Set("If-Modified-Since", modified)
+		}
+	}
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if revalidating && response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		t.cache.revalidations.Add(1)
+		// the 304 response holds the new caching headers of the response. The cached response may be
+		// read concurrently: update a copy.
+		revalidated := *cached
+		revalidated.Header = cached.Header.Clone()
+		for name, values := range response.Header {
+			if cachedHeaders[name] {
+				revalidated.Header[name] = values
+			}
+		}
+		revalidated.StoredAt = time.Now()
+		t.cache.set(key, &revalidated)
+		return revalidated.response(request), nil
+	}
+
+	t.cache.misses.Add(1)
+	if response.StatusCode == http.StatusNotModified {
+		// the 304 of a conditional request of the caller tells nothing about the cached response
+		return response, nil
+	}
+	if response.StatusCode != http.StatusOK || hasDirective(response.Header, "no-store") {
+		t.cache.store.Delete(key)
+		return response, nil
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	t.cache.set(key, &CachedResponse{
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		Body:       body,
+		StoredAt:   time.Now(),
+	})
+	return response, nil
+}
+
+// cachedHeaders are the headers of a 304 response replacing the ones of the cached response
+var cachedHeaders = map[string]bool{
+	"Cache-Control": true,
+	"Date":          true,
+	"Etag":          true,
+	"Expires":       true,
+	"Last-Modified": true,
+}
+
+// set stores the response. The cache is an optimization: the store errors are only logged.
+func (c *Cache) set(key string, response *CachedResponse) {
+	if err := c.store.Set(key, response); err != nil {
+		log.Println("cannot cache the response of", key, ":", err)
+	}
+}
+
+// invalidate removes the cached responses of the resource changed by the request, and of the one
+// created at its Location
+func (c *Cache) invalidate(request *http.Request, response *http.Response) {
+	c.store.Delete(request.URL.String())
+	if location, err := response.Location(); err == nil {
+		c.store.Delete(location.String())
+	}
+}
+
+// fresh tells if the response can be used without revalidation
+func (r *CachedResponse) fresh(now time.Time) bool {
+	if hasDirective(r.Header, "no-cache") {
+		return false
+	}
+	maxAge, found := directive(r.Header, "max-age")
+	if !found {
+		return false
+	}
+	seconds, err := strconv.Atoi(maxAge)
+	if err != nil {
+		return false
+	}
+	// the response may already be old when received from a shared cache
+	age, _ := strconv.Atoi(r.Header.Get("Age"))
+	return now.Sub(r.StoredAt) < time.Duration(seconds-age)*time.Second
+}
+
+// response returns a new response to the request, with the cached headers and body
+func (r *CachedResponse) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
+
+// directive returns the value of a Cache-Control directive, like max-age=60
+func directive(header http.Header, name string) (string, bool) {
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			key, argument, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if strings.EqualFold(key, name) {
+				return strings.Trim(argument, `"`), true
+			}
+		}
+	}
+	return "", false
+}
+
+func hasDirective(header http.Header, name string) bool {
+	_, found := directive(header, name)
+	return found
+}
@@ -0,0 +1,139 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"golang_starter/internal/stats"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// NewCommand creates the http-probe command line
+func NewCommand() *cobra.Command {
+	var headers []string
+	var output string
+	config := Config{}
+
+	cmd := &cobra.Command{
+		Use:   "http-probe URL",
+		Short: "Repeat an HTTP request and report the time spent in each phase",
+		Long: "Send a request from concurrent workers, then report the minimum, mean and percentiles of the " +
+			"DNS lookup, TCP connection, TLS handshake, server and total times.\n" +
+			"The DNS, TCP and TLS times are only measured on the new connections: use --no-keepalive to " +
+			"open a connection for every request.",
+		Example: "  http-probe http://localhost:8080/albums -n 1000 -c 10\n" +
+			"  http-probe http://localhost:8080/albums -X POST -H 'Content-Type: application/json' " +
+			"--data '{\"title\": \"Discovery\"}' --duration 10s -o json",
+		Args: cobra.ExactArgs(1),
+		// the usage is not helpful when the run fails
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "table" && output != "json" {
+				return fmt.Errorf("unknown output format %q: use table or json", output)
+			}
+			var err error
+			if config.Header, err = ParseHeaders(headers); err != nil {
+				return err
+			}
+			config.URL = args[0]
+			config.Method = strings.ToUpper(config.Method)
+
+			report, err := Run(cmd.Context(), config)
+			if err != nil {
+				return err
+			}
+			if output == "json" {
+				return WriteJSON(cmd.OutOrStdout(), report)
+			}
+			return WriteTable(cmd.OutOrStdout(), report)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&config.Method, "method", "X", http.MethodGet, "The method of the request")
+	flags.StringArrayVarP(&headers, "header", "H", nil, "A header of the request, like 'Accept: application/json'. Can be repeated")
+	flags.StringVar(&config.Body, "data", "", "The body of the request")
+	flags.IntVarP(&config.Concurrency, "concurrency", "c", 1, "The number of concurrent workers")
+	flags.IntVarP(&config.Requests, "requests", "n", 0, "The total number of requests. The run lasts --duration when not set")
+	flags.DurationVar(&config.Duration, "duration", 10*time.Second, "The duration of the run, unless --requests is set")
+	flags.DurationVar(&config.Timeout, "timeout", 10*time.Second, "The deadline of each request")
+	flags.BoolVar(&config.DisableKeepAlives, "no-keepalive", false, "Open a new connection for every request")
+	flags.BoolVarP(&config.Insecure, "insecure", "k", false, "Accept any certificate of the server")
+	flags.StringVarP(&output, "output", "o", "table", "The output format. Can be [table, json]")
+	return cmd
+}
+
+// Execute is the entry point of the command
+func Execute() {
+	if err := NewCommand().Execute(); err != nil {
+		// cobra has already printed the error
+		os.Exit(1)
+	}
+}
+
+// ParseHeaders reads headers like "Accept: application/json"
+func ParseHeaders(values []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, value := range values {
+		name, content, found := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid header %q: want NAME: VALUE", value)
+		}
+		header.Add(name, strings.TrimSpace(content))
+	}
+	return header, nil
+}
+
+// WriteTable writes the report as a table of the phases, with the latencies in milliseconds
+func WriteTable(w io.Writer, report *Report) error {
+	_, err := fmt.Fprintf(w, "%s %s: %d requests, %d errors, %d new connections, %.1f requests/s\n",
+		report.Method, report.URL, report.Requests, report.Errors, report.NewConnections, report.Throughput)
+	if err != nil {
+		return err
+	}
+	for _, code := range report.StatusCodes() {
+		fmt.Fprintf(w, "  %d %s: %d\n", code, http.StatusText(code), report.Statuses[code])
+	}
+	failures := make([]string, 0, len(report.Failures))
+	for failure := range report.Failures {
+		failures = append(failures, failure)
+	}
+	sort.Strings(failures)
+	for _, failure := range failures {
+		fmt.Fprintf(w, "  %s: %d\n", failure, report.Failures[failure])
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tCOUNT\tMIN (ms)\tMEAN (ms)\tP50 (ms)\tP95 (ms)\tP99 (ms)\tMAX (ms)")
+	for _, phase := range report.Phases {
+		l := phase.Latency
+		fmt.Fprintf(tw, "%s\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\n", phase.Phase, l.Count,
+			stats.Milliseconds(l.Min), stats.Milliseconds(l.Mean), stats.Milliseconds(l.P50),
+			stats.Milliseconds(l.P95), stats.Milliseconds(l.P99), stats.Milliseconds(l.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n%d workers during %v\n", report.Concurrency, report.Elapsed.Round(time.Millisecond))
+	return err
+}
+
+// WriteJSON writes the report as an indented JSON object, with the latencies in milliseconds, to
+// compare the runs in the regression tests
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		*Report
+		ElapsedSeconds float64 `json:"elapsedSeconds"`
+	}{report, report.Elapsed.Seconds()})
+}
@@ -0,0 +1,219 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"golang_starter/internal/stats"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The probe sends the same request from concurrent workers, with the resty trace enabled, and
+// aggregates the time spent in each phase of the requests: the DNS lookup, the TCP connection, the
+// TLS handshake, the wait for the server, and the total time.
+// The DNS, TCP and TLS phases only happen on the new connections: the requests sent on a reused
+// connection do not count in their statistics. Disable the keep-alives to measure them every time.
+
+// The phases of a request
+const (
+	DNS     = "dns"
+	Connect = "connect"
+	TLS     = "tls"
+	Server  = "server"
+	Total   = "total"
+)
+
+// Phases lists the phases in the order of the reports
+var Phases = []string{DNS, Connect, TLS, Server, Total}
+
+// Config holds the request and the settings of a run
+type Config struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+	// Concurrency is the number of concurrent workers, each waiting for its request before the next one
+	Concurrency int
+	// Duration bounds the run when Requests is zero
+	Duration time.Duration
+	// Requests is the total number of requests of the run, shared by the workers
+	Requests int
+	// Timeout is the deadline of each request, none when zero
+	Timeout time.Duration
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool
+	// Insecure accepts any certificate of the server
+	Insecure bool
+}
+
+// Report holds the results of a run
+type Report struct {
+	Method      string        `json:"method"`
+	URL         string        `json:"url"`
+	Concurrency int           `json:"concurrency"`
+	Elapsed     time.Duration `json:"-"`
+	// Requests counts the requests, and Errors the ones failed or answered a 4xx or 5xx status
+	Requests int `json:"requests"`
+	Errors   int `json:"errors"`
+	// Throughput is the number of requests per second
+	Throughput float64 `json:"throughput"`
+	// NewConnections counts the requests which have opened a connection
+	NewConnections int `json:"newConnections"`
+	// Statuses counts the responses by status code, and Failures the failed requests by error
+	Statuses map[int]int    `json:"statuses"`
+	Failures map[string]int `json:"failures,omitempty"`
+	Phases   []PhaseReport  `json:"phases"`
+}
+
+// PhaseReport holds the latencies of a phase
+type PhaseReport struct {
+	Phase   string        `json:"phase"`
+	Latency stats.Summary `json:"latency"`
+}
+
+// Run sends the request until Requests requests are done, or until Duration has elapsed, or until
+// the context is done. The requests interrupted by the end of the run are not reported.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if config.Concurrency < 1 {
+		return nil, errors.New("the probe needs at least one worker")
+	}
+	if config.Requests <= 0 && config.Duration <= 0 {
+		return nil, errors.New("the probe needs a number of requests or a duration")
+	}
+	if config.URL == "" {
+		return nil, errors.New("the probe needs a URL")
+	}
+	if config.Method == "" {
+		config.Method = http.MethodGet
+	}
+	if config.Requests <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+	client := newClient(config)
+
+	recorders := make(map[string]*stats.Recorder, len(Phases))
+	for _, phase := range Phases {
+		recorders[phase] = &stats.Recorder{}
+	}
+	var mu sync.Mutex
+	statuses := make(map[int]int)
+	failures := make(map[string]int)
+	var newConnections atomic.Int64
+	var started atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if config.Requests > 0 && started.Add(1) > int64(config.Requests) {
+					return
+				}
+				requestStart := time.Now()
+				response, err := send(ctx, client, config)
+				if ctx.Err() != nil {
+					// the run has ended during the request
+					return
+				}
+				if err != nil {
+					recorders[Total].Record(time.Since(requestStart), err)
+					mu.Lock()
+					failures[err.Error()]++
+					mu.Unlock()
+					continue
+				}
+				if response.StatusCode() >= http.StatusBadRequest {
+					err = fmt.Errorf("status %d", response.StatusCode())
+				}
+				trace := response.Request.TraceInfo()
+				recorders[Total].Record(trace.TotalTime, err)
+				recorders[Server].Record(trace.ServerTime, nil)
+				if !trace.IsConnReused {
+					newConnections.Add(1)
+					recorders[DNS].Record(trace.DNSLookup, nil)
+					recorders[Connect].Record(trace.TCPConnTime, nil)
+					if response.RawResponse.TLS != nil {
+						recorders[TLS].Record(trace.TLSHandshake, nil)
+					}
+				}
+				mu.Lock()
+				statuses[response.StatusCode()]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	total := recorders[Total].Summary()
+	report := &Report{
+		Method:         config.Method,
+		URL:            config.URL,
+		Concurrency:    config.Concurrency,
+		Elapsed:        elapsed,
+		Requests:       total.Count,
+		Errors:         total.Errors,
+		Throughput:     float64(total.Count) / elapsed.Seconds(),
+		NewConnections: int(newConnections.Load()),
+		Statuses:       statuses,
+		Failures:       failures,
+	}
+	for _, phase := range Phases {
+		summary := recorders[phase].Summary()
+		if summary.Count == 0 {
+			continue
+		}
+		report.Phases = append(report.Phases, PhaseReport{Phase: phase, Latency: summary})
+	}
+	return report, nil
+}
+
+// newClient returns a resty client without retry, tracing the requests
+func newClient(config Config) *resty.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = config.DisableKeepAlives
+	// keep a connection per worker between the requests
+	transport.MaxIdleConnsPerHost = config.Concurrency
+	client := resty.New().SetTransport(transport).EnableTrace()
+	if config.Insecure {
+		// for the servers with a self-signed certificate
+		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+	if config.Timeout > 0 {
+		client.SetTimeout(config.Timeout)
+	}
+	return client
+}
+
+// send sends the request of the config
+func send(ctx context.Context, client *resty.Client, config Config) (*resty.Response, error) {
+	request := client.R().SetContext(ctx)
+	for name, values := range config.Header {
+		for _, value := range values {
+			request.Header.Add(name, value)
+		}
+	}
+	if config.Body != "" {
+		request.SetBody(config.Body)
+	}
+	return request.Execute(config.Method, config.URL)
+}
+
+// StatusCodes returns the status codes of the report, sorted
+func (r *Report) StatusCodes() []int {
+	codes := make([]int, 0, len(r.Statuses))
+	for code := range r.Statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes
+}
@@ -1,63 +1,62 @@
 package resty
 
 import (
-	"github.com/go-resty/resty/v2"
+	"context"
+	"golang_starter/internal/tracing"
 	"log"
-	"net/url"
 )
 
 // This tutorial shows the usage of a rest client using the module Resty.
 // Use this client with the tutorial Gin :
 // [Gin tutorial](../../../api/rest/gin)
-
-var client *resty.Client
-
-var myUrl *url.URL
-
-func Run() {
-	// init connexion
-	client = resty.New()
-	myUrl, _ = url.Parse("http://localhost:8080")
+// The AlbumsClient wraps the resty client in typed methods, one per route of the API.
+// The probe package measures the phases of any request with the resty trace (DNS, TCP, TLS, server):
+// see the http-probe command.
+
+// Run lists the albums of the API served at the base URL, like DefaultBaseURL
+func Run(baseURL string) {
+	// init tracing
+	// The spans are exported with the OTEL_TRACES_EXPORTER environment variable (none, stdout or otlp)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.ConfigFromEnv("albums-client"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// send the last spans before exiting
+	defer shutdownTracing(context.Background())
+
+	// init client
+	// The failed requests are retried with the DefaultRetryPolicy. The circuit breaker stops calling
+	// the API for a while after 5 consecutive failures.
+	breaker := NewCircuitBreaker(BreakerSettings{
+		Hooks: BreakerHooks{
+			OnStateChange: func(from State, to State) {
+				log.Printf("circuit breaker: %s -> %s", from, to)
+			},
+		},
+	})
+	// The cache keeps the last responses in memory, and revalidates them with the ETag of the API:
+	// the unchanged albums are not downloaded again.
+	cache := NewCache(NewMemoryStore(DefaultMemoryStoreSize))
+	client := NewAlbumsClient(baseURL, WithUserAgent("albums-client"), WithCircuitBreaker(breaker), WithCache(cache))
 
 	// GET
 	log.Println("GET method")
-	restyGet(myUrl)
-
-}
-
-func restyGet(u *url.URL) {
-	// use this method to add path dynamically
-	u = u.JoinPath("/albums")
-	// get response
-	resp, err := client.R().EnableTrace().Get(u.String())
+	page, err := client.List(context.Background(), ListOptions{})
 	if err != nil {
-		log.Fatal("GET error:", err)
+		// log.Fatal would exit without sending the spans
+		log.Println("GET error:", err)
+		return
+	}
+	log.Printf("%d albums:", page.Total)
+	for _, album := range page.Albums {
+		log.Printf("  %d: %q by %s, %.2f", album.ID, album.Title, album.Artist, album.Price)
 	}
 
-	// Explore response object
-	log.Println("Response Info:")
-	log.Println("  Error      :", err)
-	log.Println("  Status Code:", resp.StatusCode())
-	log.Println("  Status     :", resp.Status())
-	log.Println("  Proto      :", resp.Proto())
-	log.Println("  Time       :", resp.Time())
-	log.Println("  Received At:", resp.ReceivedAt())
-	log.Println("  Body       :\n", resp)
-	log.Println()
-
-	// Explore trace info
-	log.Println("Request Trace Info:")
-	ti := resp.Request.TraceInfo()
-	log.Println("  DNSLookup     :", ti.DNSLookup)
-	log.Println("  ConnTime      :", ti.ConnTime)
-	log.Println("  TCPConnTime   :", ti.TCPConnTime)
-	log.Println("  TLSHandshake  :", ti.TLSHandshake)
-	log.Println("  ServerTime    :", ti.ServerTime)
-	log.Println("  ResponseTime  :", ti.ResponseTime)
-	log.Println("  TotalTime     :", ti.TotalTime)
-	log.Println("  IsConnReused  :", ti.IsConnReused)
-	log.Println("  IsConnWasIdle :", ti.IsConnWasIdle)
-	log.Println("  ConnIdleTime  :", ti.ConnIdleTime)
-	log.Println("  RequestAttempt:", ti.RequestAttempt)
-	log.Println("  RemoteAddr    :", ti.RemoteAddr.String())
+	// GET again, answered 304 Not Modified by the API
+	if _, err := client.List(context.Background(), ListOptions{}); err != nil {
+		log.Println("GET error:", err)
+		return
+	}
+	stats := cache.Stats()
+	log.Printf("cache: %d hits, %d revalidations, %d misses", stats.Hits, stats.Revalidations, stats.Misses)
 }
@@ -0,0 +1,135 @@
+package resty
+
+import (
+	"errors"
+	"github.com/go-resty/resty/v2"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy tells which requests are retried, and how long to wait between the attempts.
+//
+// Every request answered 429 (Too Many Requests) or 503 (Service Unavailable) is retried: the server
+// has not processed it. The idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are also retried
+// when they fail to reach the server, or are answered 502 or 504 by a gateway.
+//
+// The wait before the n-th retry is InitialBackoff * Multiplier^(n-1), at most MaxBackoff, minus a
+// random part given by Jitter. The Retry-After header of the response overrides it.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt. Zero disables the retries.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is the random part of the wait, between 0 and 1: the wait is drawn between
+	// backoff * (1 - Jitter) and backoff, so that the clients do not retry all together.
+	Jitter float64
+	// MaxRetryAfter is the longest wait asked by a Retry-After header which is honored. The responses
+	// asking to wait longer are returned without retry.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy of the AlbumsClient, unless WithRetry is used
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+		MaxRetryAfter:  10 * time.Second,
+	}
+}
+
+// WithRetry sets the retry policy of the client, DefaultRetryPolicy by default.
+// Use a zero RetryPolicy to disable the retries.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *AlbumsClient) {
+		c.retry = policy
+	}
+}
+
+// Backoff returns the wait before the given retry, starting at 1, with its random part drawn from
+// the value between 0 and 1
+func (p RetryPolicy) Backoff(retry int, random float64) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(math.Max(p.Multiplier, 1), float64(retry-1))
+	if p.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.MaxBackoff))
+	}
+	jitter := math.Max(0, math.Min(1, p.Jitter))
+	return time.Duration(backoff * (1 - jitter*random))
+}
+
+// apply configures the retries of the resty client.
+// resty calls the retry condition after every attempt, then waits the duration returned by the
+// RetryAfter function, bounded by the RetryWaitTime and RetryMaxWaitTime of the client.
+func (p RetryPolicy) apply(client *resty.Client) {
+	if p.MaxRetries <= 0 {
+		return
+	}
+	client.
+		SetRetryCount(p.MaxRetries).
+		SetRetryWaitTime(0).
+		SetRetryMaxWaitTime(time.Duration(math.Max(float64(p.MaxBackoff), float64(p.MaxRetryAfter)))).
+		AddRetryCondition(p.shouldRetry).
+		SetRetryAfter(func(client *resty.Client, response *resty.Response) (time.Duration, error) {
+			// resty uses its own backoff when the wait is zero, like with Retry-After: 0
+			if wait, found := retryAfter(response); found {
+				return time.Duration(math.Max(float64(wait), 1)), nil
+			}
+			return time.Duration(math.Max(float64(p.Backoff(response.Request.Attempt, rand.Float64())), 1)), nil
+		})
+}
+
+// shouldRetry tells if the attempt which returned the response or the error is retried
+func (p RetryPolicy) shouldRetry(response *resty.Response, err error) bool {
+	if errors.Is(err, ErrCircuitOpen) || response == nil || response.Request == nil {
+		return false
+	}
+	if err != nil {
+		// the request has not reached the server, or its answer is lost
+		return idempotent(response.Request.Method)
+	}
+	switch response.StatusCode() {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		wait, found := retryAfter(response)
+		return !found || wait <= p.MaxRetryAfter
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(response.Request.Method)
+	}
+	return false
+}
+
+// idempotent tells if sending the request several times has the same effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads the Retry-After header of the response, either a number of seconds or a date
+func retryAfter(response *resty.Response) (time.Duration, bool) {
+	if response.RawResponse == nil {
+		return 0, false
+	}
+	value := response.Header().Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package zap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+	"golang_starter/internal/tracing"
+	"net/http"
+)
+
+// The request-scoped fields, like the request id or the user, are carried by the context of the
+// request: a middleware puts a logger with the fields in the context, and the functions handling the
+// request log with FromContext.
+//
+//	ctx = zap.WithFields(ctx, zap.String("user", user))
+//	zap.FromContext(ctx).Info("album created", zap.Int("id", id))
+
+// RequestIDHeader is the header of the request id, read from the requests and set on the responses
+const RequestIDHeader = "X-Request-Id"
+
+type contextKey struct{}
+
+// NewContext returns a context holding the logger
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of the context, or the global logger of zap when the context has
+// none. The trace_id and span_id of the span of the context are added to its entries.
+func FromContext(ctx context.Context) *zap.Logger {
+	return tracing.Logger(ctx, fromContext(ctx))
+}
+
+// fromContext returns the logger of the context, without the trace fields
+func fromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return zap.L()
+}
+
+// WithFields returns a context whose logger adds the fields to its entries
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return NewContext(ctx, fromContext(ctx).With(fields...))
+}
+
+// Middleware puts the logger in the context of the requests, with the request_id, method and path
+// fields. The request id is read from the X-Request-Id header, or generated, and returned in the
+// response headers.
+func Middleware(logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := NewContext(r.Context(), logger.With(
+			zap.String("request_id", requestID),
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+		))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// newRequestID returns 16 random hexadecimal characters
+func newRequestID() string {
+	id := make([]byte, 8)
+	// the reader of crypto/rand does not fail on the supported systems
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,235 @@
+package zap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang_starter/internal/marshaller/viper/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Config is the configuration of a logger. It can be loaded with the config package, as a part of
+// the configuration of a service, like:
+//
+//	level: info
+//	encoding: json
+//	outputPaths: [stdout, /var/log/albums/api.log]
+//	rotation:
+//	  maxSize: 100
+//	  maxAge: 168h
+type Config struct {
+	// Level is the minimum level of the logged entries: debug, info, warn, error, dpanic, panic or
+	// fatal. It can be changed at runtime, see Logger.LevelHandler.
+	Level string `validate:"oneof=debug info warn error dpanic panic fatal"`
+	// Encoding is json, or console for a human-readable output
+	Encoding string `validate:"oneof=json console"`
+	// OutputPaths are stdout, stderr or files, which are created when missing
+	OutputPaths []string `validate:"min=1,dive,required"`
+	// ErrorOutputPaths receive the errors of the logger itself, like a failed write
+	ErrorOutputPaths []string `validate:"dive,required"`
+	// Development logs the stack traces from the warn level, and makes the dpanic entries panic
+	Development bool
+	Sampling    SamplingConfig
+	Rotation    RotationConfig
+}
+
+// SamplingConfig limits the entries logged under load: every Tick, the first Initial entries with the
+// same level and message are logged, then one out of Thereafter, or none when it is 0. The sampling
+// is disabled when Initial is 0.
+type SamplingConfig struct {
+	Initial    int `validate:"gte=0"`
+	Thereafter int `validate:"gte=0"`
+	// Tick is one second when 0
+	Tick time.Duration `validate:"gte=0"`
+}
+
+// RotationConfig rotates the output files: the current file is renamed with its rotation time, like
+// api-2022-10-19T15-30-00.000.log, and a new file is created. The files are rotated when MaxSize or
+// Interval is set.
+type RotationConfig struct {
+	// MaxSize is the size of a file in megabytes before it is rotated. It is 100 when only Interval
+	// is set.
+	MaxSize int `validate:"gte=0"`
+	// Interval rotates the files periodically too, like every 24h. The files are not rotated by time
+	// when it is 0.
+	Interval time.Duration `validate:"gte=0"`
+	// MaxAge removes the rotated files older than this age, rounded up to days. They are kept when 0.
+	MaxAge time.Duration `validate:"gte=0"`
+	// MaxBackups is the number of rotated files kept, all of them when 0
+	MaxBackups int `validate:"gte=0"`
+	// Compress compresses the rotated files with gzip
+	Compress bool
+	// LocalTime names the rotated files with the local time rather than UTC
+	LocalTime bool
+}
+
+// DefaultConfig returns the configuration of a production logger: JSON entries of the info level
+// and above on the standard error, sampled like zap.NewProduction does
+func DefaultConfig() Config {
+	return Config{
+		Level:            "info",
+		Encoding:         "json",
+		OutputPaths:      []string{"stderr"},
+		ErrorOutputPaths: []string{"stderr"},
+		Sampling:         SamplingConfig{Initial: 100, Thereafter: 100},
+	}
+}
+
+// Logger is a zap logger built from a Config. Close it before exiting, to write the buffered
+// entries and close the files.
+type Logger struct {
+	*zap.Logger
+	// Level changes the level of the logger while it is used
+	Level zap.AtomicLevel
+
+	closeOnce sync.Once
+	closers   []io.Closer
+	// rotated are the files rotated periodically
+	rotated []*lumberjack.Logger
+	// stop ends the periodic rotations
+	stop chan struct{}
+}
+
+// New builds a logger, after validating its configuration
+func New(c Config) (*Logger, error) {
+	if err := config.Validate(c); err != nil {
+		return nil, err
+	}
+	level := zap.NewAtomicLevel()
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return nil, err
+	}
+	l := &Logger{Level: level, stop: make(chan struct{})}
+
+	output, err := l.open(c.OutputPaths, c.Rotation)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	errorOutput, err := l.open(c.ErrorOutputPaths, c.Rotation)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	stacktraceLevel := zapcore.ErrorLevel
+	options := []zap.Option{zap.ErrorOutput(errorOutput), zap.AddCaller()}
+	if c.Development {
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+		stacktraceLevel = zapcore.WarnLevel
+		options = append(options, zap.Development())
+	}
+	options = append(options, zap.AddStacktrace(stacktraceLevel))
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	if c.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	core := zapcore.NewCore(encoder, output, level)
+	if c.Sampling.Initial > 0 {
+		tick := c.Sampling.Tick
+		if tick == 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, c.Sampling.Initial, c.Sampling.Thereafter)
+	}
+	l.Logger = zap.New(core, options...)
+	for _, file := range l.rotated {
+		go l.rotateEvery(file, c.Rotation.Interval)
+	}
+	return l, nil
+}
+
+// open opens the outputs: stdout and stderr, the files, rotated when the rotation is set, and the
+// URLs of the sinks registered in zap
+func (l *Logger) open(paths []string, rotation RotationConfig) (zapcore.WriteSyncer, error) {
+	var syncers []zapcore.WriteSyncer
+	for _, path := range paths {
+		if (rotation.MaxSize == 0 && rotation.Interval == 0) || path == "stdout" || path == "stderr" {
+			syncer, closeSink, err := zap.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			l.closers = append(l.closers, closerFunc(closeSink))
+			syncers = append(syncers, syncer)
+			continue
+		}
+		file := &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    rotation.MaxSize,
+			MaxAge:     int(math.Ceil(rotation.MaxAge.Hours() / 24)),
+			MaxBackups: rotation.MaxBackups,
+			Compress:   rotation.Compress,
+			LocalTime:  rotation.LocalTime,
+		}
+		l.closers = append(l.closers, file)
+		if rotation.Interval > 0 {
+			l.rotated = append(l.rotated, file)
+		}
+		// the writes of lumberjack are not buffered, there is nothing to sync
+		syncers = append(syncers, zapcore.Lock(zapcore.AddSync(file)))
+	}
+	return zapcore.NewMultiWriteSyncer(syncers...), nil
+}
+
+// rotateEvery rotates a file periodically, until the logger is closed
+func (l *Logger) rotateEvery(file *lumberjack.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := file.Rotate(); err != nil {
+				l.Error("cannot rotate the log file", zap.String("file", file.Filename), zap.Error(err))
+			}
+		}
+	}
+}
+
+// LevelHandler returns the HTTP handler reading and changing the level of the logger, to log the
+// debug entries of a running service for a while:
+//
+//	curl localhost:8081/log/level
+//	curl -X PUT localhost:8081/log/level -d '{"level":"debug"}'
+//
+// The logger does not serve it: the callers mount it on an internal address only, since everyone
+// reaching it can change the level, like the albums API with its adminAddress setting.
+func (l *Logger) LevelHandler() http.Handler {
+	return l.Level
+}
+
+// Close writes the buffered entries, and closes the files. The logger must not be used afterwards.
+func (l *Logger) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.stop)
+		if l.Logger != nil {
+			// syncing the standard outputs fails on some systems, like when they are terminals
+			_ = l.Sync()
+		}
+		for _, closer := range l.closers {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	})
+	return err
+}
+
+// closerFunc adapts the close functions of zap.Open to io.Closer
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
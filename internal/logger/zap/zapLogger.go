@@ -1,13 +1,22 @@
 package zap
 
 import (
+	"context"
 	"go.uber.org/zap"
+	"log"
 	"time"
 )
 
 func Start() {
-	logger, _ := zap.NewProduction()
-	defer logger.Sync() // flushed buffer after function return, if any
+	// New builds the logger from a configuration, usually loaded with the configuration of the
+	// program. The default one logs JSON entries of the info level and above.
+	conf := DefaultConfig()
+	conf.OutputPaths = []string{"stdout"}
+	logger, err := New(conf)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer logger.Close() // flushed buffer and closed files after function return, if any
 	url := "http://issou.com"
 
 	// Standard logger
@@ -34,4 +43,15 @@ func Start() {
 		"backoff", time.Second,
 	)
 	sugar.Infof("Failed to fetch URL: %s", url)
+
+	// The request-scoped fields are carried by the context: every entry logged with the logger of the
+	// context has them, here request_id
+	ctx := NewContext(context.Background(), logger.Logger)
+	ctx = WithFields(ctx, zap.String("request_id", "42"))
+	FromContext(ctx).Info("album created", zap.Int("id", 7))
+
+	// The level can change while the logger is used, like with its HTTP handler, see LevelHandler
+	logger.Debug("not logged at the info level")
+	logger.Level.SetLevel(zap.DebugLevel)
+	logger.Debug("logged at the debug level")
 }
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// NewCommand creates the config command line, working on the configuration files of a program.
+// newTarget returns a pointer to a new configuration struct of the program, and the loader holds its
+// defaults and environment prefix.
+func NewCommand(loader Loader, newTarget func() interface{}) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work on the configuration files",
+		// the usage is not helpful when a file is invalid
+		SilenceUsage: true,
+	}
+	cmd.AddCommand(
+		newValidateCommand(loader, newTarget),
+		newPrintCommand(loader, newTarget),
+		newConvertCommand(loader),
+		newSchemaCommand(newTarget),
+	)
+	return cmd
+}
+
+func newValidateCommand(loader Loader, newTarget func() interface{}) *cobra.Command {
+	var env bool
+	cmd := &cobra.Command{
+		Use:   "validate FILE",
+		Short: "Check a configuration file",
+		Long: "Check that a configuration file (yaml, toml, json, ...) is readable and valid once merged with " +
+			"the defaults. The invalid values are reported with their line, and the command exits with an error.",
+		Example: "  config validate res/conf/viper.yaml",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loader.File = args[0]
+			loader.NoEnv = !env
+			loader.Flags = nil
+			if err := loader.Load(newTarget()); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), args[0], "is valid")
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&env, "env", false, "Apply the environment variables to the file, like the program does")
+	return cmd
+}
+
+func newPrintCommand(loader Loader, newTarget func() interface{}) *cobra.Command {
+	var effective bool
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the default or the effective configuration",
+		Long: "Print the default configuration, or with --effective the configuration the program would use: " +
+			"the defaults merged with the file and the environment variables, with the origin of each value. " +
+			"The secrets are masked.",
+		Example: "  config print > my-conf.yaml\n  APP_VERSION=2.0.0 config print --effective --conf res/conf/viper.yaml",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !effective {
+				_, err := cmd.OutOrStdout().Write(loader.Defaults)
+				return err
+			}
+			loader.Flags = nil
+			target := newTarget()
+			// an invalid configuration is printed too, to find the invalid values
+			v, err := loader.load(target)
+			if err != nil {
+				return err
+			}
+			origins, err := newOrigins(loader, v)
+			if err != nil {
+				return err
+			}
+			return printEffective(cmd.OutOrStdout(), target, origins)
+		},
+	}
+	cmd.Flags().BoolVar(&effective, "effective", false, "Print the merged configuration, with the origin of each value")
+	cmd.Flags().StringVar(&loader.File, "conf", "", "The configuration file of the program")
+	return cmd
+}
+
+func newConvertCommand(loader Loader) *cobra.Command {
+	var from, to string
+	cmd := &cobra.Command{
+		Use:   "convert [FILE]",
+		Short: "Convert a configuration file to another format",
+		Long: "Convert a configuration file, or the standard input, to yaml, json, toml or env. The format of the " +
+			"file is given by its extension, or by --from. The keys are written in lower case, and the references " +
+			"are kept. The env format writes the environment variables overriding the keys, which cannot hold the " +
+			"lists of objects.",
+		Example: "  config convert res/conf/viper.yaml --to toml > viper.toml\n" +
+			"  config convert --from yaml --to json < res/conf/viper.yaml",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := cmd.InOrStdin()
+			if len(args) == 1 && args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				input = file
+				if from == "" {
+					from = strings.TrimPrefix(filepath.Ext(args[0]), ".")
+				}
+			}
+			if from == "" {
+				return errors.New("the format of the standard input is required, set --from")
+			}
+			return Convert(cmd.OutOrStdout(), input, from, to, loader.envPrefix())
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "The format of the input, the extension of the file by default")
+	cmd.Flags().StringVar(&to, "to", "", "The output format: "+strings.Join(ConvertFormats, ", "))
+	cmd.Flags().StringVar(&loader.EnvPrefix, "env-prefix", loader.envPrefix(), "The prefix of the environment variables of the env format")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func newSchemaCommand(newTarget func() interface{}) *cobra.Command {
+	var title string
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration files",
+		Long: "Print the JSON Schema of the configuration files, generated from the configuration struct and its " +
+			"validate tags. The editors use it to check and complete the files: in a YAML file, refer to it with " +
+			"the comment # yaml-language-server: $schema=FILE.",
+		Example: "  config schema > res/conf/viper.schema.json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return WriteSchema(cmd.OutOrStdout(), newTarget(), title)
+		},
+	}
+	cmd.Flags().StringVar(&title, "title", "", "The title of the schema")
+	return cmd
+}
+
+// printEffective writes the values of a configuration struct with their origin
+func printEffective(w io.Writer, target interface{}, origins *origins) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tORIGIN")
+	for _, key := range Keys(target) {
+		origin := origins.of(key)
+		source := origin.String()
+		if source == "" {
+			source = origin.Layer.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key, formatValue(fieldValue(reflect.ValueOf(target), key)), source)
+	}
+	return tw.Flush()
+}
+
+// fieldValue returns the value of the field of a struct at a key path, like "server.port"
+func fieldValue(value reflect.Value, key string) reflect.Value {
+	for _, segment := range strings.Split(key, ".") {
+		for value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		value = field(value, segment)
+	}
+	return value
+}
+
+// field returns the field of a struct with the key name, looking into the squashed fields too
+func field(value reflect.Value, name string) reflect.Value {
+	for i := 0; i < value.NumField(); i++ {
+		fieldName, squash := fieldName(value.Type().Field(i))
+		if squash && value.Field(i).Kind() == reflect.Struct {
+			if found := field(value.Field(i), name); found.IsValid() {
+				return found
+			}
+		} else if fieldName == name && value.Type().Field(i).IsExported() {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// formatValue formats a value like JSON, but with the text of the values having one, like the
+// durations. The secrets are masked by their methods.
+func formatValue(value reflect.Value) string {
+	if !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+		return "null"
+	}
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		if value.Kind() == reflect.String {
+			return strconv.Quote(stringer.String())
+		}
+		return stringer.String()
+	}
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return fmt.Sprint(value.Interface())
+	}
+	return string(data)
+}
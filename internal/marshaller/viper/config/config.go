@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"reflect"
+	"strings"
+)
+
+// The config package reads a configuration from several layers, each one overriding the previous:
+//  1. the defaults, a configuration document usually embedded in the binary
+//  2. the configuration file, in any format known by viper (yaml, json, toml, ...)
+//  3. the environment variables, like APP_SERVER_PORT for server.port
+//  4. the command line flags, when they are set
+//
+// The references to the environment variables and to the files are replaced (see Secret), then the
+// merged configuration is decoded into a struct of the caller, like viper.Unmarshal does, and
+// validated with the validate tags of the struct (see Validate). Each Loader uses its own viper
+// instance, so that several configurations can be loaded by a program.
+
+// DefaultEnvPrefix prefixes the environment variables when Loader.EnvPrefix is not set
+const DefaultEnvPrefix = "APP"
+
+// Loader reads a configuration. Only the Defaults are required.
+type Loader struct {
+	// Defaults is a configuration document holding the default values
+	Defaults []byte
+	// DefaultsType is the format of Defaults, yaml when empty
+	DefaultsType string
+	// File is the configuration file. Its format is given by its extension. No file is read when
+	// empty, but a missing file is an error.
+	File string
+	// EnvPrefix prefixes the environment variables, DefaultEnvPrefix when empty
+	EnvPrefix string
+	// NoEnv ignores the environment variables, like to check a file alone
+	NoEnv bool
+	// Flags override the configuration, but only the ones set on the command line: their defaults do
+	// not override the other layers
+	Flags *pflag.FlagSet
+	// FlagKeys maps the names of the flags to their configuration keys, like "port" to
+	// "server.port". The flags which are not in the map override the key of their name.
+	FlagKeys map[string]string
+}
+
+// Load reads the configuration, decodes it into target, a pointer to a struct, then validates it.
+// The fields are matched with the keys by name, whatever the case, or by their mapstructure tag.
+// The invalid values are returned in a *ValidationError, with their origin.
+func (l Loader) Load(target interface{}) error {
+	v, err := l.load(target)
+	if err != nil {
+		return err
+	}
+	err = Validate(target)
+	if validationErr, ok := err.(*ValidationError); ok {
+		origins, originsErr := newOrigins(l, v)
+		if originsErr != nil {
+			return originsErr
+		}
+		for _, fieldErr := range validationErr.Errors {
+			fieldErr.Origin = origins.of(fieldErr.Key)
+		}
+	}
+	return err
+}
+
+// load reads the configuration, resolves its references and decodes it into target, without
+// validation
+func (l Loader) load(target interface{}) (*viper.Viper, error) {
+	v, err := l.read(target)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := resolveReferences(v); err != nil {
+		origins, originsErr := newOrigins(l, v)
+		if originsErr != nil {
+			return nil, originsErr
+		}
+		return nil, &FieldError{Key: key, Message: "has an invalid reference: " + err.Error(), Origin: origins.of(key)}
+	}
+	return v, decode(v, target)
+}
+
+// read merges the layers in a new viper instance. The keys of target are used to find the
+// environment variables of the keys missing from the defaults.
+func (l Loader) read(target interface{}) (*viper.Viper, error) {
+	v := viper.New()
+
+	defaults := viper.New()
+	defaults.SetConfigType(l.defaultsType())
+	if err := defaults.ReadConfig(bytes.NewReader(l.Defaults)); err != nil {
+		return nil, fmt.Errorf("cannot read the default configuration: %w", err)
+	}
+	for _, key := range defaults.AllKeys() {
+		v.SetDefault(key, defaults.Get(key))
+	}
+
+	if l.File != "" {
+		v.SetConfigFile(l.File)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("cannot read the configuration file %s: %w", l.File, err)
+		}
+	}
+
+	if !l.NoEnv {
+		v.SetEnvPrefix(l.envPrefix())
+		v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		v.AutomaticEnv()
+		// viper only reads the environment variables of the keys it knows
+		for _, key := range Keys(target) {
+			if err := v.BindEnv(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for name, key := range l.flagKeys() {
+		// a bound flag is only used when it is set, or when no other layer has the key: bind the
+		// flags set on the command line only
+		if err := v.BindPFlag(key, l.Flags.Lookup(name)); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (l Loader) defaultsType() string {
+	if l.DefaultsType == "" {
+		return "yaml"
+	}
+	return l.DefaultsType
+}
+
+func (l Loader) envPrefix() string {
+	if l.EnvPrefix == "" {
+		return DefaultEnvPrefix
+	}
+	return l.EnvPrefix
+}
+
+// flagKeys returns the keys of the flags set on the command line, by flag name
+func (l Loader) flagKeys() map[string]string {
+	keys := make(map[string]string)
+	if l.Flags == nil {
+		return keys
+	}
+	l.Flags.Visit(func(flag *pflag.Flag) {
+		key, found := l.FlagKeys[flag.Name]
+		if !found {
+			key = flag.Name
+		}
+		keys[flag.Name] = key
+	})
+	return keys
+}
+
+// decode decodes the settings of v into target
+func decode(v *viper.Viper, target interface{}) error {
+	err := v.Unmarshal(target, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Keys returns the keys of the fields of a struct, or of a pointer to a struct, like "server.port"
+// for the field Port of the field Server. The keys are in lower case, like the viper keys.
+func Keys(target interface{}) []string {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return nil
+	}
+	return keys(t, "")
+}
+
+func keys(t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var found []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, squash := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		// the structs decoded from a text, like time.Time, are values rather than nested keys
+		nested := fieldType.Kind() == reflect.Struct && !reflect.PtrTo(fieldType).Implements(textUnmarshalerType)
+		switch {
+		case nested && squash:
+			found = append(found, keys(fieldType, prefix)...)
+		case nested:
+			found = append(found, keys(fieldType, prefix+name+".")...)
+		default:
+			found = append(found, prefix+name)
+		}
+	}
+	return found
+}
+
+// fieldName returns the key of a struct field, and tells if its fields are squashed into its parent
+func fieldName(field reflect.StructField) (string, bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	squash := strings.Contains(options, "squash")
+	if name == "" {
+		name = field.Name
+	}
+	return strings.ToLower(name), squash
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ConvertFormats are the formats a configuration can be converted to
+var ConvertFormats = []string{"yaml", "json", "toml", "env"}
+
+// Convert reads a configuration document in the format from, any format known by viper, and writes
+// it in the format to, one of ConvertFormats. The references are kept as they are, and the keys are
+// written in lower case, like viper reads them. The env format writes the environment variables
+// overriding the keys, like APP_SERVER_PORT=8080 with the prefix APP: the lists of values are joined
+// by commas, and the lists of objects cannot be written. The keys holding a dot are refused: the
+// loader would read them as nested keys.
+func Convert(w io.Writer, r io.Reader, from string, to string, envPrefix string) error {
+	// viper splits the keys at the delimiter: another delimiter than the dot keeps the dotted keys
+	v := viper.NewWithOptions(viper.KeyDelimiter(convertKeyDelimiter))
+	v.SetConfigType(from)
+	if err := v.ReadConfig(r); err != nil {
+		return fmt.Errorf("cannot read the %s configuration: %w", from, err)
+	}
+	if err := checkKeys(v.AllSettings(), ""); err != nil {
+		return err
+	}
+	switch to {
+	case "env":
+		return writeEnv(w, v.AllSettings(), envPrefix)
+	case "yaml", "json", "toml":
+		// viper only writes files: its encoders write to a file in memory
+		fs := afero.NewMemMapFs()
+		v.SetFs(fs)
+		file := "/config." + to
+		if err := v.WriteConfigAs(file); err != nil {
+			return err
+		}
+		data, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return err
+		}
+		// the JSON encoder does not end the document with a new line
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return fmt.Errorf("cannot convert to %q, the formats are: %s", to, strings.Join(ConvertFormats, ", "))
+}
+
+// convertKeyDelimiter separates the nested keys while converting, instead of the dot
+const convertKeyDelimiter = "::"
+
+// checkKeys returns an error when a key of the settings, or of the nested objects, holds a dot
+func checkKeys(settings map[string]interface{}, prefix string) error {
+	for key, value := range settings {
+		if strings.Contains(key, ".") {
+			return fmt.Errorf("the key %q holds a dot, which the loader reads as nested keys", prefix+key)
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := checkKeys(nested, prefix+key+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeEnv writes the settings as environment variables, sorted by name
+func writeEnv(w io.Writer, settings map[string]interface{}, prefix string) error {
+	// the values by key
+	variables := make(map[string]string)
+	if err := flattenEnv(variables, settings, ""); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// the names are the ones read by the loader, like APP_SERVER_PORT for server.port
+		variable := strings.ToUpper(prefix + "_" + strings.ReplaceAll(key, ".", "_"))
+		if _, err := fmt.Fprintf(w, "%s=%s\n", variable, quoteEnv(variables[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenEnv maps the keys of the values, like "server.port", to their text
+func flattenEnv(variables map[string]string, settings map[string]interface{}, prefix string) error {
+	for key, value := range settings {
+		key = prefix + key
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if err := flattenEnv(variables, value, key+"."); err != nil {
+				return err
+			}
+		case []interface{}:
+			// the loader splits the values of the lists at the commas
+			items := make([]string, len(value))
+			for i, item := range value {
+				switch item.(type) {
+				case map[string]interface{}, []interface{}:
+					return fmt.Errorf("%s: a list of objects or lists cannot be written as an environment variable", key)
+				}
+				items[i] = fmt.Sprint(item)
+			}
+			variables[key] = strings.Join(items, ",")
+		case nil:
+			variables[key] = ""
+		default:
+			variables[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+// plainEnvValue matches the values which do not need quotes in a shell or a .env file
+var plainEnvValue = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=-]*$`)
+
+// quoteEnv quotes the value like a POSIX shell reads it: between single quotes, which keep every
+// character. A single quote ends the quoted text, is escaped with a backslash, and a new quoted text
+// starts.
+func quoteEnv(value string) string {
+	if plainEnvValue.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+	"strconv"
+	"strings"
+)
+
+// lineFinder returns the line of a key path in a configuration document, like
+// ["people", "0", "firstname"]. When the key is missing, it returns the line of its closest parent
+// found, or 0.
+type lineFinder func(path []string) int
+
+// newLineFinder parses a document to find the lines of its keys. It returns nil for the formats
+// without line information, or when the document cannot be parsed.
+func newLineFinder(content []byte, format string) lineFinder {
+	switch strings.ToLower(format) {
+	// JSON is a subset of YAML, its lines are found the same way
+	case "yaml", "yml", "json":
+		var root yaml.Node
+		if err := yaml.Unmarshal(content, &root); err != nil {
+			return nil
+		}
+		return func(path []string) int {
+			return yamlLine(&root, path)
+		}
+	case "toml":
+		tree, err := toml.LoadBytes(content)
+		if err != nil {
+			return nil
+		}
+		return func(path []string) int {
+			return tomlLine(tree, path)
+		}
+	}
+	return nil
+}
+
+func yamlLine(node *yaml.Node, path []string) int {
+	line := 0
+	for _, segment := range path {
+		for node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+			if node.Kind == yaml.AliasNode {
+				node = node.Alias
+			} else if len(node.Content) > 0 {
+				node = node.Content[0]
+			} else {
+				return line
+			}
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			found := false
+			// the keys are read whatever their case, like viper does
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if strings.EqualFold(node.Content[i].Value, segment) {
+					line, node, found = node.Content[i].Line, node.Content[i+1], true
+					break
+				}
+			}
+			if !found {
+				return line
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return line
+			}
+			node = node.Content[index]
+			line = node.Line
+		default:
+			return line
+		}
+	}
+	return line
+}
+
+func tomlLine(tree *toml.Tree, path []string) int {
+	line := 0
+	var value interface{} = tree
+	for _, segment := range path {
+		switch current := value.(type) {
+		case *toml.Tree:
+			key := ""
+			for _, k := range current.Keys() {
+				if strings.EqualFold(k, segment) {
+					key = k
+				}
+			}
+			if key == "" {
+				return line
+			}
+			line = current.GetPositionPath([]string{key}).Line
+			value = current.GetPath([]string{key})
+		case []*toml.Tree:
+			// an array of tables, like [[people]]
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return line
+			}
+			value = current[index]
+			line = current[index].Position().Line
+		default:
+			return line
+		}
+	}
+	return line
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Layer is a source of configuration values
+type Layer int
+
+const (
+	// LayerUnset is the layer of the keys set nowhere
+	LayerUnset Layer = iota
+	LayerDefault
+	LayerFile
+	LayerEnv
+	LayerFlag
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerUnset:
+		return "unset"
+	case LayerDefault:
+		return "default"
+	case LayerFile:
+		return "file"
+	case LayerEnv:
+		return "env"
+	case LayerFlag:
+		return "flag"
+	}
+	return "unknown"
+}
+
+// Origin tells where a configuration value comes from
+type Origin struct {
+	Layer Layer
+	// Name is the file, the environment variable or the flag setting the value
+	Name string
+	// Line is the line of the value in the defaults or in the file, 0 when unknown
+	Line int
+}
+
+func (o Origin) String() string {
+	switch o.Layer {
+	case LayerDefault, LayerFile:
+		if o.Line > 0 {
+			return o.Name + ":" + strconv.Itoa(o.Line)
+		}
+		return o.Name
+	case LayerEnv:
+		return "$" + o.Name
+	case LayerFlag:
+		return "--" + o.Name
+	}
+	return ""
+}
+
+// origins finds the origins of the values of a loaded configuration
+type origins struct {
+	v *viper.Viper
+	// envPrefix is empty when the environment is not read
+	envPrefix string
+	// flags maps the keys to the flags set on the command line
+	flags map[string]string
+	file  string
+	// fileLines and defaultsLines are nil when the lines are unknown
+	fileLines     lineFinder
+	defaultsLines lineFinder
+}
+
+func newOrigins(l Loader, v *viper.Viper) (*origins, error) {
+	o := &origins{v: v, flags: make(map[string]string), file: l.File}
+	if !l.NoEnv {
+		o.envPrefix = l.envPrefix()
+	}
+	if l.Flags != nil {
+		for name, key := range l.flagKeys() {
+			o.flags[strings.ToLower(key)] = name
+		}
+	}
+	o.defaultsLines = newLineFinder(l.Defaults, l.defaultsType())
+	if l.File != "" {
+		content, err := os.ReadFile(l.File)
+		if err != nil {
+			return nil, err
+		}
+		o.fileLines = newLineFinder(content, strings.TrimPrefix(filepath.Ext(l.File), "."))
+	}
+	return o, nil
+}
+
+// of returns the origin of the value of a key path, like "server.port" or "people[0].firstname".
+// The items of a list come from the layer setting the list.
+func (o *origins) of(key string) Origin {
+	path := splitKey(key)
+	// the viper key is the path before the first index
+	viperKey := strings.ToLower(key)
+	for i, segment := range path {
+		if _, err := strconv.Atoi(segment); err == nil && i > 0 {
+			viperKey = strings.ToLower(strings.Join(path[:i], "."))
+			break
+		}
+	}
+
+	if flag, found := o.flags[viperKey]; found {
+		return Origin{Layer: LayerFlag, Name: flag}
+	}
+	if o.envPrefix != "" {
+		name := strings.ToUpper(o.envPrefix + "_" + strings.ReplaceAll(viperKey, ".", "_"))
+		if _, found := os.LookupEnv(name); found {
+			return Origin{Layer: LayerEnv, Name: name}
+		}
+	}
+	if o.file != "" && o.v.InConfig(viperKey) {
+		return Origin{Layer: LayerFile, Name: o.file, Line: o.fileLines.line(path)}
+	}
+	if o.v.IsSet(viperKey) {
+		return Origin{Layer: LayerDefault, Name: "defaults", Line: o.defaultsLines.line(path)}
+	}
+	return Origin{Layer: LayerUnset}
+}
+
+// line returns the line of the path, or 0 when the lines are unknown
+func (f lineFinder) line(path []string) int {
+	if f == nil {
+		return 0
+	}
+	return f(path)
+}
+
+// splitKey splits a key path like "people[0].firstname" into ["people", "0", "firstname"]
+func splitKey(key string) []string {
+	key = strings.NewReplacer("[", ".", "]", "").Replace(key)
+	return strings.Split(key, ".")
+}
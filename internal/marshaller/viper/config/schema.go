@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The JSON Schema of a configuration struct lets the editors check and complete its files. The YAML
+// files refer to their schema with a comment understood by the YAML language server of the editors:
+//
+//	# yaml-language-server: $schema=viper.schema.json
+//
+// The properties are named like the keys of the files: by their mapstructure tag, or by the name of
+// their field starting with a lower case letter, like logLevel for LogLevel. The validate tags are
+// translated when JSON Schema has an equivalent: oneof, semver, regexp and the range rules. The
+// required rule is not, since a file does not have to set the values of the defaults.
+
+// SchemaVersion is the JSON Schema dialect of the generated schemas
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema, limited to the keywords describing a configuration
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+const (
+	// durationPattern matches the durations read by time.ParseDuration, like 1m30s
+	durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+	// semverPattern matches the semantic versions, see https://semver.org
+	semverPattern = `^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// NewSchema returns the JSON Schema of the files of a configuration struct, or of a pointer to a
+// struct
+func NewSchema(target interface{}, title string) *Schema {
+	schema := typeSchema(reflect.TypeOf(target))
+	schema.Schema = SchemaVersion
+	schema.Title = title
+	return schema
+}
+
+// WriteSchema writes the JSON Schema of a configuration struct, indented
+func WriteSchema(w io.Writer, target interface{}, title string) error {
+	data, err := json.MarshalIndent(NewSchema(target, title), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// typeSchema returns the schema of the values of a type
+func typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// the values decoded from a text, like the durations or the secrets, are strings in the files
+	if t == durationType {
+		return &Schema{Type: "string", Pattern: durationPattern}
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		addProperties(schema, t)
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	// any value, like the fields of type interface{}
+	return &Schema{}
+}
+
+// addProperties adds the fields of a struct to the properties of its schema
+func addProperties(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, squash := schemaName(field)
+		if name == "-" {
+			continue
+		}
+		property := typeSchema(field.Type)
+		if squash && property.Type == "object" && property.Properties != nil {
+			for name, nested := range property.Properties {
+				schema.Properties[name] = nested
+			}
+			continue
+		}
+		applyRules(property, field.Tag.Get("validate"))
+		schema.Properties[name] = property
+	}
+}
+
+// schemaName returns the name of a field in the files: its mapstructure tag, or its name starting
+// with a lower case letter. The loader reads the keys whatever their case, but the schemas do not.
+func schemaName(field reflect.StructField) (string, bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	squash := strings.Contains(options, "squash")
+	if name == "" {
+		name = lowerCamel(field.Name)
+	}
+	return name, squash
+}
+
+// lowerCamel lowers the first letter of a name, or its leading acronym: Version is version, TLS is
+// tls and APIToken is apiToken
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// the last upper case letter of an acronym followed by a word starts the word
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	if upper == 0 && len(runes) > 0 {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+	return string(runes)
+}
+
+// applyRules translates the validate rules of a field into schema keywords. The rules after dive
+// apply to the items of the lists.
+func applyRules(schema *Schema, tag string) {
+	if tag == "" || tag == "-" {
+		return
+	}
+	rules, itemRules, dive := strings.Cut(tag, ",dive")
+	if strings.HasPrefix(tag, "dive") {
+		rules, itemRules, dive = "", strings.TrimPrefix(tag, "dive"), true
+	}
+	if dive && schema.Items != nil {
+		applyRules(schema.Items, strings.TrimPrefix(itemRules, ","))
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema.Type, value))
+			}
+		case "semver":
+			schema.Pattern = semverPattern
+		case "regexp":
+			schema.Pattern = param
+		case "min", "gte":
+			applyBound(schema, param, &schema.Minimum, &schema.MinLength, &schema.MinItems, 0)
+		case "max", "lte":
+			applyBound(schema, param, &schema.Maximum, &schema.MaxLength, &schema.MaxItems, 0)
+		case "len":
+			applyBound(schema, param, &schema.Minimum, &schema.MinLength, &schema.MinItems, 0)
+			applyBound(schema, param, &schema.Maximum, &schema.MaxLength, &schema.MaxItems, 0)
+		case "gt":
+			applyBound(schema, param, &schema.ExclusiveMinimum, &schema.MinLength, &schema.MinItems, 1)
+		case "lt":
+			applyBound(schema, param, &schema.ExclusiveMaximum, &schema.MaxLength, &schema.MaxItems, -1)
+		}
+	}
+}
+
+// applyBound sets the bound of a number, or of the length of a string or of a list. The lengths are
+// integers: their exclusive bounds are shifted by shift to be inclusive.
+func applyBound(schema *Schema, param string, number **float64, length **int, items **int, shift int) {
+	switch schema.Type {
+	case "integer", "number":
+		if value, err := strconv.ParseFloat(param, 64); err == nil {
+			*number = &value
+		}
+	case "string", "array":
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		value += shift
+		if schema.Type == "string" {
+			*length = &value
+		} else {
+			*items = &value
+		}
+	}
+}
+
+// enumValue converts a value of a oneof rule to the type of its field
+func enumValue(schemaType string, value string) interface{} {
+	switch schemaType {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// The values of the configuration can reference an environment variable or a file, replaced when
+// the configuration is loaded, so that the secrets are not written in the configuration files:
+//
+//	database:
+//	  password: ${env:DB_PASSWORD}
+//	  url: postgres://albums:${env:DB_PASSWORD}@localhost/albums
+//	jwt:
+//	  key: ${file:/run/secrets/jwt.key}
+//
+// The files are read without their final line break. Write $${ for a literal ${.
+// The secrets should be decoded into a Secret field, so that they are not printed or logged.
+
+// redacted replaces the secrets in the outputs
+const redacted = "******"
+
+// Secret is a configuration value which must not be printed: its String, GoString, MarshalJSON and
+// MarshalText methods return a redacted value. Use Value to read it.
+type Secret string
+
+// Value returns the secret itself
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads the secret itself: it is the reverse of MarshalText for the decoders only
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = Secret(text)
+	return nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", s.String())), nil
+}
+
+// reference matches the references, and the escaped $${
+var reference = regexp.MustCompile(`\$\$\{|\$\{\s*(env|file):([^}]*)\}`)
+
+// resolve replaces the references of a value
+func resolve(value string) (string, error) {
+	var err error
+	resolved := reference.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		groups := reference.FindStringSubmatch(match)
+		kind, name := groups[1], strings.TrimSpace(groups[2])
+		switch kind {
+		case "env":
+			content, found := os.LookupEnv(name)
+			if !found && err == nil {
+				err = fmt.Errorf("the environment variable %s is not set", name)
+			}
+			return content
+		default:
+			content, readErr := os.ReadFile(name)
+			if readErr != nil && err == nil {
+				err = readErr
+			}
+			return strings.TrimRight(string(content), "\r\n")
+		}
+	})
+	return resolved, err
+}
+
+// resolveValue replaces the references of the strings of a value, in the lists and maps too
+func resolveValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return resolve(v)
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if resolved[i], err = resolveValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var err error
+			if resolved[key], err = resolveValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// resolveReferences replaces the references of the settings of v. It returns the key of the first
+// invalid reference with its error.
+func resolveReferences(v *viper.Viper) (string, error) {
+	for _, key := range v.AllKeys() {
+		value := v.Get(key)
+		resolved, err := resolveValue(value)
+		if err != nil {
+			return key, err
+		}
+		if !reflect.DeepEqual(resolved, value) {
+			v.Set(key, resolved)
+		}
+	}
+	return "", nil
+}
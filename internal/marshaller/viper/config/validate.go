@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// The configuration structs declare their rules with validate tags, like the gin binding tags:
+//
+//	type Config struct {
+//		Version string   `validate:"required,semver"`
+//		Level   string   `validate:"oneof=debug info warn error"`
+//		Port    int      `validate:"min=1,max=65535"`
+//		Name    string   `validate:"regexp=^[a-z]+$"`
+//		People  []People `validate:"min=1,dive"`
+//	}
+//
+// Every rule of github.com/go-playground/validator is available, plus regexp. The patterns of regexp
+// cannot hold the commas and the pipes separating the rules.
+
+// FieldError is an invalid configuration value
+type FieldError struct {
+	// Key is the path of the value, like "people[0].firstname"
+	Key string
+	// Message tells the broken rule, like "is required"
+	Message string
+	// Origin is the layer setting the value. It is only known when the value was loaded by a Loader.
+	Origin Origin
+}
+
+func (e *FieldError) Error() string {
+	if origin := e.Origin.String(); origin != "" {
+		return fmt.Sprintf("%s: %s %s", origin, e.Key, e.Message)
+	}
+	return e.Key + " " + e.Message
+}
+
+// ValidationError holds the invalid values of a configuration
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// validate is shared: the validator caches the rules of the structs
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// name the fields by their configuration key
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _ := fieldName(field)
+		return name
+	})
+	if err := v.RegisterValidation("regexp", matchRegexp); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// patterns caches the compiled regexp patterns
+var patterns sync.Map
+
+func matchRegexp(field validator.FieldLevel) bool {
+	pattern := field.Param()
+	compiled, found := patterns.Load(pattern)
+	if !found {
+		// an invalid pattern is a bug of the struct, like an invalid rule
+		compiled, _ = patterns.LoadOrStore(pattern, regexp.MustCompile(pattern))
+	}
+	return field.Field().Kind() == reflect.String && compiled.(*regexp.Regexp).MatchString(field.Field().String())
+}
+
+// Validate checks the rules of the validate tags of a struct, or of a pointer to a struct. The
+// invalid values are returned in a *ValidationError.
+func Validate(target interface{}) error {
+	err := validate.Struct(target)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+	validationErr := &ValidationError{}
+	for _, fieldErr := range fieldErrs {
+		// the namespace starts with the name of the struct type, like Config.people[0].firstname
+		_, key, _ := strings.Cut(fieldErr.Namespace(), ".")
+		validationErr.Errors = append(validationErr.Errors, &FieldError{Key: key, Message: message(fieldErr)})
+	}
+	return validationErr
+}
+
+// message describes the rule broken by a value
+func message(err validator.FieldError) string {
+	subject := "must be"
+	switch err.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		subject = "must have a length of"
+	}
+	switch err.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("is %q, must be one of: %s", fmt.Sprint(err.Value()), strings.Join(strings.Fields(err.Param()), ", "))
+	case "semver":
+		return fmt.Sprintf("is %q, must be a semantic version like 1.2.3", fmt.Sprint(err.Value()))
+	case "regexp":
+		return fmt.Sprintf("is %q, must match %s", fmt.Sprint(err.Value()), err.Param())
+	case "min", "gte":
+		return fmt.Sprintf("%s at least %s, not %v", subject, err.Param(), length(err))
+	case "max", "lte":
+		return fmt.Sprintf("%s at most %s, not %v", subject, err.Param(), length(err))
+	case "gt":
+		return fmt.Sprintf("%s more than %s, not %v", subject, err.Param(), length(err))
+	case "lt":
+		return fmt.Sprintf("%s less than %s, not %v", subject, err.Param(), length(err))
+	}
+	if err.Param() != "" {
+		return fmt.Sprintf("breaks the rule %s=%s", err.Tag(), err.Param())
+	}
+	return "breaks the rule " + err.Tag()
+}
+
+// length returns the value checked by a range rule: the length of the strings and collections
+func length(err validator.FieldError) interface{} {
+	value := reflect.ValueOf(err.Value())
+	switch err.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len()
+	}
+	return err.Value()
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"github.com/fsnotify/fsnotify"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// reloadDelay groups the events of a file change: the editors often write a file in several steps
+const reloadDelay = 100 * time.Millisecond
+
+// Watcher holds the active configuration of a program, and reloads it when its file changes. A new
+// configuration replaces the active one only when it is valid: a program never sees an invalid
+// configuration. A Watcher is safe for concurrent use.
+type Watcher[T any] struct {
+	loader  Loader
+	current atomic.Pointer[T]
+
+	// reloading serializes the reloads, so that the subscribers see the changes in order
+	reloading sync.Mutex
+
+	mu          sync.Mutex
+	nextID      int
+	subscribers []subscriber[T]
+}
+
+type subscriber[T any] struct {
+	id       int
+	onChange func(previous *T, current *T)
+}
+
+// NewWatcher loads the configuration, and returns an error when it is invalid
+func NewWatcher[T any](loader Loader) (*Watcher[T], error) {
+	w := &Watcher[T]{loader: loader}
+	current := new(T)
+	if err := loader.Load(current); err != nil {
+		return nil, err
+	}
+	w.current.Store(current)
+	return w, nil
+}
+
+// Current returns the active configuration. It is shared: do not modify it.
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Subscribe calls onChange after every change of the configuration, with the previous and the new
+// one. The callbacks are called by the reloading goroutine, one at a time, in their subscription
+// order: they must not block. The returned function cancels the subscription.
+func (w *Watcher[T]) Subscribe(onChange func(previous *T, current *T)) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	w.subscribers = append(w.subscribers, subscriber[T]{id, onChange})
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		for i, s := range w.subscribers {
+			if s.id == id {
+				// copy the slice rather than modifying it: Reload may be reading it
+				w.subscribers = append(w.subscribers[:i:i], w.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Changes returns a channel receiving the new configurations. A slow reader misses the intermediate
+// changes, but always receives the last one. The returned function cancels the subscription; the
+// channel is not closed.
+func (w *Watcher[T]) Changes() (<-chan *T, func()) {
+	changes := make(chan *T, 1)
+	cancel := w.Subscribe(func(_ *T, current *T) {
+		// replace the change not read yet
+		select {
+		case <-changes:
+		default:
+		}
+		changes <- current
+	})
+	return changes, cancel
+}
+
+// Reload loads the configuration again. The active configuration is replaced, and the subscribers
+// notified, when the new one is valid and different. Otherwise, the active configuration is kept
+// and the error returned.
+func (w *Watcher[T]) Reload() error {
+	w.reloading.Lock()
+	defer w.reloading.Unlock()
+
+	next := new(T)
+	if err := w.loader.Load(next); err != nil {
+		return err
+	}
+	previous := w.current.Load()
+	if reflect.DeepEqual(previous, next) {
+		return nil
+	}
+	w.current.Store(next)
+
+	w.mu.Lock()
+	subscribers := w.subscribers
+	w.mu.Unlock()
+	for _, s := range subscribers {
+		s.onChange(previous, next)
+	}
+	return nil
+}
+
+// Watch reloads the configuration when its file changes, until the context is done. The reload
+// errors, like an invalid file, are given to onError, or logged when onError is nil: the active
+// configuration is kept until the file is fixed.
+func (w *Watcher[T]) Watch(ctx context.Context, onError func(err error)) error {
+	if w.loader.File == "" {
+		return errors.New("there is no configuration file to watch")
+	}
+	if onError == nil {
+		onError = func(err error) {
+			log.Println("cannot reload the configuration:", err)
+		}
+	}
+	file, err := filepath.Abs(w.loader.File)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	// watch the directory rather than the file: the editors and Kubernetes replace the file, which
+	// ends the watch of the file itself
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// Kubernetes mounts the files of a ConfigMap as links to a ..data directory, replaced on change
+			if filepath.Clean(event.Name) == file || filepath.Base(event.Name) == "..data" {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			onError(err)
+		case <-timer.C:
+			if err := w.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -1,65 +1,66 @@
 package viper
 
 import (
-	"bytes"
 	_ "embed"
-	"github.com/spf13/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang_starter/internal/marshaller/viper/config"
 	"log"
 )
 
+// This tutorial shows the usage of viper through the config package: the embedded default
+// configuration is overridden by a file, by the APP_ environment variables, like APP_VERSION, and by
+// the command line flags.
+
 var (
 	//go:embed static/conf.yaml
 	data []byte
 )
 
-type people struct {
-	Firstname string
-	Lastname  string
+type People struct {
+	Firstname string `validate:"required"`
+	Lastname  string `validate:"required"`
 }
 
-type config struct {
-	Version string
-	People  []people
+// Config is the configuration of the tutorial, see res/conf/viper.yaml.
+// The validate tags are checked when the configuration is loaded.
+type Config struct {
+	Version string   `validate:"required,semver"`
+	People  []People `validate:"min=1,dive"`
+	// APIToken is redacted when printed
+	APIToken config.Secret `mapstructure:"apiToken"`
 }
 
-var Configuration config
+// RegisterFlags defines the command line flags overriding the configuration
+func RegisterFlags(flags *pflag.FlagSet) {
+	flags.String("version", "", "The version, overriding the configuration")
+}
 
-func Start(path string) {
-	// init viper
-	viper.SetConfigName("myconfig")
-	viper.SetConfigType("yaml")
+// LoadConfig reads the configuration from the embedded defaults, the file when not empty, the
+// environment and the flags set on the command line
+func LoadConfig(file string, flags *pflag.FlagSet) (Config, error) {
+	loader := config.Loader{Defaults: data, File: file, Flags: flags}
+	var configuration Config
+	err := loader.Load(&configuration)
+	return configuration, err
+}
 
-	// read a file content from given bytes
-	err := viper.ReadConfig(bytes.NewBuffer(data))
-	if err != nil {
-		log.Println(err.Error())
-	} else {
-		version := viper.Get("version")
-		log.Printf("Version from default conf is: %s\n", version)
-	}
+// NewConfigCommand creates the config command line, checking the configuration files of the tutorial
+func NewConfigCommand() *cobra.Command {
+	return config.NewCommand(config.Loader{Defaults: data}, func() interface{} { return &Config{} })
+}
 
-	// read a file from a given path
+func Start(path string, flags *pflag.FlagSet) {
 	log.Println("Path is:", path)
-	viper.AddConfigPath(path)
-	viper.SetConfigFile(path)
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			log.Printf("File not found")
-		} else {
-			log.Printf("File content error")
-		}
-	}
-
-	log.Println("Configuration found and file format is ok")
-
-	// Unmarshalling
-	// validate schema with structure
-	if err := viper.Unmarshal(&Configuration); err != nil {
-		log.Println("Unable to validate the config schema:", path)
+	configuration, err := LoadConfig(path, flags)
+	if err != nil {
 		log.Fatalln(err)
 	}
 	log.Println("Configuration content is ok and loaded")
-	log.Println("Version:", Configuration.Version)
-	firstPeople := Configuration.People[0]
+	log.Println("Version:", configuration.Version)
+	// the validation ensures that there are people
+	firstPeople := configuration.People[0]
 	log.Printf("First people name is: '%s %s'", firstPeople.Firstname, firstPeople.Lastname)
+	// the secrets are redacted in the logs: the token is printed as ******
+	log.Println("API token:", configuration.APIToken)
 }
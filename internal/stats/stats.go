@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// stats summarizes the latencies measured by the load tools: the count of calls, the errors and the
+// latency percentiles.
+
+// Recorder collects the latencies of calls. It can be shared by concurrent goroutines.
+type Recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+}
+
+// Record adds the latency of a call, counting it as an error when err is not nil.
+// The latencies of the failed calls are kept: a timeout is a slow call too.
+func (r *Recorder) Record(latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, latency)
+	if err != nil {
+		r.errors++
+	}
+}
+
+// Summary summarizes the latencies recorded so far
+func (r *Recorder) Summary() Summary {
+	r.mu.Lock()
+	latencies := make([]time.Duration, len(r.latencies))
+	copy(latencies, r.latencies)
+	errors := r.errors
+	r.mu.Unlock()
+
+	summary := Summarize(latencies)
+	summary.Errors = errors
+	return summary
+}
+
+// Summary holds the statistics of a set of latencies
+type Summary struct {
+	Count  int
+	Errors int
+	Min    time.Duration
+	Mean   time.Duration
+	Max    time.Duration
+	P50    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+}
+
+// Summarize computes the statistics of the latencies, without errors. The slice is sorted in place.
+func Summarize(latencies []time.Duration) Summary {
+	if len(latencies) == 0 {
+		return Summary{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	return Summary{
+		Count: len(latencies),
+		Min:   latencies[0],
+		Mean:  total / time.Duration(len(latencies)),
+		Max:   latencies[len(latencies)-1],
+		P50:   Percentile(latencies, 50),
+		P95:   Percentile(latencies, 95),
+		P99:   Percentile(latencies, 99),
+	}
+}
+
+// Percentile returns the p-th percentile of sorted latencies, with the nearest-rank method: the
+// smallest latency greater than or equal to p percent of the latencies.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// MarshalJSON writes the latencies in milliseconds, which are easier to read than the nanoseconds
+// of time.Duration
+func (s Summary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Count  int     `json:"count"`
+		Errors int     `json:"errors"`
+		Min    float64 `json:"minMs"`
+		Mean   float64 `json:"meanMs"`
+		Max    float64 `json:"maxMs"`
+		P50    float64 `json:"p50Ms"`
+		P95    float64 `json:"p95Ms"`
+		P99    float64 `json:"p99Ms"`
+	}{s.Count, s.Errors, Milliseconds(s.Min), Milliseconds(s.Mean), Milliseconds(s.Max),
+		Milliseconds(s.P50), Milliseconds(s.P95), Milliseconds(s.P99)})
+}
+
+// Milliseconds returns a duration in milliseconds, with a microsecond precision
+func Milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
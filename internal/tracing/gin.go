@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// Middleware traces the requests handled by a gin router. The span continues the trace of the
+// traceparent header, and its context is given to the handlers through c.Request.Context().
+// Declare it before the other middlewares, so that the span covers them.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		// the route, like /albums/:id, names the span better than the path, which holds the ids
+		route := c.FullPath()
+		if route == "" {
+			route = "unknown route"
+		}
+		ctx, span := tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.HTTPTarget(c.Request.URL.RequestURI()),
+			))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		statusCode := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCode(statusCode))
+		// the client errors are the client's fault, not the server's
+		if statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP status %d", statusCode))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
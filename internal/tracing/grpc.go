@@ -0,0 +1,195 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+	"sync"
+)
+
+// The gRPC interceptors carry the trace context in the call metadata, with the same traceparent key
+// as the HTTP header.
+
+// ServerOptions returns the options tracing the calls of a gRPC server. Add them before the other
+// interceptors, so that the span covers them.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor),
+	}
+}
+
+// DialOptions returns the options tracing the calls of a gRPC client
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor),
+	}
+}
+
+// metadataCarrier reads and writes the trace context in the gRPC metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startServerSpan starts the span of a call received by the server, child of the span of the client
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md.Copy()))
+	return tracer().Start(ctx, spanName(fullMethod), trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+// startClientSpan starts the span of a call sent by the client, and adds its trace context to the
+// outgoing metadata
+func startClientSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := tracer().Start(ctx, spanName(fullMethod), trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcAttributes(fullMethod)...))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// spanName names the span after the method, like main.RouteGuide/GetFeature
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// rpcAttributes describes the method of a call, following the semantic conventions
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(spanName(fullMethod), "/")
+	return []attribute.KeyValue{semconv.RPCSystemKey.String("grpc"), semconv.RPCService(service), semconv.RPCMethod(method)}
+}
+
+// endSpan ends the span of a call with the status of its error
+func endSpan(span trace.Span, err error) {
+	s, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	if err != nil {
+		span.SetStatus(codes.Error, s.Message())
+	}
+	span.End()
+}
+
+// UnaryServerInterceptor traces the unary calls of a server
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+// StreamServerInterceptor traces the streaming calls of a server
+func StreamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startServerSpan(stream.Context(), info.FullMethod)
+	err := handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	endSpan(span, err)
+	return err
+}
+
+// serverStream gives the context of the span to the handler
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor traces the unary calls of a client
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := startClientSpan(ctx, method)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	endSpan(span, err)
+	return err
+}
+
+// StreamClientInterceptor traces the streaming calls of a client. The span ends with the stream:
+// when the server ends it, when the context is done, or when the connection is closed.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := startClientSpan(ctx, method)
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	s := &clientStream{ClientStream: stream, desc: desc, span: span, done: make(chan struct{})}
+	// gRPC cancels the context of the stream once it is over, even when it is not drained, so that
+	// the goroutine does not outlive the stream
+	go func() {
+		select {
+		case <-stream.Context().Done():
+		case <-s.done:
+			return
+		}
+		switch {
+		case ctx.Err() != nil:
+			s.end(status.FromContextError(ctx.Err()).Err())
+		case cc.GetState() == connectivity.Shutdown:
+			s.end(status.Error(grpccodes.Canceled, "grpc: the client connection is closing"))
+		}
+		// otherwise the server ended the stream, and RecvMsg ends the span with its status
+	}()
+	return s, nil
+}
+
+// clientStream ends the span of the call once the stream is over
+type clientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	span trace.Span
+	once sync.Once
+	// done is closed once the span is ended
+	done chan struct{}
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.end(nil)
+	case err != nil:
+		s.end(err)
+	case !s.desc.ServerStreams:
+		// the single answer of a client streaming call ends it
+		s.end(nil)
+	}
+	return err
+}
+
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		endSpan(s.span, err)
+		close(s.done)
+	})
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// attemptKey is the context key of the span of the current attempt of a resty request
+type attemptKey struct{}
+
+// attempt is a try of a request: resty sends the request again when it is retried
+type attempt struct {
+	// parent is the context of the request, before the span of the attempt
+	parent context.Context
+	span   trace.Span
+}
+
+// InstrumentResty adds hooks to a resty client, tracing each attempt of its requests and sending
+// the trace context in the traceparent header. The requests continue the trace of the context given
+// with Request.SetContext.
+func InstrumentResty(client *resty.Client) *resty.Client {
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		parent := r.Context()
+		if previous, ok := parent.Value(attemptKey{}).(*attempt); ok {
+			// a retry: the previous attempt has failed
+			previous.span.SetStatus(codes.Error, "retried")
+			previous.span.End()
+			parent = previous.parent
+		}
+		ctx, span := tracer().Start(parent, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.HTTPMethod(r.Method), semconv.HTTPURL(r.URL)))
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+		r.SetContext(context.WithValue(ctx, attemptKey{}, &attempt{parent: parent, span: span}))
+		return nil
+	})
+	client.OnAfterResponse(func(c *resty.Client, response *resty.Response) error {
+		if a, ok := response.Request.Context().Value(attemptKey{}).(*attempt); ok {
+			a.span.SetAttributes(semconv.HTTPStatusCode(response.StatusCode()))
+			if response.StatusCode() >= http.StatusBadRequest {
+				a.span.SetStatus(codes.Error, fmt.Sprintf("HTTP status %d", response.StatusCode()))
+			}
+			a.span.End()
+		}
+		return nil
+	})
+	// the requests failing without response, like the connection errors, never reach OnAfterResponse
+	client.OnError(func(r *resty.Request, err error) {
+		if a, ok := r.Context().Value(attemptKey{}).(*attempt); ok {
+			a.span.RecordError(err)
+			a.span.SetStatus(codes.Error, err.Error())
+			// ending a span twice does nothing
+			a.span.End()
+		}
+	})
+	return client
+}
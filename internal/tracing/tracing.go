@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// tracing follows a request across the services with OpenTelemetry.
+// Each service records spans: a span measures an operation, like the handling of an HTTP request or
+// a gRPC call, and belongs to a trace shared by all the operations of the request. The trace is
+// propagated to the next service with the W3C trace context headers, like
+//
+//	traceparent: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// The spans are sent to an exporter: the standard error, or an OpenTelemetry collector speaking
+// OTLP over HTTP, which forwards them to Jaeger, Zipkin, Tempo, ...
+
+// The exporters of Config.Exporter
+const (
+	// NoExporter records no span, but still propagates the trace context of the incoming requests
+	NoExporter = "none"
+	// StdoutExporter prints the spans as JSON. They are written on the standard error, so that they
+	// do not mix with the output of the commands, like the JSON of route-guide-client -o json.
+	StdoutExporter = "stdout"
+	// OTLPExporter sends the spans to an OpenTelemetry collector, with OTLP over HTTP
+	OTLPExporter = "otlp"
+)
+
+// instrumentationName names the tracer of the spans created by this package
+const instrumentationName = "golang_starter/internal/tracing"
+
+// Config holds the tracing settings of a service
+type Config struct {
+	// ServiceName identifies the service in the traces
+	ServiceName string
+	// Exporter is one of NoExporter (the default), StdoutExporter or OTLPExporter
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector. The OTLP exporter reads the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable, or uses localhost:4318, when it is not set.
+	Endpoint string
+	// Insecure sends the spans to the collector over HTTP rather than HTTPS
+	Insecure bool
+}
+
+// ConfigFromEnv reads the configuration from the standard OpenTelemetry environment variables:
+// OTEL_SERVICE_NAME and OTEL_TRACES_EXPORTER.
+// serviceName is used when OTEL_SERVICE_NAME is not set.
+func ConfigFromEnv(serviceName string) Config {
+	config := Config{ServiceName: serviceName, Exporter: os.Getenv("OTEL_TRACES_EXPORTER")}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		config.ServiceName = name
+	}
+	// the OTLP exporter reads OTEL_EXPORTER_OTLP_ENDPOINT itself, as a URL like http://localhost:4318
+	return config
+}
+
+// Setup installs the global tracer provider and the W3C propagators used by the middlewares and
+// interceptors of this package. Call the returned function before exiting, to send the last spans.
+func Setup(ctx context.Context, config Config) (shutdown func(context.Context) error, err error) {
+	// the propagators are needed even without exporter, to forward the trace context
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch config.Exporter {
+	case NoExporter, "":
+		return func(context.Context) error { return nil }, nil
+	case StdoutExporter:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case OTLPExporter:
+		var opts []otlptracehttp.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q: use none, stdout or otlp", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s trace exporter: %w", config.Exporter, err)
+	}
+	provider := NewTracerProvider(config.ServiceName, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider recording the spans of the service. The options set
+// where the spans go, like sdktrace.WithBatcher(exporter).
+func NewTracerProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// tracer returns the tracer of the global provider. It is read at every span, so that the provider
+// can be installed after the middlewares.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
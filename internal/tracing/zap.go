@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ZapFields returns the trace_id and span_id fields of the span of the context, to find the logs of
+// a trace. There is no field when the context has no span.
+func ZapFields(ctx context.Context) []zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	}
+}
+
+// Logger returns a logger adding the trace fields of the context to every line
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	return logger.With(ZapFields(ctx)...)
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"golang_starter/internal/api/grpc/go-grpc/auth"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var hmacSecret = []byte("test-secret")
+
+// principals records the principal found in the context of each call, by method
+type principals struct {
+	mu   sync.Mutex
+	seen map[string]*auth.Principal
+}
+
+func (p *principals) record(ctx context.Context, method string) {
+	principal, _ := auth.FromContext(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seen[method] = principal
+}
+
+func (p *principals) get(method string) *auth.Principal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.seen[method]
+}
+
+// startServer serves a RouteGuide server with the authentication configuration on an in-memory
+// listener. The returned function dials it with the given token.
+func startServer(t *testing.T, config auth.Config) (func(token string) pb.RouteGuideClient, *principals) {
+	t.Helper()
+	interceptors, err := config.Interceptors()
+	if err != nil {
+		t.Fatalf("Interceptors() = %v", err)
+	}
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+
+	seen := &principals{seen: make(map[string]*auth.Principal)}
+	routeGuide, err := server.NewServer(server.Config{}, routes)
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, routeGuide)
+	},
+		// the recording interceptors run after the authentication ones
+		grpctest.WithUnaryInterceptors(interceptors.Unary, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			seen.record(ctx, info.FullMethod)
+			return handler(ctx, req)
+		}),
+		grpctest.WithStreamInterceptors(interceptors.Stream, func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			seen.record(stream.Context(), info.FullMethod)
+			return handler(srv, stream)
+		}),
+	)
+
+	dial := func(token string) pb.RouteGuideClient {
+		if token == "" {
+			return pb.NewRouteGuideClient(s.Dial())
+		}
+		return pb.NewRouteGuideClient(s.Dial(grpc.WithPerRPCCredentials(auth.TokenCredentials{Token: token, AllowInsecure: true})))
+	}
+	return dial, seen
+}
+
+// writeFile writes the data to a file of a temporary directory and returns its path
+func writeFile(t *testing.T, data []byte) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() = %v", err)
+	}
+	return token
+}
+
+// TestStaticTokens checks the authentication and the per-method rules with static tokens
+func TestStaticTokens(t *testing.T) {
+	dial, seen := startServer(t, auth.Config{
+		Tokens: []auth.StaticToken{
+			{Token: "reader-token", Subject: "alice", Roles: []string{"reader"}},
+			{Token: "writer-token", Subject: "bob", Roles: []string{"writer"}},
+		},
+		Rules: auth.Rules{
+			Public: []string{"/main.RouteGuide/ListRoutes"},
+			Methods: map[string][]string{
+				"/main.RouteGuide/GetFeature": {"reader"},
+				"/main.RouteGuide/*":          {"writer"},
+			},
+		},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		token string
+		call  func(client pb.RouteGuideClient) error
+		want  codes.Code
+	}{
+		{"no token", "", func(c pb.RouteGuideClient) error { _, err := c.GetFeature(ctx, &pb.Point{}); return err }, codes.Unauthenticated},
+		{"unknown token", "nope", func(c pb.RouteGuideClient) error { _, err := c.GetFeature(ctx, &pb.Point{}); return err }, codes.Unauthenticated},
+		{"method rule", "reader-token", func(c pb.RouteGuideClient) error { _, err := c.GetFeature(ctx, &pb.Point{}); return err }, codes.OK},
+		{"method rule without the role", "writer-token", func(c pb.RouteGuideClient) error { _, err := c.GetFeature(ctx, &pb.Point{}); return err }, codes.PermissionDenied},
+		{"service rule", "writer-token", func(c pb.RouteGuideClient) error {
+			_, err := c.GetRoute(ctx, &pb.GetRouteRequest{Id: 1})
+			return err
+		}, codes.NotFound},
+		{"service rule without the role", "reader-token", func(c pb.RouteGuideClient) error {
+			_, err := c.GetRoute(ctx, &pb.GetRouteRequest{Id: 1})
+			return err
+		}, codes.PermissionDenied},
+		{"public method", "", func(c pb.RouteGuideClient) error { _, err := c.ListRoutes(ctx, &pb.ListRoutesRequest{}); return err }, codes.OK},
+		{"stream", "writer-token", func(c pb.RouteGuideClient) error {
+			stream, err := c.RecordRoute(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = stream.CloseAndRecv()
+			return err
+		}, codes.OK},
+		{"stream without token", "", func(c pb.RouteGuideClient) error {
+			stream, err := c.RecordRoute(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = stream.CloseAndRecv()
+			return err
+		}, codes.Unauthenticated},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.call(dial(test.token)); status.Code(err) != test.want {
+				t.Fatalf("call returned %v, want code %v", err, test.want)
+			}
+		})
+	}
+
+	if principal := seen.get("/main.RouteGuide/GetFeature"); principal == nil || principal.Subject != "alice" {
+		t.Fatalf("GetFeature context holds principal %v, want alice", principal)
+	}
+	if principal := seen.get("/main.RouteGuide/RecordRoute"); principal == nil || principal.Subject != "bob" {
+		t.Fatalf("RecordRoute stream context holds principal %v, want bob", principal)
+	}
+}
+
+// TestJWT checks the JWT verified with a HMAC secret and with an ECDSA public key
+func TestJWT(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&ecdsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyFile := writeFile(t, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	valid := jwt.MapClaims{"sub": "carol", "roles": []string{"reader"}, "iss": "route-guide",
+		"exp": time.Now().Add(time.Hour).Unix()}
+	expired := jwt.MapClaims{"sub": "carol", "iss": "route-guide", "exp": time.Now().Add(-time.Hour).Unix()}
+	wrongIssuer := jwt.MapClaims{"sub": "carol", "iss": "someone"}
+
+	tests := []struct {
+		name    string
+		keyFile string
+		token   string
+		want    codes.Code
+	}{
+		{"HMAC", writeFile(t, hmacSecret), signToken(t, jwt.SigningMethodHS256, hmacSecret, valid), codes.OK},
+		{"HMAC wrong secret", writeFile(t, hmacSecret), signToken(t, jwt.SigningMethodHS256, []byte("other"), valid), codes.Unauthenticated},
+		{"HMAC expired", writeFile(t, hmacSecret), signToken(t, jwt.SigningMethodHS256, hmacSecret, expired), codes.Unauthenticated},
+		{"HMAC wrong issuer", writeFile(t, hmacSecret), signToken(t, jwt.SigningMethodHS256, hmacSecret, wrongIssuer), codes.Unauthenticated},
+		{"ECDSA", publicKeyFile, signToken(t, jwt.SigningMethodES256, ecdsaKey, valid), codes.OK},
+		// a token signed with HMAC using the public key as secret must not be accepted
+		{"ECDSA key used as HMAC secret", publicKeyFile, signToken(t, jwt.SigningMethodHS256,
+			pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), valid), codes.Unauthenticated},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dial, seen := startServer(t, auth.Config{
+				JWT:   auth.JWTConfig{KeyFile: test.keyFile, Issuer: "route-guide"},
+				Rules: auth.Rules{Methods: map[string][]string{"/main.RouteGuide/GetFeature": {"reader"}}},
+			})
+			_, err := dial(test.token).GetFeature(context.Background(), &pb.Point{})
+			if status.Code(err) != test.want {
+				t.Fatalf("GetFeature() = %v, want code %v", err, test.want)
+			}
+			if test.want == codes.OK {
+				principal := seen.get("/main.RouteGuide/GetFeature")
+				if principal == nil || principal.Subject != "carol" || !principal.HasRole("reader") {
+					t.Fatalf("GetFeature context holds principal %v, want carol with role reader", principal)
+				}
+			}
+		})
+	}
+}
+
+// TestLoadConfig checks that the authentication files without authenticator, or with unknown keys,
+// are rejected
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "tokens", content: "tokens:\n  - token: s3cr3t\n    subject: alice\n"},
+		{name: "jwt", content: "jwt:\n  keyFile: key.pem\n"},
+		{name: "rules only", content: "rules:\n  public: [/grpc.health.v1.Health/Check]\n", wantErr: true},
+		{name: "misspelled keyFile", content: "jwt:\n  keyfile: key.pem\n", wantErr: true},
+		{name: "misspelled tokens", content: "token:\n  - token: s3cr3t\n", wantErr: true},
+		{name: "empty file", content: "", wantErr: true},
+	}
+	for _, test := range tests {
+		config, err := auth.LoadConfig(writeFile(t, []byte(test.content)))
+		if (err != nil) != test.wantErr {
+			t.Errorf("LoadConfig(%s) = %+v, %v, want error %v", test.name, config, err, test.wantErr)
+		}
+	}
+}
+
+// TestRulesWithoutRule checks that the methods without rule are denied once a rule is set
+func TestRulesWithoutRule(t *testing.T) {
+	principal := &auth.Principal{Subject: "alice", Roles: []string{"reader"}}
+	rules := auth.Rules{Methods: map[string][]string{"/main.RouteGuide/GetFeature": {}}}
+	if !rules.Allows("/main.RouteGuide/GetFeature", principal) {
+		t.Errorf("Allows(GetFeature) = false, want the rule without roles to allow every principal")
+	}
+	if rules.Allows("/main.RouteGuide/RecordRoute", principal) {
+		t.Errorf("Allows(RecordRoute) = true, want the method without rule denied")
+	}
+	if !(auth.Rules{}).Allows("/main.RouteGuide/RecordRoute", principal) {
+		t.Errorf("Allows(RecordRoute) without rules = false, want every method allowed")
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"golang_starter/internal/api/grpc/go-grpc/greeter/cli"
+	"golang_starter/internal/api/grpc/go-grpc/greeter/server"
+	"google.golang.org/grpc"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// run runs the command line against a Greeter server on a local TCP port, and returns its output
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() = %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	cmd := cli.NewCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	cmd.SetArgs(append([]string{"--host", "127.0.0.1", "--port", port}, args...))
+	err = cmd.Execute()
+	return out.String(), err
+}
+
+// TestCommands checks that every command prints one greeting per line
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		args  []string
+		lines int
+	}{
+		{[]string{"hello", "Gladys"}, 1},
+		{[]string{"replies", "Gladys"}, server.ReplyCount},
+		{[]string{"greetings", "Gladys", "Samantha"}, 2},
+		{[]string{"bidi", "Gladys", "Samantha", "Darrin"}, 3},
+	}
+	for _, test := range tests {
+		out, err := run(t, test.args...)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if err != nil || len(lines) != test.lines {
+			t.Fatalf("%v = %q, %v, want %d greetings", test.args, out, err, test.lines)
+		}
+	}
+}
+
+// TestEmptyName checks that the commands fail with the InvalidArgument error of the server
+func TestEmptyName(t *testing.T) {
+	for _, command := range []string{"hello", "replies", "greetings", "bidi"} {
+		out, err := run(t, command, "")
+		if err == nil || !strings.Contains(out, "InvalidArgument") {
+			t.Fatalf("%s \"\" = %q, %v, want an InvalidArgument error", command, out, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"golang_starter/internal/api/grpc/go-grpc/greeter"
+	"golang_starter/internal/api/grpc/go-grpc/greeter/server"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+	"testing"
+)
+
+// dial serves the Greeter services in memory, and returns a connection to them
+func dial(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	return grpctest.Start(t, server.Register).Conn
+}
+
+// TestSayHello checks the unary method
+func TestSayHello(t *testing.T) {
+	client := greeter.NewGreeterClient(dial(t))
+	reply, err := client.SayHello(context.Background(), &greeter.HelloRequest{Name: "Gladys"})
+	if err != nil || !strings.Contains(reply.GetMessage(), "Gladys") {
+		t.Fatalf("SayHello(Gladys) = %v, %v, want a greeting for Gladys", reply, err)
+	}
+	_, err = client.SayHello(context.Background(), &greeter.HelloRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("SayHello(\"\") = %v, want InvalidArgument", err)
+	}
+}
+
+// TestLotsOfReplies checks the server streaming method
+func TestLotsOfReplies(t *testing.T) {
+	client := greeter.NewGreeterServerStreamClient(dial(t))
+	stream, err := client.LotsOfReplies(context.Background(), &greeter.HelloRequest{Name: "Gladys"})
+	if err != nil {
+		t.Fatalf("LotsOfReplies(Gladys) = %v", err)
+	}
+	count := 0
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil || !strings.Contains(reply.GetMessage(), "Gladys") {
+			t.Fatalf("Recv() = %v, %v, want a greeting for Gladys", reply, err)
+		}
+		count++
+	}
+	if count != server.ReplyCount {
+		t.Fatalf("LotsOfReplies(Gladys) = %d replies, want %d", count, server.ReplyCount)
+	}
+
+	stream, err = client.LotsOfReplies(context.Background(), &greeter.HelloRequest{})
+	if err != nil {
+		t.Fatalf("LotsOfReplies(\"\") = %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("LotsOfReplies(\"\") Recv() = %v, want InvalidArgument", err)
+	}
+}
+
+// TestLotsOfGreetings checks the client streaming method
+func TestLotsOfGreetings(t *testing.T) {
+	client := greeter.NewGreeterClientStreamClient(dial(t))
+	// greetings sends the names and returns the reply
+	greetings := func(names ...string) (*greeter.HelloReply, error) {
+		stream, err := client.LotsOfGreetings(context.Background())
+		if err != nil {
+			t.Fatalf("LotsOfGreetings() = %v", err)
+		}
+		for _, name := range names {
+			if err := stream.Send(&greeter.HelloRequest{Name: name}); err != nil {
+				t.Fatalf("Send(%s) = %v", name, err)
+			}
+		}
+		return stream.CloseAndRecv()
+	}
+
+	names := []string{"Gladys", "Samantha", "Darrin"}
+	reply, err := greetings(names...)
+	if err != nil {
+		t.Fatalf("LotsOfGreetings(%v) = %v", names, err)
+	}
+	lines := strings.Split(reply.GetMessage(), "\n")
+	if len(lines) != len(names) {
+		t.Fatalf("LotsOfGreetings(%v) = %q, want %d lines", names, reply.GetMessage(), len(names))
+	}
+	for i, name := range names {
+		if !strings.Contains(lines[i], name) {
+			t.Fatalf("LotsOfGreetings(%v) line %d = %q, want a greeting for %s", names, i, lines[i], name)
+		}
+	}
+
+	if _, err := greetings("Gladys", ""); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("LotsOfGreetings(Gladys, \"\") = %v, want InvalidArgument", err)
+	}
+}
+
+// TestBidiHello checks the bidirectional streaming method, reading each greeting before sending the
+// next name
+func TestBidiHello(t *testing.T) {
+	client := greeter.NewGreeterBidirectionalStreamClient(dial(t))
+	stream, err := client.BidiHello(context.Background())
+	if err != nil {
+		t.Fatalf("BidiHello() = %v", err)
+	}
+	for _, name := range []string{"Gladys", "Samantha"} {
+		if err := stream.Send(&greeter.HelloRequest{Name: name}); err != nil {
+			t.Fatalf("Send(%s) = %v", name, err)
+		}
+		reply, err := stream.Recv()
+		if err != nil || !strings.Contains(reply.GetMessage(), name) {
+			t.Fatalf("Recv() = %v, %v, want a greeting for %s", reply, err, name)
+		}
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Recv() after CloseSend = %v, want EOF", err)
+	}
+
+	stream, err = client.BidiHello(context.Background())
+	if err != nil {
+		t.Fatalf("BidiHello() = %v", err)
+	}
+	stream.Send(&greeter.HelloRequest{})
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("BidiHello(\"\") Recv() = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,57 @@
+package grpctest
+
+import (
+	"context"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"reflect"
+	"testing"
+)
+
+// TestInterceptorsOrder checks that the server and client interceptors run in the given order
+func TestInterceptorsOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	},
+		grpctest.WithUnaryInterceptors(record("first"), record("second")),
+		grpctest.WithDialOptions(grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, "client")
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})),
+	)
+
+	response, err := healthpb.NewHealthClient(s.Conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || response.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check() = %v, %v, want SERVING", response, err)
+	}
+	if want := []string{"client", "first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("Check() interceptors = %v, want %v", calls, want)
+	}
+}
+
+// TestDial checks that every connection gets its own dial options
+func TestDial(t *testing.T) {
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	})
+	failing := s.Dial(grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return status.Error(codes.Aborted, "not sent")
+	}))
+	if _, err := healthpb.NewHealthClient(failing).Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Aborted {
+		t.Fatalf("Check() = %v, want Aborted", err)
+	}
+	if _, err := healthpb.NewHealthClient(s.Conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+}
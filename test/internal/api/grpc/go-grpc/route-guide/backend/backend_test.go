@@ -0,0 +1,46 @@
+package backend
+
+import (
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"math"
+	"testing"
+)
+
+// half of the earth circumference used by the haversine formula, in metres
+var halfCircumference = math.Pi * 6371000
+
+// TestCalcDistance checks the distance between points on both sides of the antimeridian, around the
+// poles, and at the opposite sides of the earth
+func TestCalcDistance(t *testing.T) {
+	tests := []struct {
+		name   string
+		p1, p2 *pb.Point
+		want   float64 // metres
+		delta  float64
+	}{
+		{"same point", &pb.Point{Latitude: 488583700, Longitude: 22944810}, &pb.Point{Latitude: 488583700, Longitude: 22944810}, 0, 0},
+		{"Paris to London", &pb.Point{Latitude: 488566000, Longitude: 23522000}, &pb.Point{Latitude: 515074000, Longitude: -1278000}, 343556, 100},
+		{"across the antimeridian", &pb.Point{Latitude: 0, Longitude: 1799999000}, &pb.Point{Latitude: 0, Longitude: -1799999000}, 22.2, 0.1},
+		{"antimeridian both signs", &pb.Point{Latitude: 0, Longitude: 1800000000}, &pb.Point{Latitude: 0, Longitude: -1800000000}, 0, 0.001},
+		{"north pole whatever the longitude", &pb.Point{Latitude: 900000000, Longitude: 0}, &pb.Point{Latitude: 900000000, Longitude: 1234567890}, 0, 0.001},
+		{"pole to pole", &pb.Point{Latitude: 900000000, Longitude: 0}, &pb.Point{Latitude: -900000000, Longitude: 0}, halfCircumference, 1},
+		{"around the south pole", &pb.Point{Latitude: -899999000, Longitude: 0}, &pb.Point{Latitude: -899999000, Longitude: 1800000000}, 22.2, 0.1},
+		{"antipodal points", &pb.Point{Latitude: 0, Longitude: 0}, &pb.Point{Latitude: 0, Longitude: 1800000000}, halfCircumference, 1},
+		{"antipodal points off the equator", &pb.Point{Latitude: 450000000, Longitude: 900000000}, &pb.Point{Latitude: -450000000, Longitude: -900000000}, halfCircumference, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := backend.Distance(test.p1, test.p2)
+			if math.IsNaN(got) || math.Abs(got-test.want) > test.delta {
+				t.Fatalf("Distance(%v, %v) = %f, want %f ± %f", test.p1, test.p2, got, test.want, test.delta)
+			}
+			if reverse := backend.Distance(test.p2, test.p1); math.Abs(reverse-got) > 1e-6 {
+				t.Fatalf("Distance is not symmetric: %f and %f", got, reverse)
+			}
+			if rounded := backend.CalcDistance(test.p1, test.p2); rounded != int32(got) {
+				t.Fatalf("CalcDistance(%v, %v) = %d, want %d", test.p1, test.p2, rounded, int32(got))
+			}
+		})
+	}
+}
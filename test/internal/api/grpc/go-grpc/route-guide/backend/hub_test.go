@@ -0,0 +1,135 @@
+package backend
+
+import (
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"sync"
+	"testing"
+	"time"
+)
+
+var hubLocation = &pb.Point{Latitude: 48858370, Longitude: 2294481}
+
+func receiveNote(t *testing.T, sub *backend.Subscription) *pb.RouteNote {
+	t.Helper()
+	select {
+	case note := <-sub.Notes():
+		return note
+	case <-time.After(time.Second):
+		t.Fatal("no note received")
+		return nil
+	}
+}
+
+// TestNoteHubPersistsNotes checks that the notes outlive the subscription that posted them
+func TestNoteHubPersistsNotes(t *testing.T) {
+	hub := backend.NewNoteHub(4, backend.DropNote)
+	first := hub.Subscribe()
+	first.Watch(hubLocation)
+	hub.Publish(&pb.RouteNote{Location: hubLocation, Message: "hello"}, first)
+	first.Close()
+
+	second := hub.Subscribe()
+	defer second.Close()
+	notes := second.Watch(hubLocation)
+	if len(notes) != 1 || notes[0].Message != "hello" {
+		t.Fatalf(`Watch(%v) = %v, want the "hello" note`, hubLocation, notes)
+	}
+}
+
+// TestNoteHubBroadcast checks that a note reaches the subscriptions watching its location, but not
+// its sender nor the subscriptions watching elsewhere
+func TestNoteHubBroadcast(t *testing.T) {
+	hub := backend.NewNoteHub(4, backend.DropNote)
+	sender, watcher, elsewhere := hub.Subscribe(), hub.Subscribe(), hub.Subscribe()
+	defer sender.Close()
+	defer watcher.Close()
+	defer elsewhere.Close()
+	sender.Watch(hubLocation)
+	watcher.Watch(hubLocation)
+	elsewhere.Watch(&pb.Point{Latitude: 1, Longitude: 1})
+
+	hub.Publish(&pb.RouteNote{Location: hubLocation, Message: "hello"}, sender)
+
+	if note := receiveNote(t, watcher); note.Message != "hello" {
+		t.Fatalf(`watcher received %q, want "hello"`, note.Message)
+	}
+	select {
+	case note := <-sender.Notes():
+		t.Fatalf("sender received its own note %v", note)
+	case note := <-elsewhere.Notes():
+		t.Fatalf("subscription watching another location received %v", note)
+	default:
+	}
+}
+
+// TestNoteHubDropPolicy checks that a full buffer drops the notes but keeps the subscription open
+func TestNoteHubDropPolicy(t *testing.T) {
+	hub := backend.NewNoteHub(1, backend.DropNote)
+	slow := hub.Subscribe()
+	defer slow.Close()
+	slow.Watch(hubLocation)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(&pb.RouteNote{Location: hubLocation, Message: "note"}, nil)
+	}
+	if dropped := slow.Dropped(); dropped != 2 {
+		t.Fatalf("Dropped() = %d, want 2", dropped)
+	}
+	select {
+	case <-slow.Done():
+		t.Fatal("subscription closed with the drop policy")
+	default:
+	}
+}
+
+// TestNoteHubDisconnectPolicy checks that a full buffer closes the subscription with ErrSlowConsumer
+func TestNoteHubDisconnectPolicy(t *testing.T) {
+	hub := backend.NewNoteHub(1, backend.Disconnect)
+	slow := hub.Subscribe()
+	defer slow.Close()
+	slow.Watch(hubLocation)
+
+	hub.Publish(&pb.RouteNote{Location: hubLocation, Message: "first"}, nil)
+	hub.Publish(&pb.RouteNote{Location: hubLocation, Message: "second"}, nil)
+	select {
+	case <-slow.Done():
+	case <-time.After(time.Second):
+		t.Fatal("slow subscription not closed")
+	}
+	if err := slow.Err(); err != backend.ErrSlowConsumer {
+		t.Fatalf("Err() = %v, want %v", err, backend.ErrSlowConsumer)
+	}
+}
+
+// TestNoteHubConcurrentPublish publishes from many goroutines while subscriptions come and go.
+// Run it with the race detector: go test -race
+func TestNoteHubConcurrentPublish(t *testing.T) {
+	const publishers, notesPerPublisher = 20, 50
+	hub := backend.NewNoteHub(publishers*notesPerPublisher, backend.DropNote)
+	reader := hub.Subscribe()
+	defer reader.Close()
+	reader.Watch(hubLocation)
+
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sub := hub.Subscribe()
+			defer sub.Close()
+			for j := 0; j < notesPerPublisher; j++ {
+				sub.Watch(hubLocation)
+				hub.Publish(&pb.RouteNote{Location: hubLocation, Message: "note"}, sub)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(hub.Notes(hubLocation)); got != publishers*notesPerPublisher {
+		t.Fatalf("hub stores %d notes, want %d", got, publishers*notesPerPublisher)
+	}
+	if got := len(reader.Notes()); got != publishers*notesPerPublisher {
+		t.Fatalf("reader received %d notes, want %d", got, publishers*notesPerPublisher)
+	}
+}
@@ -0,0 +1,132 @@
+package backend
+
+import (
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+var importedRoute = []*pb.Point{
+	{Latitude: 488625780, Longitude: 22877580},
+	{Latitude: 488583700, Longitude: 22944810},
+}
+
+// TestImportRoute checks that the routes are read from every format
+func TestImportRoute(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"route.gpx", `<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><trkseg>
+    <trkpt lat="48.862578" lon="2.287758"></trkpt>
+    <trkpt lat="48.85837" lon="2.294481"></trkpt>
+  </trkseg></trk>
+</gpx>`},
+		{"route.GPX", `<gpx><rte><rtept lat="48.862578" lon="2.287758"/><rtept lat="48.85837" lon="2.294481"/></rte></gpx>`},
+		{"route.geojson", `{"type": "Feature", "properties": {},
+  "geometry": {"type": "LineString", "coordinates": [[2.287758, 48.862578], [2.294481, 48.85837, 35]]}}`},
+		{"route.json", `{"type": "FeatureCollection", "features": [
+  {"type": "Feature", "geometry": {"type": "Point", "coordinates": [2.287758, 48.862578]}},
+  {"type": "Feature", "geometry": {"type": "Point", "coordinates": [2.294481, 48.85837]}}]}`},
+		{"route.csv", "latitude,longitude\n48.862578,2.287758\n48.85837, 2.294481\n"},
+		{"route.csv", "48.862578,2.287758\n48.85837,2.294481\n"},
+	}
+	for _, test := range tests {
+		points, err := backend.ImportRoute(test.name, []byte(test.data))
+		if err != nil {
+			t.Fatalf("ImportRoute(%s) = %v", test.name, err)
+		}
+		if len(points) != len(importedRoute) {
+			t.Fatalf("ImportRoute(%s) = %v, want %v", test.name, points, importedRoute)
+		}
+		for i := range points {
+			if !proto.Equal(points[i], importedRoute[i]) {
+				t.Fatalf("ImportRoute(%s)[%d] = %v, want %v", test.name, i, points[i], importedRoute[i])
+			}
+		}
+	}
+}
+
+// TestImportRouteTimes checks that the point times are read
+func TestImportRouteTimes(t *testing.T) {
+	start := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	route := &pb.Route{Id: 1, Points: []*pb.Point{
+		{Latitude: 488625780, Longitude: 22877580, Timestamp: timestamppb.New(start)},
+		{Latitude: 488583700, Longitude: 22944810, Timestamp: timestamppb.New(start.Add(time.Minute))},
+	}}
+	gpx, err := backend.ExportGPX(route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geoJSON, err := backend.ExportGeoJSON(route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := "latitude,longitude,time\n48.862578,2.287758,2023-05-01T10:00:00Z\n48.85837,2.294481,2023-05-01T10:01:00Z\n"
+	for name, data := range map[string][]byte{"route.gpx": gpx, "route.geojson": geoJSON, "route.csv": []byte(csv)} {
+		points, err := backend.ImportRoute(name, data)
+		if err != nil {
+			t.Fatalf("ImportRoute(%s) = %v", name, err)
+		}
+		for i := range points {
+			if !proto.Equal(points[i], route.Points[i]) {
+				t.Fatalf("ImportRoute(%s)[%d] = %v, want %v", name, i, points[i], route.Points[i])
+			}
+		}
+	}
+}
+
+// TestImportNotes checks that the notes are read from every format
+func TestImportNotes(t *testing.T) {
+	tests := map[string]string{
+		"notes.gpx": `<gpx><wpt lat="48.85837" lon="2.294481"><name>Eiffel tower !!</name></wpt></gpx>`,
+		"notes.geojson": `{"type": "FeatureCollection", "features": [{"type": "Feature",
+  "geometry": {"type": "Point", "coordinates": [2.294481, 48.85837]}, "properties": {"message": "Eiffel tower !!"}}]}`,
+		"notes.csv": "latitude,longitude,message\n48.85837,2.294481,Eiffel tower !!\n",
+	}
+	want := &pb.RouteNote{Location: &pb.Point{Latitude: 488583700, Longitude: 22944810}, Message: "Eiffel tower !!"}
+	for name, data := range tests {
+		notes, err := backend.ImportNotes(name, []byte(data))
+		if err != nil || len(notes) != 1 || !proto.Equal(notes[0], want) {
+			t.Fatalf("ImportNotes(%s) = %v, %v, want %v", name, notes, err, want)
+		}
+	}
+}
+
+// TestImportInvalid checks that the invalid files are rejected
+func TestImportInvalid(t *testing.T) {
+	tests := map[string]string{
+		"route.txt":     "48.85837,2.294481",
+		"route.gpx":     "<gpx></gpx>",
+		"route.geojson": `{"type": "Polygon", "coordinates": []}`,
+		"route.csv":     "48.85837\n",
+	}
+	for name, data := range tests {
+		if points, err := backend.ImportRoute(name, []byte(data)); err == nil {
+			t.Fatalf("ImportRoute(%s) = %v, want an error", name, points)
+		}
+	}
+	if points, err := backend.ImportRoute("route.csv", []byte("latitude,longitude\n48.8,east\n")); err == nil {
+		t.Fatalf("ImportRoute(invalid longitude) = %v, want an error", points)
+	}
+	if points, err := backend.ImportRoute("route.csv", []byte("91,2.294481\n")); err == nil {
+		t.Fatalf("ImportRoute(latitude out of range) = %v, want an error", points)
+	}
+	for _, gpx := range []string{
+		`<gpx><trk><trkseg><trkpt lat="91" lon="2.294481"/></trkseg></trk></gpx>`,
+		`<gpx><rte><rtept lat="48.85837" lon="180.5"/></rte></gpx>`,
+	} {
+		if points, err := backend.ImportRoute("route.gpx", []byte(gpx)); err == nil {
+			t.Fatalf("ImportRoute(%s) = %v, want an error", gpx, points)
+		}
+	}
+	gpx := `<gpx><wpt lat="-90.1" lon="0"><name>South</name></wpt></gpx>`
+	if notes, err := backend.ImportNotes("notes.gpx", []byte(gpx)); err == nil {
+		t.Fatalf("ImportNotes(%s) = %v, want an error", gpx, notes)
+	}
+}
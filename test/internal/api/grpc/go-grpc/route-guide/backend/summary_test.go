@@ -0,0 +1,131 @@
+package backend
+
+import (
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"math"
+	"testing"
+	"time"
+)
+
+var summaryStart = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// timedPoint returns a point on the equator, reached seconds after summaryStart
+func timedPoint(longitude int32, seconds int) *pb.Point {
+	return &pb.Point{Longitude: longitude, Timestamp: timestamppb.New(summaryStart.Add(time.Duration(seconds) * time.Second))}
+}
+
+// TestPointTimes checks that the client timestamps are only used when every point has one
+func TestPointTimes(t *testing.T) {
+	received := []time.Time{summaryStart.Add(time.Hour), summaryStart.Add(2 * time.Hour)}
+	withTimestamps := []*pb.Point{timedPoint(0, 0), timedPoint(0, 10)}
+	if times := backend.PointTimes(withTimestamps, received); !times[1].Equal(summaryStart.Add(10 * time.Second)) {
+		t.Fatalf("PointTimes() = %v, want the client timestamps", times)
+	}
+	mixed := []*pb.Point{timedPoint(0, 0), {}}
+	if times := backend.PointTimes(mixed, received); !times[0].Equal(received[0]) || !times[1].Equal(received[1]) {
+		t.Fatalf("PointTimes() = %v, want the reception times", times)
+	}
+}
+
+// TestSummarize checks the statistics of a route going east, stopping two minutes, then going on
+func TestSummarize(t *testing.T) {
+	// 0.001 degree of longitude on the equator is about 111 metres
+	points := []*pb.Point{
+		timedPoint(0, 0),
+		timedPoint(10000, 10),   // 111 m in 10 s
+		timedPoint(10010, 70),   // 1 m in 60 s: stop
+		timedPoint(10020, 130),  // 1 m in 60 s: stop
+		timedPoint(30020, 150),  // 222 m in 20 s
+		timedPoint(-10000, 170), // 445 m back west in 20 s
+	}
+	features := []*pb.Feature{{Name: "start", Location: &pb.Point{}}}
+	summary := backend.Summarize(points, backend.PointTimes(points, nil), features)
+
+	if summary.PointCount != 6 || summary.FeatureCount != 1 {
+		t.Fatalf("Summarize() counts %d points and %d features, want 6 and 1", summary.PointCount, summary.FeatureCount)
+	}
+	var distance float64
+	for i := 1; i < len(points); i++ {
+		distance += backend.Distance(points[i-1], points[i])
+	}
+	if math.Abs(summary.DistanceMeters-distance) > 1e-6 || summary.Distance != int32(distance) {
+		t.Fatalf("Summarize() distance = %f (%d), want %f", summary.DistanceMeters, summary.Distance, distance)
+	}
+	if summary.ElapsedSeconds != 170 || summary.ElapsedTime != 170 {
+		t.Fatalf("Summarize() elapsed = %f (%d), want 170", summary.ElapsedSeconds, summary.ElapsedTime)
+	}
+	if want := distance / 170; math.Abs(summary.AverageSpeed-want) > 1e-6 {
+		t.Fatalf("Summarize() average speed = %f, want %f", summary.AverageSpeed, want)
+	}
+	if len(summary.Segments) != 5 {
+		t.Fatalf("Summarize() returned %d segments, want 5", len(summary.Segments))
+	}
+	if fastest := summary.Segments[4]; summary.MaxSpeed != fastest.Speed || math.Abs(fastest.Speed-22.2) > 0.1 {
+		t.Fatalf("Summarize() max speed = %f, want the last segment speed %f (about 22.2)", summary.MaxSpeed, fastest.Speed)
+	}
+	box := summary.BoundingBox
+	if box.Lo.Longitude != -10000 || box.Hi.Longitude != 30020 || box.Lo.Latitude != 0 || box.Hi.Latitude != 0 || box.CrossesAntimeridian {
+		t.Fatalf("Summarize() bounding box = %v, want longitudes from -10000 to 30020", box)
+	}
+	if len(summary.Stops) != 1 {
+		t.Fatalf("Summarize() detected stops %v, want one", summary.Stops)
+	}
+	if stop := summary.Stops[0]; stop.StartIndex != 1 || stop.EndIndex != 3 || stop.DurationSeconds != 120 {
+		t.Fatalf("Summarize() stop = %v, want points 1 to 3 during 120 s", stop)
+	}
+}
+
+// TestCheckTimestamps checks that the timestamps must increase along the route
+func TestCheckTimestamps(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []*pb.Point
+		wantErr bool
+	}{
+		{name: "increasing", points: []*pb.Point{timedPoint(0, 0), timedPoint(1, 10), timedPoint(2, 20)}},
+		{name: "without timestamps", points: []*pb.Point{{}, {}}},
+		{name: "skipped points", points: []*pb.Point{timedPoint(0, 0), {}, timedPoint(2, 20)}},
+		{name: "backwards", points: []*pb.Point{timedPoint(0, 10), timedPoint(1, 0)}, wantErr: true},
+		{name: "equal", points: []*pb.Point{timedPoint(0, 10), timedPoint(1, 10)}, wantErr: true},
+		{name: "backwards after a point without timestamp", points: []*pb.Point{timedPoint(0, 10), {}, timedPoint(1, 5)}, wantErr: true},
+	}
+	for _, test := range tests {
+		if err := backend.CheckTimestamps(test.points); (err != nil) != test.wantErr {
+			t.Errorf("CheckTimestamps(%s) = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+// TestBoundingBoxAntimeridian checks that the bounding box of a route crossing the antimeridian
+// crosses it too, rather than covering the whole globe
+func TestBoundingBoxAntimeridian(t *testing.T) {
+	points := []*pb.Point{
+		timedPoint(1799000000, 0),
+		timedPoint(1800000000, 10),
+		timedPoint(-1799000000, 20),
+		timedPoint(-1798000000, 30),
+	}
+	box := backend.Summarize(points, backend.PointTimes(points, nil), nil).BoundingBox
+	if box.Lo.Longitude != 1799000000 || box.Hi.Longitude != -1798000000 || !box.CrossesAntimeridian {
+		t.Fatalf("Summarize() bounding box = %v, want longitudes from 179.9 east to -179.8, crossing the antimeridian", box)
+	}
+	for _, point := range points {
+		if !geo.Contains(box, point) {
+			t.Fatalf("Summarize() bounding box %v does not contain %v", box, point)
+		}
+	}
+	if geo.Contains(box, &pb.Point{}) {
+		t.Fatalf("Summarize() bounding box %v contains (0, 0), want the narrow rectangle", box)
+	}
+}
+
+// TestSummarizeEmptyRoute checks that a route without points has an empty summary
+func TestSummarizeEmptyRoute(t *testing.T) {
+	summary := backend.Summarize(nil, nil, nil)
+	if summary.PointCount != 0 || summary.BoundingBox != nil || len(summary.Segments) != 0 {
+		t.Fatalf("Summarize(nil) = %v, want an empty summary", summary)
+	}
+}
@@ -0,0 +1,127 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/bench"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/client"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer serves a RouteGuide server in memory and returns a client to it
+func startServer(t *testing.T) *client.Client {
+	t.Helper()
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+	routeGuide, err := server.NewServer(server.Config{}, routes)
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, routeGuide)
+	})
+	return client.New(s.Conn)
+}
+
+// TestRunRequests checks that a run makes the requested number of calls, with the methods of the mix
+func TestRunRequests(t *testing.T) {
+	c := startServer(t)
+	report, err := bench.Run(context.Background(), c, bench.Config{
+		Workers:  4,
+		Requests: 40,
+		Mix:      map[string]int{bench.GetFeature: 1, bench.RecordRoute: 1},
+	})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	total := 0
+	for _, method := range report.Methods {
+		if method.Method != bench.GetFeature && method.Method != bench.RecordRoute {
+			t.Fatalf("Run() called %s, want only the methods of the mix", method.Method)
+		}
+		if method.Latency.Errors != 0 || method.Throughput <= 0 {
+			t.Fatalf("Run() %s = %+v, want no error", method.Method, method)
+		}
+		total += method.Latency.Count
+	}
+	if total != 40 {
+		t.Fatalf("Run() = %d calls, want 40", total)
+	}
+}
+
+// TestRunDuration checks that a run without number of requests ends after its duration, and calls
+// every method of the default mix
+func TestRunDuration(t *testing.T) {
+	c := startServer(t)
+	report, err := bench.Run(context.Background(), c, bench.Config{
+		Workers:  4,
+		Duration: 300 * time.Millisecond,
+		Mix:      bench.DefaultMix,
+	})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if report.Elapsed > 5*time.Second {
+		t.Fatalf("Run() lasted %v, want about 300ms", report.Elapsed)
+	}
+	if len(report.Methods) != len(bench.Methods) {
+		t.Fatalf("Run() = %+v, want the 4 methods", report.Methods)
+	}
+
+	var out bytes.Buffer
+	if err := bench.WriteTable(&out, report); err != nil || !strings.Contains(out.String(), "P99 (ms)") || !strings.Contains(out.String(), "RouteChat") {
+		t.Fatalf("WriteTable() = %q, %v, want a table of the methods", out.String(), err)
+	}
+	out.Reset()
+	var decoded struct {
+		Workers        int
+		ElapsedSeconds float64
+		Methods        []struct {
+			Method  string
+			Latency struct{ Count int }
+		}
+	}
+	if err := bench.WriteJSON(&out, report); err != nil {
+		t.Fatalf("WriteJSON() = %v", err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded.Workers != 4 || len(decoded.Methods) != 4 {
+		t.Fatalf("WriteJSON() = %s, %v, want 4 workers and 4 methods", out.String(), err)
+	}
+}
+
+// TestInvalidConfig checks the settings refused by Run and ParseMix
+func TestInvalidConfig(t *testing.T) {
+	c := startServer(t)
+	configs := []bench.Config{
+		{Workers: 0, Requests: 1, Mix: bench.DefaultMix},
+		{Workers: 1, Mix: bench.DefaultMix},
+		{Workers: 1, Requests: 1, Mix: map[string]int{bench.GetFeature: 0}},
+		{Workers: 1, Requests: 1, Mix: map[string]int{"GetRoute": 1}},
+	}
+	for _, config := range configs {
+		if _, err := bench.Run(context.Background(), c, config); err == nil {
+			t.Fatalf("Run(%+v) = nil, want an error", config)
+		}
+	}
+
+	mix, err := bench.ParseMix("getfeature=3, RouteChat=1, ListFeatures=0")
+	if err != nil || mix[bench.GetFeature] != 3 || mix[bench.RouteChat] != 1 || mix[bench.ListFeatures] != 0 {
+		t.Fatalf("ParseMix() = %v, %v, want GetFeature=3, RouteChat=1 and ListFeatures=0", mix, err)
+	}
+	for _, value := range []string{"GetFeature", "GetRoute=1", "GetFeature=-1", "GetFeature=a"} {
+		if _, err := bench.ParseMix(value); err == nil {
+			t.Fatalf("ParseMix(%q) = nil, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/cli"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// startServer serves a RouteGuide server on a local TCP port and returns the port
+func startServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	config := server.DefaultConfig()
+	config.DatabasePath = store.InMemory
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, config, listener)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// run runs the command line against the server and returns its output
+func run(t *testing.T, port string, args ...string) (string, error) {
+	t.Helper()
+	cmd := cli.NewCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cli.SetArgs(cmd, append([]string{"--host", "127.0.0.1", "--port", port}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// TestFeature checks the outputs of the feature command, on the port given by the flags
+func TestFeature(t *testing.T) {
+	port := startServer(t)
+
+	out, err := run(t, port, "feature", "48.85837,2.294481")
+	if err != nil || !strings.Contains(out, "NAME") || !strings.Contains(out, "Eiffel Tour") {
+		t.Fatalf("feature = %q, %v, want a table with Eiffel Tour", out, err)
+	}
+
+	out, err = run(t, port, "feature", "48.85837,2.294481", "--output", "json")
+	var feature struct{ Name string }
+	if err != nil || json.Unmarshal([]byte(out), &feature) != nil || feature.Name != "Eiffel Tour" {
+		t.Fatalf("feature --output json = %q, %v, want Eiffel Tour", out, err)
+	}
+
+	out, err = run(t, port, "features", "48.860806,2.290437", "48.855989,2.297761", "-o", "yaml")
+	if err != nil || !strings.Contains(out, "- location:") || !strings.Contains(out, "name: Eiffel Tour") {
+		t.Fatalf("features -o yaml = %q, %v, want a list with Eiffel Tour", out, err)
+	}
+}
+
+// TestNegativeCoordinates checks that the negative coordinates are read as points rather than flags,
+// in their order, whatever the place of the flags
+func TestNegativeCoordinates(t *testing.T) {
+	port := startServer(t)
+
+	if out, err := run(t, port, "feature", "-33.86,151.2"); err != nil {
+		t.Fatalf("feature -33.86,151.2 = %q, %v, want no error", out, err)
+	}
+	if out, err := run(t, port, "-o", "json", "feature", "-.5,-151.2"); err != nil || !strings.HasPrefix(out, "{") {
+		t.Fatalf("-o json feature -.5,-151.2 = %q, %v, want a JSON feature", out, err)
+	}
+
+	out, err := run(t, port, "features", "-10,-10", "60,10", "-o", "yaml")
+	if err != nil || !strings.Contains(out, "name: Eiffel Tour") {
+		t.Fatalf("features -10,-10 60,10 = %q, %v, want Eiffel Tour", out, err)
+	}
+	// from 10 degrees east to 10 degrees west, going east: the Eiffel Tour is left out
+	out, err = run(t, port, "features", "--crosses-antimeridian", "60,10", "-10,-10", "-o", "yaml")
+	if err != nil || strings.Contains(out, "Eiffel Tour") {
+		t.Fatalf("features --crosses-antimeridian 60,10 -10,-10 = %q, %v, want no Eiffel Tour", out, err)
+	}
+}
+
+// TestRecord checks that a route file is recorded, then read back
+func TestRecord(t *testing.T) {
+	port := startServer(t)
+	file := filepath.Join(t.TempDir(), "route.csv")
+	if err := os.WriteFile(file, []byte("48.862578,2.287758\n48.85837,2.294481\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run(t, port, "record", file, "-o", "yaml")
+	if err != nil || !strings.Contains(out, "pointCount: 2") || !strings.Contains(out, `routeId: "1"`) {
+		t.Fatalf("record = %q, %v, want route 1 with 2 points", out, err)
+	}
+
+	out, err = run(t, port, "routes", "-o", "json")
+	var page struct{ Routes []json.RawMessage }
+	if err != nil || json.Unmarshal([]byte(out), &page) != nil || len(page.Routes) != 1 {
+		t.Fatalf("routes = %q, %v, want 1 route", out, err)
+	}
+
+	out, err = run(t, port, "export", "1", "--format", "geojson")
+	if err != nil || !strings.Contains(out, `"LineString"`) {
+		t.Fatalf("export = %q, %v, want a GeoJSON LineString", out, err)
+	}
+}
+
+// TestChat checks that the chat prints the notes already posted
+func TestChat(t *testing.T) {
+	port := startServer(t)
+	if out, err := run(t, port, "chat", "48.85837,2.294481", "-m", "Eiffel tower !!"); err != nil {
+		t.Fatalf("chat = %q, %v", out, err)
+	}
+
+	out, err := run(t, port, "chat", "48.85837,2.294481", "-o", "json")
+	var note struct{ Message string }
+	if err != nil || json.Unmarshal([]byte(out), &note) != nil || note.Message != "Eiffel tower !!" {
+		t.Fatalf("chat = %q, %v, want the posted note", out, err)
+	}
+}
+
+// TestHealth checks the health command
+func TestHealth(t *testing.T) {
+	port := startServer(t)
+	out, err := run(t, port, "health", "main.RouteGuide")
+	if err != nil || !strings.Contains(out, "SERVING") {
+		t.Fatalf("health = %q, %v, want SERVING", out, err)
+	}
+	if out, err := run(t, port, "health", "unknown"); err == nil {
+		t.Fatalf("health unknown = %q, want an error", out)
+	}
+}
+
+// TestInvalidArguments checks that the invalid arguments are rejected before calling the server
+func TestInvalidArguments(t *testing.T) {
+	tests := [][]string{
+		{"feature", "48.85837"},
+		{"feature", "91,0"},
+		{"feature", "-91,0"},
+		{"feature", "48.85837,2.294481", "--output", "xml"},
+		{"features", "48.85837,2.294481"},
+		{"record"},
+		{"record", "route.txt"},
+		{"chat"},
+		{"route", "first"},
+		{"export", "1", "--format", "kml"},
+	}
+	for _, args := range tests {
+		// nothing listens on port 1
+		if out, err := run(t, "1", args...); err == nil {
+			t.Fatalf("%v = %q, want an error", args, out)
+		}
+	}
+}
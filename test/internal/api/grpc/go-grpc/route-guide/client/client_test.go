@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/client"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var eiffelTower = &pb.Point{Latitude: 488583700, Longitude: 22944810}
+
+// startServer serves a RouteGuide server on an in-memory listener, with the given interceptor, and
+// returns a client dialed to it
+func startServer(t *testing.T, interceptor grpc.UnaryServerInterceptor) *client.Client {
+	t.Helper()
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+	var opts []grpctest.Option
+	if interceptor != nil {
+		opts = append(opts, grpctest.WithUnaryInterceptors(interceptor))
+	}
+	routeGuide, err := server.NewServer(server.Config{}, routes)
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, routeGuide)
+	}, opts...)
+
+	c, err := client.Dial(grpctest.Target, s.Dialer())
+	if err != nil {
+		t.Fatalf("client.Dial() = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// TestClient checks that every method returns the answer of the server
+func TestClient(t *testing.T) {
+	c := startServer(t, nil)
+	ctx := context.Background()
+
+	feature, err := c.GetFeature(ctx, eiffelTower)
+	if err != nil || feature.Name != "Eiffel Tour" {
+		t.Fatalf("GetFeature(%v) = %v, %v, want Eiffel Tour", eiffelTower, feature, err)
+	}
+
+	rectangle := &pb.Rectangle{
+		Lo: &pb.Point{Latitude: 488608060, Longitude: 22904370},
+		Hi: &pb.Point{Latitude: 488559890, Longitude: 22977610},
+	}
+	features, err := c.ListFeatures(ctx, rectangle)
+	if err != nil || len(features) != 1 {
+		t.Fatalf("ListFeatures(%v) = %v, %v, want 1 feature", rectangle, features, err)
+	}
+
+	points := []*pb.Point{eiffelTower, {Latitude: 488608060, Longitude: 22904370}}
+	summary, err := c.RecordRoute(ctx, points)
+	if err != nil || summary.PointCount != 2 {
+		t.Fatalf("RecordRoute() = %v, %v, want 2 points", summary, err)
+	}
+	route, err := c.GetRoute(ctx, summary.RouteId)
+	if err != nil || len(route.Points) != 2 {
+		t.Fatalf("GetRoute(%d) = %v, %v, want 2 points", summary.RouteId, route, err)
+	}
+	export, err := c.ExportRoute(ctx, summary.RouteId, pb.ExportFormat_GEOJSON)
+	if err != nil || len(export.Data) == 0 {
+		t.Fatalf("ExportRoute(%d) = %v, %v", summary.RouteId, export, err)
+	}
+
+	note := &pb.RouteNote{Location: eiffelTower, Message: "hello"}
+	if err := c.RouteChat(ctx, []*pb.RouteNote{note}, true, func(*pb.RouteNote) {}); err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	var received []*pb.RouteNote
+	watch := &pb.RouteNote{Location: eiffelTower}
+	err = c.RouteChat(ctx, []*pb.RouteNote{watch}, true, func(note *pb.RouteNote) {
+		received = append(received, note)
+	})
+	if err != nil || len(received) != 1 || received[0].Message != "hello" {
+		t.Fatalf("RouteChat() received %v, %v, want the posted note", received, err)
+	}
+}
+
+// TestClientNew checks that a client can use a connection opened by the caller
+func TestClientNew(t *testing.T) {
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, &pb.UnimplementedRouteGuideServer{})
+	})
+	_, err := client.New(s.Conn).GetFeature(context.Background(), eiffelTower)
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("GetFeature() = %v, want Unimplemented", err)
+	}
+}
+
+// TestClientRetry checks that only the idempotent methods are retried when the server is unavailable
+func TestClientRetry(t *testing.T) {
+	var attempts atomic.Int32
+	c := startServer(t, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// fail the first two attempts of each call
+		if attempts.Add(1) <= 2 {
+			return nil, status.Error(codes.Unavailable, "not yet")
+		}
+		return handler(ctx, req)
+	})
+
+	if _, err := c.GetFeature(context.Background(), eiffelTower); err != nil {
+		t.Fatalf("GetFeature() = %v, want retried until success", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("GetFeature() attempts = %d, want 3", got)
+	}
+}
+
+// TestClientNoRetry checks that RecordRoute is not retried, since the server stores the routes
+func TestClientNoRetry(t *testing.T) {
+	var attempts atomic.Int32
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, &pb.UnimplementedRouteGuideServer{})
+	}, grpctest.WithStreamInterceptors(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		attempts.Add(1)
+		return status.Error(codes.Unavailable, "not yet")
+	}))
+	c, err := client.Dial(grpctest.Target, s.Dialer())
+	if err != nil {
+		t.Fatalf("client.Dial() = %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.RecordRoute(context.Background(), []*pb.Point{eiffelTower})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("RecordRoute() = %v, want Unavailable", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("RecordRoute() attempts = %d, want 1", got)
+	}
+}
+
+// TestClientTimeout checks that the calls without deadline get the client timeout
+func TestClientTimeout(t *testing.T) {
+	c := startServer(t, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// never answer
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	c.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := c.GetFeature(context.Background(), eiffelTower)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("GetFeature() = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("GetFeature() returned after %v, want about %v", elapsed, c.Timeout)
+	}
+}
+
+// TestClientRouteChatCancel checks that cancelling the context ends a chat
+func TestClientRouteChatCancel(t *testing.T) {
+	c := startServer(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	note := &pb.RouteNote{Location: eiffelTower, Message: "hello"}
+	if err := c.RouteChat(ctx, []*pb.RouteNote{note}, true, func(*pb.RouteNote) {}); err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+
+	// without closeSend, the chat only ends with the context. Cancel it once the stored note arrives.
+	watch := &pb.RouteNote{Location: eiffelTower}
+	err := c.RouteChat(ctx, []*pb.RouteNote{watch}, false, func(*pb.RouteNote) { cancel() })
+	if status.Code(err) != codes.Canceled {
+		t.Fatalf("RouteChat() = %v, want Canceled", err)
+	}
+}
@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/gateway"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	// do not print the routes and the requests
+	gin.SetMode(gin.TestMode)
+}
+
+// startGateway serves the gateway of a RouteGuide server on a local HTTP server, and returns its
+// URL
+func startGateway(t *testing.T) string {
+	t.Helper()
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+	routeGuide, err := server.NewServer(server.Config{}, routes)
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, routeGuide)
+	})
+
+	httpServer := httptest.NewServer(gateway.NewRouter(context.Background(), s.Conn))
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+// call sends a request to the gateway, checks its status code and decodes its JSON body
+func call(t *testing.T, method string, url string, body string, wantCode int, response interface{}) {
+	t.Helper()
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s = %v", method, url, err)
+	}
+	defer reply.Body.Close()
+	data, _ := io.ReadAll(reply.Body)
+	if reply.StatusCode != wantCode {
+		t.Fatalf("%s %s = %d %s, want %d", method, url, reply.StatusCode, data, wantCode)
+	}
+	if response != nil {
+		if err := json.Unmarshal(data, response); err != nil {
+			t.Fatalf("%s %s body = %s: %v", method, url, data, err)
+		}
+	}
+}
+
+// TestFeatures checks the features resources
+func TestFeatures(t *testing.T) {
+	url := startGateway(t)
+
+	var feature struct {
+		Name     string
+		Location struct{ Latitude, Longitude int32 }
+	}
+	call(t, http.MethodGet, url+"/v1/features?lat=48.85837&lng=2.294481", "", http.StatusOK, &feature)
+	if feature.Name != "Eiffel Tour" || feature.Location.Latitude != 488583700 {
+		t.Fatalf("GET /v1/features = %+v, want Eiffel Tour", feature)
+	}
+
+	var list struct{ Features []json.RawMessage }
+	call(t, http.MethodGet, url+"/v1/features:list?lo.lat=48.860806&lo.lng=2.290437&hi.lat=48.855989&hi.lng=2.297761", "", http.StatusOK, &list)
+	if len(list.Features) != 1 {
+		t.Fatalf("GET /v1/features:list = %s, want 1 feature", list.Features)
+	}
+	call(t, http.MethodGet, url+"/v1/features:list?lo.lat=0&lo.lng=0&hi.lat=1&hi.lng=1", "", http.StatusOK, &list)
+	if list.Features == nil || len(list.Features) != 0 {
+		t.Fatalf("GET /v1/features:list = %s, want an empty list", list.Features)
+	}
+	call(t, http.MethodGet, url+"/v1/features:list?lo.lat=40&lo.lng=170&hi.lat=50&hi.lng=5&crossesAntimeridian=true", "", http.StatusOK, &list)
+	if len(list.Features) != 1 {
+		t.Fatalf("GET /v1/features:list across the antimeridian = %s, want 1 feature", list.Features)
+	}
+}
+
+// TestErrors checks that the errors are returned with the HTTP status of their gRPC code
+func TestErrors(t *testing.T) {
+	url := startGateway(t)
+	var body struct {
+		Code    codes.Code
+		Message string
+	}
+	call(t, http.MethodGet, url+"/v1/features?lat=91&lng=0", "", http.StatusBadRequest, &body)
+	if body.Code != codes.InvalidArgument || body.Message == "" {
+		t.Fatalf("GET /v1/features?lat=91 = %+v, want InvalidArgument", body)
+	}
+	call(t, http.MethodGet, url+"/v1/features?lat=48", "", http.StatusBadRequest, nil)
+	call(t, http.MethodGet, url+"/v1/features:list?lo.lat=0&lo.lng=0&hi.lat=1&hi.lng=1&crossesAntimeridian=yes", "", http.StatusBadRequest, nil)
+	call(t, http.MethodGet, url+"/v1/albums", "", http.StatusNotFound, nil)
+	call(t, http.MethodPost, url+"/v1/routes", `{"latitude": 1}`, http.StatusBadRequest, nil)
+	call(t, http.MethodPost, url+"/v1/routes", `[{"lat": 1}]`, http.StatusBadRequest, nil)
+	call(t, http.MethodPost, url+"/v1/notes", `{"message": "nowhere"}`, http.StatusBadRequest, &body)
+	if body.Code != codes.InvalidArgument {
+		t.Fatalf("POST /v1/notes without location = %+v, want InvalidArgument", body)
+	}
+}
+
+// TestRoutes checks that a route is recorded
+func TestRoutes(t *testing.T) {
+	url := startGateway(t)
+	var summary struct {
+		PointCount int32
+		RouteId    string
+	}
+	points := `[{"latitude": 488625780, "longitude": 22877580}, {"latitude": 488583700, "longitude": 22944810, "timestamp": "2023-05-01T10:00:00Z"}]`
+	call(t, http.MethodPost, url+"/v1/routes", points, http.StatusCreated, &summary)
+	if summary.PointCount != 2 || summary.RouteId != "1" {
+		t.Fatalf("POST /v1/routes = %+v, want route 1 with 2 points", summary)
+	}
+}
+
+// TestNotes checks that the notes posted by a client are received by the server-sent events of
+// another one
+func TestNotes(t *testing.T) {
+	url := startGateway(t)
+	note := `{"location": {"latitude": 488583700, "longitude": 22944810}, "message": "first"}`
+	var notes struct{ Notes []json.RawMessage }
+	call(t, http.MethodPost, url+"/v1/notes", note, http.StatusOK, &notes)
+	if len(notes.Notes) != 0 {
+		t.Fatalf("POST /v1/notes = %s, want no stored note", notes.Notes)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/v1/notes:watch?lat=48.85837&lng=2.294481", nil)
+	events, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET /v1/notes:watch = %v", err)
+	}
+	defer events.Body.Close()
+	if events.StatusCode != http.StatusOK || events.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /v1/notes:watch = %d %s, want an event stream", events.StatusCode, events.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(events.Body)
+	// readNote returns the message of the next note event
+	readNote := func() string {
+		t.Helper()
+		var event, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading the events = %v", err)
+			}
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				data = strings.TrimPrefix(line, "data:")
+			case line == "" && event != "":
+				if event != "note" {
+					t.Fatalf("event %s: %s, want a note", event, data)
+				}
+				var received struct{ Message string }
+				if err := json.Unmarshal([]byte(data), &received); err != nil {
+					t.Fatalf("note event %s: %v", data, err)
+				}
+				return received.Message
+			}
+		}
+	}
+	if message := readNote(); message != "first" {
+		t.Fatalf("first event = %q, want the stored note", message)
+	}
+
+	note = `{"location": {"latitude": 488583700, "longitude": 22944810}, "message": "second"}`
+	call(t, http.MethodPost, url+"/v1/notes", note, http.StatusOK, &notes)
+	if len(notes.Notes) != 1 {
+		t.Fatalf("POST /v1/notes = %s, want the first note", notes.Notes)
+	}
+	if message := readNote(); message != "second" {
+		t.Fatalf("second event = %q, want the posted note", message)
+	}
+}
+
+// TestHTTPStatus checks a few translations of the gRPC codes
+func TestHTTPStatus(t *testing.T) {
+	tests := map[codes.Code]int{
+		codes.OK:                http.StatusOK,
+		codes.InvalidArgument:   http.StatusBadRequest,
+		codes.NotFound:          http.StatusNotFound,
+		codes.Unauthenticated:   http.StatusUnauthorized,
+		codes.PermissionDenied:  http.StatusForbidden,
+		codes.ResourceExhausted: http.StatusTooManyRequests,
+		codes.Unavailable:       http.StatusServiceUnavailable,
+		codes.Internal:          http.StatusInternalServerError,
+	}
+	for code, want := range tests {
+		if got := gateway.HTTPStatus(code); got != want {
+			t.Fatalf("HTTPStatus(%v) = %d, want %d", code, got, want)
+		}
+	}
+}
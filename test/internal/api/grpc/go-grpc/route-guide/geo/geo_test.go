@@ -0,0 +1,302 @@
+package geo
+
+import (
+	"errors"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/geo"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// The property tests draw their values with testing/quick. The points are drawn by RandomPoint, so a
+// failing property also tells a bug in RandomPoint.
+
+// point is a valid point generated by testing/quick
+type point struct {
+	*pb.Point
+}
+
+func (point) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(point{geo.RandomPoint(r)})
+}
+
+// degrees is a pair of coordinates, up to a few degrees out of range
+type degrees struct {
+	Latitude, Longitude float64
+}
+
+func (degrees) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(degrees{Latitude: r.Float64()*200 - 100, Longitude: r.Float64()*380 - 190})
+}
+
+// check runs quick.Check with more values than the default
+func check(t *testing.T, property interface{}) {
+	t.Helper()
+	if err := quick.Check(property, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestE7RoundTrip checks that the conversion of an E7 coordinate to degrees is exact
+func TestE7RoundTrip(t *testing.T) {
+	check(t, func(coordinate int32) bool {
+		return geo.ToE7(geo.FromE7(coordinate)) == coordinate
+	})
+}
+
+// TestNewPoint checks that NewPoint only accepts the valid coordinates, and rounds them to the nearest
+// E7 coordinate
+func TestNewPoint(t *testing.T) {
+	check(t, func(d degrees) bool {
+		p, err := geo.NewPoint(d.Latitude, d.Longitude)
+		valid := math.Abs(d.Latitude) <= 90 && math.Abs(d.Longitude) <= 180
+		if !valid {
+			var rangeErr *geo.RangeError
+			return p == nil && errors.As(err, &rangeErr)
+		}
+		return err == nil && geo.Validate(p) == nil &&
+			math.Abs(geo.FromE7(p.Latitude)-d.Latitude) <= 0.5e-7 &&
+			math.Abs(geo.FromE7(p.Longitude)-d.Longitude) <= 0.5e-7
+	})
+
+	tests := []struct {
+		latitude, longitude float64
+		valid               bool
+	}{
+		{90, 180, true},
+		{-90, -180, true},
+		{90.0000001, 0, false},
+		{0, -180.0000001, false},
+		{math.NaN(), 0, false},
+		{0, math.NaN(), false},
+		{math.Inf(1), 0, false},
+		{0, math.Inf(-1), false},
+	}
+	for _, test := range tests {
+		if _, err := geo.NewPoint(test.latitude, test.longitude); (err == nil) != test.valid {
+			t.Fatalf("NewPoint(%v, %v) = %v, want valid %v", test.latitude, test.longitude, err, test.valid)
+		}
+	}
+}
+
+// TestValidate checks the range of the E7 coordinates
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		point *pb.Point
+		valid bool
+	}{
+		{nil, true},
+		{&pb.Point{Latitude: 900000000, Longitude: -1800000000}, true},
+		{&pb.Point{Latitude: 900000001}, false},
+		{&pb.Point{Longitude: 1800000001}, false},
+	}
+	for _, test := range tests {
+		if err := geo.Validate(test.point); (err == nil) != test.valid {
+			t.Fatalf("Validate(%v) = %v, want valid %v", test.point, err, test.valid)
+		}
+	}
+	rectangle := &pb.Rectangle{Lo: &pb.Point{}, Hi: &pb.Point{Latitude: -900000001}}
+	if err := geo.ValidateRectangle(rectangle); err == nil {
+		t.Fatalf("ValidateRectangle(%v) = nil, want an error", rectangle)
+	}
+}
+
+// TestRandomPoint checks that the random points are valid
+func TestRandomPoint(t *testing.T) {
+	check(t, func(seed int64) bool {
+		return geo.Validate(geo.RandomPoint(rand.New(rand.NewSource(seed)))) == nil
+	})
+}
+
+// TestNormalizeLongitude checks that the longitudes are wrapped into [-180, 180) without moving the
+// meridian
+func TestNormalizeLongitude(t *testing.T) {
+	check(t, func(longitude float64, turns int8) bool {
+		longitude = math.Mod(longitude, 180)
+		normalized := geo.NormalizeLongitude(longitude + float64(turns)*360)
+		return normalized >= -180 && normalized < 180 && math.Abs(normalized-geo.NormalizeLongitude(longitude)) < 1e-9
+	})
+	if got := geo.NormalizeLongitude(180); got != -180 {
+		t.Fatalf("NormalizeLongitude(180) = %v, want -180", got)
+	}
+}
+
+// TestContains checks the rectangles with their corners in any order, and the ones set to cross the
+// antimeridian
+func TestContains(t *testing.T) {
+	// the Fiji islands rectangle crosses the antimeridian
+	fiji := &pb.Rectangle{
+		Lo:                  &pb.Point{Latitude: -150000000, Longitude: 1770000000},
+		Hi:                  &pb.Point{Latitude: -200000000, Longitude: -1780000000},
+		CrossesAntimeridian: true,
+	}
+	paris := &pb.Rectangle{
+		Lo: &pb.Point{Latitude: 488608060, Longitude: 22904370},
+		Hi: &pb.Point{Latitude: 488559890, Longitude: 22977610},
+	}
+	world := &pb.Rectangle{
+		Lo: &pb.Point{Latitude: -900000000, Longitude: -1800000000},
+		Hi: &pb.Point{Latitude: 900000000, Longitude: 1800000000},
+	}
+	east := &pb.Rectangle{Lo: &pb.Point{Longitude: 1700000000}, Hi: &pb.Point{Latitude: 100000000, Longitude: 1800000000}}
+	tests := []struct {
+		name      string
+		rectangle *pb.Rectangle
+		point     *pb.Point
+		want      bool
+	}{
+		{"inside", paris, &pb.Point{Latitude: 488583700, Longitude: 22944810}, true},
+		{"corner", paris, paris.Lo, true},
+		{"north", paris, &pb.Point{Latitude: 488608061, Longitude: 22944810}, false},
+		{"west", paris, &pb.Point{Latitude: 488583700, Longitude: 22904369}, false},
+		{"across the antimeridian, east side", fiji, &pb.Point{Latitude: -178000000, Longitude: 1784000000}, true},
+		{"across the antimeridian, west side", fiji, &pb.Point{Latitude: -178000000, Longitude: -1790000000}, true},
+		{"across the antimeridian, on it", fiji, &pb.Point{Latitude: -178000000, Longitude: 1800000000}, true},
+		{"across the antimeridian, outside", fiji, &pb.Point{Latitude: -178000000, Longitude: 0}, false},
+		{"across the antimeridian, south", fiji, &pb.Point{Latitude: -210000000, Longitude: 1790000000}, false},
+		{"not across the antimeridian", &pb.Rectangle{Lo: fiji.Lo, Hi: fiji.Hi}, &pb.Point{Latitude: -178000000, Longitude: 0}, true},
+		{"whole world", world, &pb.Point{Latitude: 123456789, Longitude: -987654321}, true},
+		{"antimeridian as -180", east, &pb.Point{Latitude: 50000000, Longitude: -1800000000}, true},
+		{"nil rectangle", nil, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := geo.Contains(test.rectangle, test.point); got != test.want {
+				t.Fatalf("Contains(%v, %v) = %v, want %v", test.rectangle, test.point, got, test.want)
+			}
+		})
+	}
+}
+
+// TestContainsSwappedCorners checks that swapping the corners gives the same rectangle, unless it is
+// set to cross the antimeridian: swapping the longitudes gives the rest of the latitude band then
+func TestContainsSwappedCorners(t *testing.T) {
+	paris := &pb.Rectangle{
+		Lo: &pb.Point{Latitude: 488559890, Longitude: 22904370},
+		Hi: &pb.Point{Latitude: 488608060, Longitude: 22977610},
+	}
+	swappedLatitudes := &pb.Rectangle{
+		Lo: &pb.Point{Latitude: paris.Hi.Latitude, Longitude: paris.Lo.Longitude},
+		Hi: &pb.Point{Latitude: paris.Lo.Latitude, Longitude: paris.Hi.Longitude},
+	}
+	swappedLongitudes := &pb.Rectangle{
+		Lo: &pb.Point{Latitude: paris.Lo.Latitude, Longitude: paris.Hi.Longitude},
+		Hi: &pb.Point{Latitude: paris.Hi.Latitude, Longitude: paris.Lo.Longitude},
+	}
+	across := &pb.Rectangle{Lo: swappedLongitudes.Lo, Hi: swappedLongitudes.Hi, CrossesAntimeridian: true}
+	points := []*pb.Point{
+		{Latitude: 488583700, Longitude: 22944810},
+		{Latitude: 488583700, Longitude: -1000000000},
+		{Latitude: 488583700, Longitude: 1800000000},
+		{Latitude: 488608060, Longitude: 22904370},
+		{Latitude: 488608061, Longitude: 22944810},
+		{Latitude: 488583700, Longitude: 22904369},
+	}
+	for _, point := range points {
+		want := geo.Contains(paris, point)
+		for _, rectangle := range []*pb.Rectangle{swappedLatitudes, swappedLongitudes} {
+			if got := geo.Contains(rectangle, point); got != want {
+				t.Fatalf("Contains(%v, %v) = %v, want %v", rectangle, point, got, want)
+			}
+		}
+		// the borders belong to both sides of the meridians, and the points out of the latitudes to none
+		onBorder := point.Longitude == paris.Lo.Longitude || point.Longitude == paris.Hi.Longitude
+		inLatitude := point.Latitude >= paris.Lo.Latitude && point.Latitude <= paris.Hi.Latitude
+		want = inLatitude && (onBorder || !want)
+		if got := geo.Contains(across, point); got != want {
+			t.Fatalf("Contains(%v, %v) = %v, want %v", across, point, got, want)
+		}
+	}
+}
+
+// TestContainsAcrossAntimeridian checks that a rectangle crossing the antimeridian holds the points
+// which are not strictly inside the rectangle between the same meridians, going the other way
+func TestContainsAcrossAntimeridian(t *testing.T) {
+	check(t, func(p point, c1 point, c2 point) bool {
+		west, east := c1.Longitude, c2.Longitude
+		if west <= east {
+			west, east = east, west
+		}
+		if west == east {
+			return true
+		}
+		across := &pb.Rectangle{
+			Lo:                  &pb.Point{Latitude: c1.Latitude, Longitude: west},
+			Hi:                  &pb.Point{Latitude: c2.Latitude, Longitude: east},
+			CrossesAntimeridian: true,
+		}
+		other := &pb.Rectangle{
+			Lo: &pb.Point{Latitude: c1.Latitude, Longitude: east},
+			Hi: &pb.Point{Latitude: c2.Latitude, Longitude: west},
+		}
+		onBorder := p.Longitude == west || p.Longitude == east
+		inLatitude := geo.Contains(other, &pb.Point{Latitude: p.Latitude, Longitude: east})
+		return geo.Contains(across, p.Point) == (inLatitude && (onBorder || !geo.Contains(other, p.Point)))
+	})
+}
+
+// TestBearing checks the bearings along the meridians and the equator
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to *pb.Point
+		want     float64
+	}{
+		{"north", &pb.Point{}, &pb.Point{Latitude: 10000000}, 0},
+		{"east", &pb.Point{}, &pb.Point{Longitude: 10000000}, 90},
+		{"south", &pb.Point{}, &pb.Point{Latitude: -10000000}, 180},
+		{"west", &pb.Point{}, &pb.Point{Longitude: -10000000}, 270},
+		{"east across the antimeridian", &pb.Point{Longitude: 1790000000}, &pb.Point{Longitude: -1790000000}, 90},
+		{"same point", &pb.Point{Latitude: 488583700, Longitude: 22944810}, &pb.Point{Latitude: 488583700, Longitude: 22944810}, 0},
+		// the initial bearing of the great circle is north of the parallel
+		{"Paris to London", &pb.Point{Latitude: 488566000, Longitude: 23522000}, &pb.Point{Latitude: 515074000, Longitude: -1278000}, 330.0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := geo.Bearing(test.from, test.to); math.Abs(got-test.want) > 0.1 {
+				t.Fatalf("Bearing(%v, %v) = %v, want %v", test.from, test.to, got, test.want)
+			}
+		})
+	}
+
+	check(t, func(from point, to point) bool {
+		bearing := geo.Bearing(from.Point, to.Point)
+		return bearing >= 0 && bearing < 360
+	})
+}
+
+// TestDestination checks that travelling the distance from a point, with the bearing to another one,
+// reaches the other point
+func TestDestination(t *testing.T) {
+	halfCircumference := math.Pi * geo.EarthRadius
+	check(t, func(from point, to point) bool {
+		distance := geo.Distance(from.Point, to.Point)
+		// the bearing is not defined from the poles, and barely near the antipodal point
+		if math.Abs(geo.FromE7(from.Latitude)) > 89 || distance > 0.99*halfCircumference {
+			return true
+		}
+		destination := geo.Destination(from.Point, geo.Bearing(from.Point, to.Point), distance)
+		return geo.Validate(destination) == nil && geo.Distance(destination, to.Point) < 1
+	})
+
+	// 1 degree of longitude at the equator, east across the antimeridian
+	from := &pb.Point{Longitude: 1795000000}
+	destination := geo.Destination(from, 90, math.Pi*geo.EarthRadius/180)
+	if want := (&pb.Point{Longitude: -1795000000}); geo.Distance(destination, want) > 0.01 {
+		t.Fatalf("Destination(%v, 90, 1°) = %v, want %v", from, destination, want)
+	}
+}
+
+// TestDestinationDistance checks that the destination is at the travelled distance, whatever the
+// bearing
+func TestDestinationDistance(t *testing.T) {
+	check(t, func(from point, bearing float64, distance uint32) bool {
+		bearing = math.Mod(bearing, 360)
+		meters := float64(distance % 10000000) // up to 10000 km, less than half of the circumference
+		destination := geo.Destination(from.Point, bearing, meters)
+		return geo.Validate(destination) == nil && math.Abs(geo.Distance(from.Point, destination)-meters) < 1
+	})
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"flag"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadDefaultConfig checks that the configuration defaults to DefaultConfig
+func TestLoadDefaultConfig(t *testing.T) {
+	config, err := server.LoadConfig("", nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	defaults := server.DefaultConfig()
+	if config.Port != defaults.Port || config.Keepalive != defaults.Keepalive || config.Limits != defaults.Limits ||
+		config.ShutdownTimeout != defaults.ShutdownTimeout || config.Chat != defaults.Chat {
+		t.Fatalf("LoadConfig() = %+v, want %+v", config, defaults)
+	}
+}
+
+// TestLoadConfigPrecedence checks that the flags override the environment, which overrides the file
+func TestLoadConfigPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.yaml")
+	err := os.WriteFile(file, []byte(`
+port: 9000
+host: 0.0.0.0
+chat:
+  bufferSize: 8
+  slowConsumer: disconnect
+keepalive:
+  minTime: 1m
+shutdownTimeout: 3s
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ROUTE_GUIDE_PORT", "9001")
+	t.Setenv("ROUTE_GUIDE_LIMITS_MAXCONCURRENTSTREAMS", "7")
+	t.Setenv("ROUTE_GUIDE_HOST", "127.0.0.1")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	server.RegisterFlags(flags)
+	if err := flags.Parse([]string{"-port", "9002", "-reflection"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := server.LoadConfig(file, flags)
+	if err != nil {
+		t.Fatalf("LoadConfig() = %v", err)
+	}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"flag over environment and file", config.Port, 9002},
+		{"flag only", config.Reflection, true},
+		{"environment over file", config.Host, "127.0.0.1"},
+		{"environment only", config.Limits.MaxConcurrentStreams, uint32(7)},
+		{"file", config.Chat.BufferSize, 8},
+		{"file policy name", config.Chat.SlowConsumer, backend.Disconnect},
+		{"file duration", config.Keepalive.MinTime, time.Minute},
+		{"file over default", config.ShutdownTimeout, 3 * time.Second},
+		{"default", config.Limits.MaxRecvMsgSize, server.DefaultConfig().Limits.MaxRecvMsgSize},
+		{"flag default does not override the file", config.Chat.BufferSize, 8},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+// TestLoadInvalidConfig checks that the configuration errors are returned
+func TestLoadInvalidConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.yaml")
+	if err := os.WriteFile(file, []byte("chat:\n  slowConsumer: explode\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.LoadConfig(file, nil); err == nil {
+		t.Fatal("LoadConfig() accepted an unknown slow consumer policy")
+	}
+	if _, err := server.LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"), nil); err == nil {
+		t.Fatal("LoadConfig() accepted a missing file")
+	}
+}
+
+// TestLoadIncompleteTLSConfig checks that a partial set of TLS files fails rather than serving
+// plaintext
+func TestLoadIncompleteTLSConfig(t *testing.T) {
+	tests := map[string]string{
+		"client CA only":       "tls:\n  clientCAFile: ca.pem\n",
+		"key only":             "tls:\n  keyFile: server.key\n",
+		"cert only":            "tls:\n  certFile: server.pem\n",
+		"key and client CA":    "tls:\n  keyFile: server.key\n  clientCAFile: ca.pem\n",
+		"cert without the key": "tls:\n  certFile: server.pem\n  clientCAFile: ca.pem\n",
+	}
+	for name, content := range tests {
+		file := filepath.Join(t.TempDir(), "server.yaml")
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if config, err := server.LoadConfig(file, nil); err == nil {
+			t.Errorf("LoadConfig(%s) = %+v, want an error", name, config.TLS)
+		}
+	}
+}
+
+// TestLoadConfigWithoutAuthenticator checks that an authentication file without tokens nor JWT key
+// fails rather than starting a server accepting every call
+func TestLoadConfigWithoutAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	authFile := filepath.Join(dir, "auth.yaml")
+	if err := os.WriteFile(authFile, []byte("rules:\n  public: [/grpc.health.v1.Health/Check]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "server.yaml")
+	if err := os.WriteFile(file, []byte("authFile: "+authFile+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := server.LoadConfig(file, nil); err == nil {
+		t.Fatalf("LoadConfig() = %+v, want an error", config.Auth)
+	}
+}
+
+// TestLoadConfigInlineAuth checks that the authentication must be in the authFile: viper would split
+// the method names of the rules at their dots
+func TestLoadConfigInlineAuth(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.yaml")
+	content := "auth:\n  tokens:\n    - token: s3cr3t\n  rules:\n    methods:\n      /main.RouteGuide/RecordRoute: [writer]\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := server.LoadConfig(file, nil); err == nil {
+		t.Fatalf("LoadConfig(inline auth) = %+v, want an error asking for authFile", config.Auth)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitStatus watches the health of the service until it has the wanted status
+func waitStatus(t *testing.T, stream healthpb.Health_WatchClient, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Watch() = %v, want %v", err, want)
+		}
+		if response.Status == want {
+			return
+		}
+	}
+}
+
+// TestHealthServing checks that the server is serving once the features file is loaded, and is not
+// serving anymore once it stops
+func TestHealthServing(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "features.json")
+	features := `[{"name": "Louvre", "location": {"latitude": 48860611, "longitude": 2337644}}]`
+	if err := os.WriteFile(file, []byte(features), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := server.DefaultConfig()
+	config.FeaturesFile = file
+	config.ShutdownTimeout = 100 * time.Millisecond
+	connection, stop, served := serve(t, config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := healthpb.NewHealthClient(connection).Watch(ctx, &healthpb.HealthCheckRequest{Service: "main.RouteGuide"})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	waitStatus(t, stream, healthpb.HealthCheckResponse_SERVING)
+
+	point := &pb.Point{Latitude: 48860611, Longitude: 2337644}
+	feature, err := pb.NewRouteGuideClient(connection).GetFeature(ctx, point)
+	if err != nil || feature.Name != "Louvre" {
+		t.Fatalf("GetFeature(%v) = %v, %v, want Louvre", point, feature, err)
+	}
+
+	stop()
+	waitStatus(t, stream, healthpb.HealthCheckResponse_NOT_SERVING)
+	waitServed(t, served, 5*time.Second)
+}
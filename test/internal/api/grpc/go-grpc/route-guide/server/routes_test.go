@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+// recordRoute records the backend's saved route and returns its summary
+func recordRoute(t *testing.T, client pb.RouteGuideClient) *pb.RouteSummary {
+	t.Helper()
+	stream, err := client.RecordRoute(context.Background())
+	if err != nil {
+		t.Fatalf("RecordRoute() = %v", err)
+	}
+	for _, point := range backend.SavedRoute {
+		if err := stream.Send(point); err != nil {
+			t.Fatalf("Send(%v) = %v", point, err)
+		}
+	}
+	summary, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv() = %v", err)
+	}
+	return summary
+}
+
+// TestGetRecordedRoute checks that a recorded route can be read back with its points
+func TestGetRecordedRoute(t *testing.T) {
+	client := startServer(t, server.Config{})
+	summary := recordRoute(t, client)
+
+	route, err := client.GetRoute(context.Background(), &pb.GetRouteRequest{Id: summary.RouteId})
+	if err != nil {
+		t.Fatalf("GetRoute(%d) = %v", summary.RouteId, err)
+	}
+	if len(route.Points) != len(backend.SavedRoute) || route.Summary.Distance != summary.Distance {
+		t.Fatalf("GetRoute(%d) = %v, want the recorded route", summary.RouteId, route)
+	}
+
+	_, err = client.GetRoute(context.Background(), &pb.GetRouteRequest{Id: summary.RouteId + 1})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("GetRoute(unknown) = %v, want NotFound", err)
+	}
+}
+
+// TestListRoutesPages checks that the routes are listed page by page
+func TestListRoutesPages(t *testing.T) {
+	client := startServer(t, server.Config{})
+	for i := 0; i < 3; i++ {
+		recordRoute(t, client)
+	}
+
+	var pages [][]uint64
+	request := &pb.ListRoutesRequest{PageSize: 2}
+	for {
+		page, err := client.ListRoutes(context.Background(), request)
+		if err != nil {
+			t.Fatalf("ListRoutes(%v) = %v", request, err)
+		}
+		var ids []uint64
+		for _, route := range page.Routes {
+			ids = append(ids, route.Id)
+		}
+		pages = append(pages, ids)
+		if page.NextPageToken == "" {
+			break
+		}
+		request.PageToken = page.NextPageToken
+	}
+	if len(pages) != 2 || len(pages[0]) != 2 || len(pages[1]) != 1 || pages[1][0] != 3 {
+		t.Fatalf("ListRoutes() pages = %v, want [[1 2] [3]]", pages)
+	}
+
+	_, err := client.ListRoutes(context.Background(), &pb.ListRoutesRequest{PageToken: "nope"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("ListRoutes(invalid token) = %v, want InvalidArgument", err)
+	}
+}
+
+// TestExportRoute checks that the exported files can be decoded and hold every point
+func TestExportRoute(t *testing.T) {
+	client := startServer(t, server.Config{})
+	summary := recordRoute(t, client)
+
+	tests := []struct {
+		format      pb.ExportFormat
+		contentType string
+		countPoints func(data []byte) (int, error)
+	}{
+		{pb.ExportFormat_GPX, backend.GPXContentType, func(data []byte) (int, error) {
+			var file struct {
+				Points []struct{} `xml:"trk>trkseg>trkpt"`
+			}
+			err := xml.Unmarshal(data, &file)
+			return len(file.Points), err
+		}},
+		{pb.ExportFormat_GEOJSON, backend.GeoJSONContentType, func(data []byte) (int, error) {
+			var feature struct {
+				Geometry struct {
+					Coordinates [][]float64 `json:"coordinates"`
+				} `json:"geometry"`
+			}
+			err := json.Unmarshal(data, &feature)
+			return len(feature.Geometry.Coordinates), err
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.format.String(), func(t *testing.T) {
+			exported, err := client.ExportRoute(context.Background(),
+				&pb.ExportRouteRequest{Id: summary.RouteId, Format: test.format})
+			if err != nil {
+				t.Fatalf("ExportRoute() = %v", err)
+			}
+			if exported.ContentType != test.contentType {
+				t.Fatalf("ExportRoute() content type = %q, want %q", exported.ContentType, test.contentType)
+			}
+			count, err := test.countPoints(exported.Data)
+			if err != nil || count != len(backend.SavedRoute) {
+				t.Fatalf("exported file holds %d points (%v), want %d", count, err, len(backend.SavedRoute))
+			}
+		})
+	}
+}
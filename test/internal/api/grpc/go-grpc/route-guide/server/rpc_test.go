@@ -0,0 +1,335 @@
+package server
+
+import (
+	"context"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/backend"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"testing"
+	"time"
+)
+
+// The tests of this file cover the four kinds of rpc methods of RouteGuide, with their error paths:
+// GetFeature (unary), ListFeatures (server streaming), RecordRoute (client streaming) and RouteChat
+// (bidirectional streaming).
+
+var (
+	eiffelTower = &pb.Point{Latitude: 488583700, Longitude: 22944810}
+	nowhere     = &pb.Point{Latitude: 10, Longitude: 10}
+)
+
+// canceled returns a context which is already cancelled
+func canceled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// reject is a stream interceptor refusing every call with the given code
+func reject(code codes.Code) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return status.Error(code, "rejected by the interceptor")
+	}
+}
+
+// TestGetFeatureTable checks the unary method
+func TestGetFeatureTable(t *testing.T) {
+	client := startServer(t, server.Config{}, grpctest.WithUnaryInterceptors(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			// the origin point stands for a failing call
+			if point := req.(*pb.Point); point.Latitude == 0 && point.Longitude == 0 {
+				return nil, status.Error(codes.Internal, "failed")
+			}
+			return handler(ctx, req)
+		}))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		point    *pb.Point
+		wantName string
+		wantCode codes.Code
+	}{
+		{"feature", context.Background(), eiffelTower, "Eiffel Tour", codes.OK},
+		{"no feature", context.Background(), nowhere, "Unknown", codes.OK},
+		{"server error", context.Background(), &pb.Point{}, "", codes.Internal},
+		{"invalid point", context.Background(), &pb.Point{Latitude: 910000000}, "", codes.InvalidArgument},
+		{"cancelled", canceled(), eiffelTower, "", codes.Canceled},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feature, err := client.GetFeature(test.ctx, test.point)
+			if status.Code(err) != test.wantCode {
+				t.Fatalf("GetFeature(%v) = %v, want %v", test.point, err, test.wantCode)
+			}
+			if feature.GetName() != test.wantName {
+				t.Fatalf("GetFeature(%v) = %q, want %q", test.point, feature.GetName(), test.wantName)
+			}
+		})
+	}
+}
+
+// TestListFeaturesTable checks the server streaming method
+func TestListFeaturesTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []grpctest.Option
+		rectangle *pb.Rectangle
+		want      int
+		wantCode  codes.Code
+	}{
+		{
+			name: "around a feature",
+			rectangle: &pb.Rectangle{
+				Lo: &pb.Point{Latitude: 488608060, Longitude: 22904370},
+				Hi: &pb.Point{Latitude: 488559890, Longitude: 22977610},
+			},
+			want: 1,
+		},
+		{
+			name:      "whole world",
+			rectangle: &pb.Rectangle{Lo: &pb.Point{Latitude: -900000000, Longitude: -1800000000}, Hi: &pb.Point{Latitude: 900000000, Longitude: 1800000000}},
+			want:      len(backend.Features),
+		},
+		{
+			name: "across the antimeridian",
+			// from 170 degrees east to 5 degrees east, going east
+			rectangle: &pb.Rectangle{
+				Lo:                  &pb.Point{Latitude: 400000000, Longitude: 1700000000},
+				Hi:                  &pb.Point{Latitude: 500000000, Longitude: 50000000},
+				CrossesAntimeridian: true,
+			},
+			want: 1,
+		},
+		{
+			name: "corners in any order",
+			// from 5 degrees east to 170 degrees east, which leaves Paris out
+			rectangle: &pb.Rectangle{
+				Lo: &pb.Point{Latitude: 400000000, Longitude: 1700000000},
+				Hi: &pb.Point{Latitude: 500000000, Longitude: 50000000},
+			},
+			want: 0,
+		},
+		{
+			name:      "empty",
+			rectangle: &pb.Rectangle{Lo: nowhere, Hi: nowhere},
+		},
+		{
+			name:      "invalid corner",
+			rectangle: &pb.Rectangle{Lo: nowhere, Hi: &pb.Point{Longitude: -1800000001}},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "rejected",
+			opts:      []grpctest.Option{grpctest.WithStreamInterceptors(reject(codes.PermissionDenied))},
+			rectangle: &pb.Rectangle{Lo: nowhere, Hi: nowhere},
+			wantCode:  codes.PermissionDenied,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := startServer(t, server.Config{}, test.opts...)
+			stream, err := client.ListFeatures(context.Background(), test.rectangle)
+			if err != nil {
+				t.Fatalf("ListFeatures(%v) = %v", test.rectangle, err)
+			}
+			count := 0
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+				count++
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if status.Code(err) != test.wantCode || count != test.want {
+				t.Fatalf("ListFeatures(%v) = %d features, %v, want %d, %v", test.rectangle, count, err, test.want, test.wantCode)
+			}
+		})
+	}
+}
+
+// TestListFeaturesCancel checks that the stream ends once the client cancels it
+func TestListFeaturesCancel(t *testing.T) {
+	client := startServer(t, server.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.ListFeatures(ctx, &pb.Rectangle{Lo: eiffelTower, Hi: eiffelTower})
+	if err != nil {
+		t.Fatalf("ListFeatures() = %v", err)
+	}
+	cancel()
+	// the feature may already be received, but the stream must end with the cancellation
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	if status.Code(err) != codes.Canceled {
+		t.Fatalf("Recv() after cancel = %v, want Canceled", err)
+	}
+}
+
+// TestRecordRouteTable checks the client streaming method
+func TestRecordRouteTable(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []grpctest.Option
+		ctx          context.Context
+		points       []*pb.Point
+		wantPoints   int32
+		wantFeatures int32
+		wantCode     codes.Code
+	}{
+		{name: "empty route", ctx: context.Background()},
+		{name: "route", ctx: context.Background(), points: []*pb.Point{nowhere, eiffelTower}, wantPoints: 2, wantFeatures: 1},
+		{name: "cancelled", ctx: canceled(), points: []*pb.Point{nowhere}, wantCode: codes.Canceled},
+		{
+			name:     "invalid point",
+			ctx:      context.Background(),
+			points:   []*pb.Point{eiffelTower, {Latitude: -900000001}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "timestamps going backwards",
+			ctx:  context.Background(),
+			points: []*pb.Point{
+				{Latitude: 10, Timestamp: timestamppb.New(time.Unix(100, 0))},
+				{Latitude: 20, Timestamp: timestamppb.New(time.Unix(50, 0))},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "message too large",
+			opts:     []grpctest.Option{grpctest.WithServerOptions(grpc.MaxRecvMsgSize(8))},
+			ctx:      context.Background(),
+			points:   []*pb.Point{{Latitude: 488583700, Longitude: 22944810}},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "rejected",
+			opts:     []grpctest.Option{grpctest.WithStreamInterceptors(reject(codes.Unauthenticated))},
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := startServer(t, server.Config{}, test.opts...)
+			stream, err := client.RecordRoute(test.ctx)
+			if err == nil {
+				for _, point := range test.points {
+					if err := stream.Send(point); err != nil {
+						// the server has ended the call: CloseAndRecv returns its status
+						break
+					}
+				}
+				var summary *pb.RouteSummary
+				summary, err = stream.CloseAndRecv()
+				if err == nil && (summary.PointCount != test.wantPoints || summary.FeatureCount != test.wantFeatures) {
+					t.Fatalf("RecordRoute(%v) = %v, want %d points and %d features", test.points, summary, test.wantPoints, test.wantFeatures)
+				}
+			}
+			if status.Code(err) != test.wantCode {
+				t.Fatalf("RecordRoute(%v) = %v, want %v", test.points, err, test.wantCode)
+			}
+		})
+	}
+}
+
+// TestRouteChatTable checks the bidirectional streaming method: the notes sent in one chat, and the
+// notes received back once the client closes its side
+func TestRouteChatTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		posted   []*pb.RouteNote // posted in a first chat
+		sent     []*pb.RouteNote
+		want     int
+		wantCode codes.Code
+	}{
+		{name: "nothing to send"},
+		{name: "subscription only", sent: []*pb.RouteNote{{Location: eiffelTower}}},
+		{
+			name:   "stored notes",
+			posted: []*pb.RouteNote{{Location: eiffelTower, Message: "first"}, {Location: eiffelTower, Message: "second"}},
+			sent:   []*pb.RouteNote{{Location: eiffelTower}},
+			want:   2,
+		},
+		{
+			name:   "other location",
+			posted: []*pb.RouteNote{{Location: eiffelTower, Message: "first"}},
+			sent:   []*pb.RouteNote{{Location: nowhere}},
+		},
+		{
+			name:     "note without location",
+			sent:     []*pb.RouteNote{{Message: "lost"}},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := startServer(t, server.Config{})
+			for _, note := range test.posted {
+				postNote(t, client, note)
+			}
+
+			stream, err := client.RouteChat(context.Background())
+			if err != nil {
+				t.Fatalf("RouteChat() = %v", err)
+			}
+			for _, note := range test.sent {
+				if err := stream.Send(note); err != nil {
+					break
+				}
+			}
+			stream.CloseSend()
+			received := 0
+			for {
+				if _, err = stream.Recv(); err != nil {
+					break
+				}
+				received++
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if status.Code(err) != test.wantCode || received != test.want {
+				t.Fatalf("RouteChat(%v) = %d notes, %v, want %d, %v", test.sent, received, err, test.want, test.wantCode)
+			}
+		})
+	}
+}
+
+// TestRouteChatCancel checks that a chat waiting for notes ends once the client cancels it
+func TestRouteChatCancel(t *testing.T) {
+	client := startServer(t, server.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	if err := stream.Send(&pb.RouteNote{Location: eiffelTower}); err != nil {
+		t.Fatalf("Send() = %v", err)
+	}
+	received := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		received <- err
+	}()
+	time.AfterFunc(50*time.Millisecond, cancel)
+	select {
+	case err := <-received:
+		if status.Code(err) != codes.Canceled {
+			t.Fatalf("Recv() after cancel = %v, want Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Recv() did not return after cancel")
+	}
+}
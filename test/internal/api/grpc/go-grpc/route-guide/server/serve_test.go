@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve runs server.Serve on a local TCP port until the returned cancel function is called.
+// The Serve error is sent to the returned channel.
+func serve(t *testing.T, config server.Config) (*grpc.ClientConn, context.CancelFunc, <-chan error) {
+	t.Helper()
+	config.DatabasePath = store.InMemory
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, config, listener)
+	}()
+
+	connection, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.Dial() = %v", err)
+	}
+	t.Cleanup(func() { connection.Close() })
+	return connection, cancel, served
+}
+
+// waitServed waits for Serve to return, at most during the given duration
+func waitServed(t *testing.T, served <-chan error, timeout time.Duration) {
+	t.Helper()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("Serve() = %v", err)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("Serve() did not return within %v", timeout)
+	}
+}
+
+// TestServeGracefulStop checks that Serve returns once its context is done
+func TestServeGracefulStop(t *testing.T) {
+	connection, stop, served := serve(t, server.DefaultConfig())
+	// the features are loaded in the background. The watch must end for the server to stop gracefully.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := healthpb.NewHealthClient(connection).Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	waitStatus(t, stream, healthpb.HealthCheckResponse_SERVING)
+	cancel()
+	if _, err := pb.NewRouteGuideClient(connection).GetFeature(context.Background(), &pb.Point{}); err != nil {
+		t.Fatalf("GetFeature() = %v", err)
+	}
+	stop()
+	waitServed(t, served, 5*time.Second)
+}
+
+// TestServeFeaturesError checks that Serve fails when the features file cannot be loaded
+func TestServeFeaturesError(t *testing.T) {
+	for name, features := range map[string]string{
+		"missing file": "",
+		"invalid JSON": `[{"name": "Louvre"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := server.DefaultConfig()
+			config.FeaturesFile = filepath.Join(t.TempDir(), "features.json")
+			if features != "" {
+				if err := os.WriteFile(config.FeaturesFile, []byte(features), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, _, served := serve(t, config)
+			select {
+			case err := <-served:
+				if err == nil || !strings.Contains(err.Error(), "failed to load the features") {
+					t.Fatalf("Serve(%s) = %v, want a features error", config.FeaturesFile, err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Serve(%s) did not fail", config.FeaturesFile)
+			}
+		})
+	}
+}
+
+// TestServeShutdownTimeout checks that the calls still running after the shutdown timeout are
+// closed
+func TestServeShutdownTimeout(t *testing.T) {
+	config := server.DefaultConfig()
+	config.ShutdownTimeout = 100 * time.Millisecond
+	connection, stop, served := serve(t, config)
+
+	// a RouteChat stream only ends when the client closes it
+	stream, err := pb.NewRouteGuideClient(connection).RouteChat(context.Background())
+	if err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	if err := stream.Send(&pb.RouteNote{Location: chatLocation}); err != nil {
+		t.Fatalf("Send() = %v", err)
+	}
+	stop()
+	waitServed(t, served, 5*time.Second)
+	if _, err := stream.Recv(); err == nil {
+		t.Fatal("Recv() succeeded after the server stopped")
+	}
+}
+
+// TestServeMessageLimit checks that the messages bigger than the limit are rejected
+func TestServeMessageLimit(t *testing.T) {
+	config := server.DefaultConfig()
+	config.Limits.MaxRecvMsgSize = 1024
+	connection, _, _ := serve(t, config)
+
+	stream, err := pb.NewRouteGuideClient(connection).RouteChat(context.Background())
+	if err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	stream.Send(&pb.RouteNote{Location: chatLocation, Message: strings.Repeat("x", 2048)})
+	if _, err := stream.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Recv() = %v, want ResourceExhausted", err)
+	}
+}
+
+// TestServeReflection checks that the reflection service lists the RouteGuide service when enabled
+func TestServeReflection(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		config := server.DefaultConfig()
+		config.Reflection = enabled
+		connection, _, _ := serve(t, config)
+
+		stream, err := reflectionpb.NewServerReflectionClient(connection).ServerReflectionInfo(context.Background())
+		if err != nil {
+			t.Fatalf("ServerReflectionInfo() = %v", err)
+		}
+		stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		response, err := stream.Recv()
+		if !enabled {
+			if status.Code(err) != codes.Unimplemented {
+				t.Fatalf("reflection disabled: Recv() = %v, want Unimplemented", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Recv() = %v", err)
+		}
+		found := false
+		for _, service := range response.GetListServicesResponse().GetService() {
+			found = found || service.Name == "main.RouteGuide"
+		}
+		if !found {
+			t.Fatalf("reflection lists %v, want main.RouteGuide", response.GetListServicesResponse())
+		}
+	}
+}
+
+// freePort returns a free local port, for the gateway
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() = %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// TestServeGateway checks that the gateway is served on its own port, and stopped with the server
+func TestServeGateway(t *testing.T) {
+	port := freePort(t)
+	config := server.DefaultConfig()
+	config.Gateway = server.GatewayConfig{Host: "127.0.0.1", Port: port}
+	connection, stop, served := serve(t, config)
+	// wait for the features, the gateway is started in the meantime
+	if _, err := healthpb.NewHealthClient(connection).Check(context.Background(), &healthpb.HealthCheckRequest{},
+		grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/v1/features?lat=48.85837&lng=2.294481", port)
+	reply, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s = %v", url, err)
+	}
+	data, _ := io.ReadAll(reply.Body)
+	reply.Body.Close()
+	if reply.StatusCode != http.StatusOK || !strings.Contains(string(data), "Eiffel Tour") {
+		t.Fatalf("GET %s = %d %s, want the Eiffel Tour", url, reply.StatusCode, data)
+	}
+
+	stop()
+	waitServed(t, served, 5*time.Second)
+	if _, err := http.Get(url); err == nil {
+		t.Fatalf("GET %s after the stop = nil, want an error", url)
+	}
+}
+
+// TestServeGatewayWatch checks that the server-sent events of the gateway end when the server stops,
+// instead of holding the shutdown until its timeout
+func TestServeGatewayWatch(t *testing.T) {
+	port := freePort(t)
+	config := server.DefaultConfig()
+	config.ShutdownTimeout = time.Minute
+	config.Gateway = server.GatewayConfig{Host: "127.0.0.1", Port: port}
+	connection, stop, served := serve(t, config)
+	if _, err := healthpb.NewHealthClient(connection).Check(context.Background(), &healthpb.HealthCheckRequest{},
+		grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/v1/notes:watch?lat=48.85837&lng=2.294481", port)
+	reply, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s = %v", url, err)
+	}
+	defer reply.Body.Close()
+	if reply.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s = %d, want 200", url, reply.StatusCode)
+	}
+
+	stop()
+	waitServed(t, served, 5*time.Second)
+	if _, err := io.ReadAll(reply.Body); err != nil {
+		t.Fatalf("GET %s: reading the events = %v, want their end", url, err)
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/grpc"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var chatLocation = &pb.Point{Latitude: 48858370, Longitude: 2294481}
+
+// startServer serves a RouteGuide server on an in-memory listener and returns a client to it
+func startServer(t *testing.T, config server.Config, opts ...grpctest.Option) pb.RouteGuideClient {
+	t.Helper()
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+	routeGuide, err := server.NewServer(config, routes)
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	s := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterRouteGuideServer(s, routeGuide)
+	}, opts...)
+	return pb.NewRouteGuideClient(s.Conn)
+}
+
+// postNote posts a single note in its own RouteChat stream
+func postNote(t *testing.T, client pb.RouteGuideClient, note *pb.RouteNote) {
+	t.Helper()
+	stream, err := client.RouteChat(context.Background())
+	if err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	if err := stream.Send(note); err != nil {
+		t.Fatalf("Send(%v) = %v", note, err)
+	}
+	stream.CloseSend()
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("Recv() = %v", err)
+		}
+	}
+}
+
+// TestRouteChatNotesPersist checks that a new stream receives the notes posted by a closed one
+func TestRouteChatNotesPersist(t *testing.T) {
+	client := startServer(t, server.Config{})
+	postNote(t, client, &pb.RouteNote{Location: chatLocation, Message: "first"})
+	postNote(t, client, &pb.RouteNote{Location: chatLocation, Message: "second"})
+
+	stream, err := client.RouteChat(context.Background())
+	if err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	stream.Send(&pb.RouteNote{Location: chatLocation})
+	stream.CloseSend()
+	var messages []string
+	for {
+		note, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() = %v", err)
+		}
+		messages = append(messages, note.Message)
+	}
+	if len(messages) != 2 || messages[0] != "first" || messages[1] != "second" {
+		t.Fatalf("received %v, want [first second]", messages)
+	}
+}
+
+// TestRouteChatBroadcast opens many concurrent streams watching the same location. Every client posts
+// one note and must receive the notes of all the others.
+// Run it with the race detector: go test -race
+func TestRouteChatBroadcast(t *testing.T) {
+	const clients = 30
+	client := startServer(t, server.Config{Chat: server.ChatConfig{BufferSize: clients}})
+	// the seed note lets each client know when its subscription is effective
+	postNote(t, client, &pb.RouteNote{Location: chatLocation, Message: "seed"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var subscribed, done sync.WaitGroup
+	subscribed.Add(clients)
+	errs := make(chan error, clients)
+	for i := 0; i < clients; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			errs <- chat(ctx, client, i, clients, &subscribed)
+		}(i)
+	}
+	done.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// chat subscribes to the chat location, waits for every client to be subscribed, posts its note and
+// reads the notes of the other clients
+func chat(ctx context.Context, client pb.RouteGuideClient, id int, clients int, subscribed *sync.WaitGroup) error {
+	stream, err := client.RouteChat(ctx)
+	if err != nil {
+		subscribed.Done()
+		return err
+	}
+	if err := stream.Send(&pb.RouteNote{Location: chatLocation}); err != nil {
+		subscribed.Done()
+		return err
+	}
+	if seed, err := stream.Recv(); err != nil || seed.Message != "seed" {
+		subscribed.Done()
+		return fmt.Errorf("client %d: Recv() = %v, %v, want the seed note", id, seed, err)
+	}
+	subscribed.Done()
+	subscribed.Wait()
+
+	if err := stream.Send(&pb.RouteNote{Location: chatLocation, Message: fmt.Sprint(id)}); err != nil {
+		return err
+	}
+	received := make(map[string]bool)
+	for len(received) < clients-1 {
+		note, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("client %d: Recv() = %v after %d notes", id, err, len(received))
+		}
+		if note.Message == fmt.Sprint(id) {
+			return fmt.Errorf("client %d received its own note", id)
+		}
+		received[note.Message] = true
+	}
+	return stream.CloseSend()
+}
+
+// postNotes posts the notes in a RouteChat stream, reading the stored notes sent back meanwhile
+func postNotes(ctx context.Context, client pb.RouteGuideClient, notes []*pb.RouteNote) error {
+	stream, err := client.RouteChat(ctx)
+	if err != nil {
+		return err
+	}
+	drained := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				drained <- err
+				return
+			}
+		}
+	}()
+	for _, note := range notes {
+		if err := stream.Send(note); err != nil {
+			return err
+		}
+	}
+	stream.CloseSend()
+	return <-drained
+}
+
+// TestRouteChatStoredNotesFirst checks that the stored notes of a location are sent before the new
+// ones, even when the new ones are posted while the server is still sending the notes of another
+// location
+func TestRouteChatStoredNotesFirst(t *testing.T) {
+	const stored, live = 100, 50
+	client := startServer(t, server.Config{Chat: server.ChatConfig{BufferSize: 2 * live}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// the notes of the other location exceed the flow control window: the server blocks on sending
+	// them until the client reads
+	otherLocation := &pb.Point{Latitude: 48860611, Longitude: 2337644}
+	var notes []*pb.RouteNote
+	for i := 0; i < 8; i++ {
+		notes = append(notes, &pb.RouteNote{Location: otherLocation, Message: strings.Repeat("x", 256*1024)})
+	}
+	for i := 0; i < stored; i++ {
+		notes = append(notes, &pb.RouteNote{Location: chatLocation, Message: fmt.Sprint("stored ", i)})
+	}
+	if err := postNotes(ctx, client, notes); err != nil {
+		t.Fatalf("posting the stored notes: %v", err)
+	}
+
+	stream, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat() = %v", err)
+	}
+	for _, location := range []*pb.Point{otherLocation, chatLocation} {
+		if err := stream.Send(&pb.RouteNote{Location: location}); err != nil {
+			t.Fatalf("Send() = %v", err)
+		}
+	}
+	// let the server receive the subscriptions, then post the new notes while it is blocked
+	time.Sleep(100 * time.Millisecond)
+	notes = make([]*pb.RouteNote, live)
+	for i := range notes {
+		notes[i] = &pb.RouteNote{Location: chatLocation, Message: fmt.Sprint("live ", i)}
+	}
+	if err := postNotes(ctx, client, notes); err != nil {
+		t.Fatalf("posting the new notes: %v", err)
+	}
+
+	want := 0
+	for want < stored+live {
+		note, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() = %v after %d notes", err, want)
+		}
+		if note.Location.Latitude == otherLocation.Latitude {
+			continue
+		}
+		wantMessage := fmt.Sprint("stored ", want)
+		if want >= stored {
+			wantMessage = fmt.Sprint("live ", want-stored)
+		}
+		if note.Message != wantMessage {
+			t.Fatalf("Recv() = %q, want %q", note.Message, wantMessage)
+		}
+		want++
+	}
+	stream.CloseSend()
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+func openStore(t *testing.T) *store.Store {
+	t.Helper()
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+	return routes
+}
+
+func newRoute(start time.Time) *pb.Route {
+	return &pb.Route{
+		Points: []*pb.Point{
+			{Latitude: 48862578, Longitude: 2287758},
+			{Latitude: 48860672, Longitude: 2290730},
+		},
+		Summary:   &pb.RouteSummary{PointCount: 2, Distance: 300},
+		StartTime: timestamppb.New(start),
+		EndTime:   timestamppb.New(start.Add(time.Minute)),
+	}
+}
+
+// TestSaveAndGetRoute checks that a stored route is read back with its points and summary
+func TestSaveAndGetRoute(t *testing.T) {
+	routes := openStore(t)
+	route := newRoute(time.Now())
+	if err := routes.SaveRoute(route); err != nil {
+		t.Fatalf("SaveRoute() = %v", err)
+	}
+	if route.Id == 0 || route.Summary.RouteId != route.Id {
+		t.Fatalf("SaveRoute() set Id = %d and Summary.RouteId = %d, want the same new ID", route.Id, route.Summary.RouteId)
+	}
+
+	got, err := routes.GetRoute(route.Id)
+	if err != nil {
+		t.Fatalf("GetRoute(%d) = %v", route.Id, err)
+	}
+	if !proto.Equal(got, route) {
+		t.Fatalf("GetRoute(%d) = %v, want %v", route.Id, got, route)
+	}
+}
+
+// TestSaveLongRoute checks that the routes having more points than the SQLite variables of a
+// statement are stored, like 3 hours of 1 Hz GPS points
+func TestSaveLongRoute(t *testing.T) {
+	routes := openStore(t)
+	start := time.Now()
+	route := newRoute(start)
+	route.Points = nil
+	for i := 0; i < 10000; i++ {
+		route.Points = append(route.Points, &pb.Point{
+			Latitude:  488625780 + int32(i),
+			Longitude: 22877580,
+			Timestamp: timestamppb.New(start.Add(time.Duration(i) * time.Second)),
+		})
+	}
+	if err := routes.SaveRoute(route); err != nil {
+		t.Fatalf("SaveRoute(10000 points) = %v", err)
+	}
+	got, err := routes.GetRoute(route.Id)
+	if err != nil {
+		t.Fatalf("GetRoute(%d) = %v", route.Id, err)
+	}
+	if !proto.Equal(got, route) {
+		t.Fatalf("GetRoute(%d) = %d points, want the %d points in order", route.Id, len(got.Points), len(route.Points))
+	}
+}
+
+// TestGetUnknownRoute checks that an unknown route returns ErrNotFound
+func TestGetUnknownRoute(t *testing.T) {
+	routes := openStore(t)
+	if _, err := routes.GetRoute(42); err != store.ErrNotFound {
+		t.Fatalf("GetRoute(42) = %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+// TestListRoutes checks the paging and the time range filter
+func TestListRoutes(t *testing.T) {
+	routes := openStore(t)
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		if err := routes.SaveRoute(newRoute(start.Add(time.Duration(i) * time.Hour))); err != nil {
+			t.Fatalf("SaveRoute() = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter store.Filter
+		want   []uint64
+	}{
+		{"all", store.Filter{}, []uint64{1, 2, 3, 4, 5}},
+		{"first page", store.Filter{Limit: 2}, []uint64{1, 2}},
+		{"next page", store.Filter{AfterID: 2, Limit: 2}, []uint64{3, 4}},
+		{"time range", store.Filter{StartFrom: start.Add(time.Hour), StartTo: start.Add(3 * time.Hour)}, []uint64{2, 3}},
+		{"time range in another zone", store.Filter{StartFrom: start.Add(4 * time.Hour).In(time.FixedZone("UTC+2", 7200))}, []uint64{5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := routes.ListRoutes(test.filter)
+			if err != nil {
+				t.Fatalf("ListRoutes(%+v) = %v", test.filter, err)
+			}
+			var ids []uint64
+			for _, route := range got {
+				if len(route.Points) != 0 {
+					t.Fatalf("ListRoutes() returned route %d with its points", route.Id)
+				}
+				ids = append(ids, route.Id)
+			}
+			if len(ids) != len(test.want) {
+				t.Fatalf("ListRoutes(%+v) = %v, want %v", test.filter, ids, test.want)
+			}
+			for i := range ids {
+				if ids[i] != test.want[i] {
+					t.Fatalf("ListRoutes(%+v) = %v, want %v", test.filter, ids, test.want)
+				}
+			}
+		})
+	}
+}
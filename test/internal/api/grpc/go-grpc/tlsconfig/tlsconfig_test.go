@@ -0,0 +1,183 @@
+package tlsconfig
+
+import (
+	"context"
+	pb "golang_starter/internal/api/grpc/go-grpc/route-guide"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/store"
+	"golang_starter/internal/api/grpc/go-grpc/tlsconfig"
+	"google.golang.org/grpc"
+	"net"
+	"testing"
+	"time"
+)
+
+// startTLSServer serves a RouteGuide server with the given TLS configuration on a local TCP port and
+// returns its address
+func startTLSServer(t *testing.T, config tlsconfig.Server) string {
+	t.Helper()
+	credentials, err := config.ServerOption()
+	if err != nil {
+		t.Fatalf("ServerOption() = %v", err)
+	}
+	routes, err := store.Open(store.InMemory)
+	if err != nil {
+		t.Fatalf("store.Open() = %v", err)
+	}
+	t.Cleanup(func() { routes.Close() })
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() = %v", err)
+	}
+	grpcServer := grpc.NewServer(credentials)
+	routeGuide, err := server.NewServer(server.Config{}, routes)
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	pb.RegisterRouteGuideServer(grpcServer, routeGuide)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+// getFeature calls GetFeature with the given client configuration
+func getFeature(t *testing.T, address string, config tlsconfig.Client) error {
+	t.Helper()
+	credentials, err := config.DialOption()
+	if err != nil {
+		t.Fatalf("DialOption() = %v", err)
+	}
+	connection, err := grpc.Dial(address, credentials)
+	if err != nil {
+		t.Fatalf("grpc.Dial() = %v", err)
+	}
+	defer connection.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = pb.NewRouteGuideClient(connection).GetFeature(ctx, &pb.Point{})
+	return err
+}
+
+// TestMutualTLS checks that only the clients with a certificate signed by the client CA are accepted
+func TestMutualTLS(t *testing.T) {
+	certificates, err := tlsconfig.GenerateDevCertificates(t.TempDir(), []string{"localhost", "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("GenerateDevCertificates() = %v", err)
+	}
+	otherCertificates, err := tlsconfig.GenerateDevCertificates(t.TempDir(), []string{"localhost"})
+	if err != nil {
+		t.Fatalf("GenerateDevCertificates() = %v", err)
+	}
+	address := startTLSServer(t, certificates.Server())
+
+	withoutCertificate := certificates.Client()
+	withoutCertificate.CertFile, withoutCertificate.KeyFile = "", ""
+	untrustedCertificate := certificates.Client()
+	untrustedCertificate.CertFile, untrustedCertificate.KeyFile = otherCertificates.ClientCertFile, otherCertificates.ClientKeyFile
+	serverNameOverride := certificates.Client()
+	serverNameOverride.ServerName = "localhost"
+	wrongServerName := certificates.Client()
+	wrongServerName.ServerName = "example.com"
+
+	tests := []struct {
+		name     string
+		client   tlsconfig.Client
+		accepted bool
+	}{
+		{"valid client certificate", certificates.Client(), true},
+		{"server name override", serverNameOverride, true},
+		{"no client certificate", withoutCertificate, false},
+		{"client certificate from another CA", untrustedCertificate, false},
+		{"server name not in the certificate", wrongServerName, false},
+		{"server CA not trusted", otherCertificates.Client(), false},
+		{"no TLS", tlsconfig.Client{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := getFeature(t, address, test.client)
+			if test.accepted && err != nil {
+				t.Fatalf("GetFeature() = %v, want the client to be accepted", err)
+			}
+			if !test.accepted && err == nil {
+				t.Fatal("GetFeature() succeeded, want the client to be rejected")
+			}
+		})
+	}
+}
+
+// TestServerTLSWithoutClientCA checks that a server without client CA accepts clients without
+// certificate
+func TestServerTLSWithoutClientCA(t *testing.T) {
+	certificates, err := tlsconfig.GenerateDevCertificates(t.TempDir(), []string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("GenerateDevCertificates() = %v", err)
+	}
+	config := certificates.Server()
+	config.ClientCAFile = ""
+	address := startTLSServer(t, config)
+	if err := getFeature(t, address, tlsconfig.Client{CAFile: certificates.CAFile}); err != nil {
+		t.Fatalf("GetFeature() = %v, want the client to be accepted", err)
+	}
+}
+
+// TestServerValidate checks that the TLS files are all unset, or hold the certificate and the key
+func TestServerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tlsconfig.Server
+		enabled bool
+		wantErr bool
+	}{
+		{name: "plaintext", config: tlsconfig.Server{}},
+		{name: "TLS", config: tlsconfig.Server{CertFile: "server.pem", KeyFile: "server.key"}, enabled: true},
+		{name: "mutual TLS", config: tlsconfig.Server{CertFile: "server.pem", KeyFile: "server.key", ClientCAFile: "ca.pem"}, enabled: true},
+		{name: "client CA only", config: tlsconfig.Server{ClientCAFile: "ca.pem"}, enabled: true, wantErr: true},
+		{name: "key only", config: tlsconfig.Server{KeyFile: "server.key"}, enabled: true, wantErr: true},
+		{name: "cert only", config: tlsconfig.Server{CertFile: "server.pem"}, enabled: true, wantErr: true},
+	}
+	for _, test := range tests {
+		if enabled := test.config.Enabled(); enabled != test.enabled {
+			t.Errorf("Enabled(%s) = %v, want %v", test.name, enabled, test.enabled)
+		}
+		if err := test.config.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate(%s) = %v, want error %v", test.name, err, test.wantErr)
+		}
+		if test.wantErr {
+			if option, err := test.config.ServerOption(); err == nil {
+				t.Errorf("ServerOption(%s) = %v, want an error rather than a plaintext server", test.name, option)
+			}
+		}
+	}
+}
+
+// TestClientValidate checks that any TLS setting enables TLS on the client, and that the client
+// certificate comes with its key
+func TestClientValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tlsconfig.Client
+		usesTLS bool
+		wantErr bool
+	}{
+		{name: "plaintext", config: tlsconfig.Client{}},
+		{name: "enabled", config: tlsconfig.Client{Enabled: true}, usesTLS: true},
+		{name: "CA", config: tlsconfig.Client{CAFile: "ca.pem"}, usesTLS: true},
+		{name: "server name", config: tlsconfig.Client{ServerName: "localhost"}, usesTLS: true},
+		{name: "mutual TLS", config: tlsconfig.Client{CertFile: "client.pem", KeyFile: "client.key"}, usesTLS: true},
+		{name: "key only", config: tlsconfig.Client{KeyFile: "client.key"}, usesTLS: true, wantErr: true},
+		{name: "cert only", config: tlsconfig.Client{CAFile: "ca.pem", CertFile: "client.pem"}, usesTLS: true, wantErr: true},
+	}
+	for _, test := range tests {
+		if usesTLS := test.config.UsesTLS(); usesTLS != test.usesTLS {
+			t.Errorf("UsesTLS(%s) = %v, want %v", test.name, usesTLS, test.usesTLS)
+		}
+		if err := test.config.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate(%s) = %v, want error %v", test.name, err, test.wantErr)
+		}
+		if test.wantErr {
+			if option, err := test.config.DialOption(); err == nil {
+				t.Errorf("DialOption(%s) = %v, want an error", test.name, option)
+			}
+		}
+	}
+}
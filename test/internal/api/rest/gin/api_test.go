@@ -0,0 +1,224 @@
+package gin
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	albums "golang_starter/internal/api/rest/gin"
+	zaplogger "golang_starter/internal/logger/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func init() {
+	// do not print the routes
+	gin.SetMode(gin.TestMode)
+}
+
+// do sends a request to the router, and returns the response
+func do(router http.Handler, method string, target string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// TestInvalidRequests checks that the invalid requests are answered 400 with a message
+func TestInvalidRequests(t *testing.T) {
+	router := albums.NewRouter(zap.NewNop())
+	tests := []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/albums/first", ""},
+		{http.MethodGet, "/albums?limit=-1", ""},
+		{http.MethodGet, "/albums?offset=two", ""},
+		{http.MethodGet, "/albums?maxPrice=cheap", ""},
+		{http.MethodPost, "/albums", "{"},
+		{http.MethodPost, "/albums", `{"artist": "Daft Punk"}`},
+		{http.MethodPut, "/albums/1", `{"title": "Jeru", "price": -1}`},
+		{http.MethodDelete, "/albums/first", ""},
+	}
+	for _, test := range tests {
+		response := do(router, test.method, test.target, test.body)
+		var body struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(response.Body.Bytes(), &body); response.Code != http.StatusBadRequest || err != nil || body.Message == "" {
+			t.Fatalf("%s %s = %d %s, want 400 with a message", test.method, test.target, response.Code, response.Body)
+		}
+	}
+}
+
+// TestDelete checks the answer of DELETE, and that a deleted album is not found anymore
+func TestDelete(t *testing.T) {
+	router := albums.NewRouter(zap.NewNop())
+	if response := do(router, http.MethodDelete, "/albums/2", ""); response.Code != http.StatusOK ||
+		!strings.Contains(response.Body.String(), "album deleted") {
+		t.Fatalf("DELETE /albums/2 = %d %s, want 200", response.Code, response.Body)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if response := do(router, method, "/albums/2", ""); response.Code != http.StatusNotFound {
+			t.Fatalf("%s /albums/2 after DELETE = %d, want 404", method, response.Code)
+		}
+	}
+	if response := do(router, http.MethodGet, "/albums", ""); response.Header().Get(albums.TotalCountHeader) != "2" {
+		t.Fatalf("GET /albums after DELETE = %s albums, want 2", response.Header().Get(albums.TotalCountHeader))
+	}
+}
+
+// TestRoutersAreIndependent checks that every router has its own albums
+func TestRoutersAreIndependent(t *testing.T) {
+	first, second := albums.NewRouter(zap.NewNop()), albums.NewRouter(zap.NewNop())
+	do(first, http.MethodDelete, "/albums/1", "")
+	if response := do(second, http.MethodGet, "/albums/1", ""); response.Code != http.StatusOK {
+		t.Fatalf("GET /albums/1 on another router = %d, want 200", response.Code)
+	}
+}
+
+// TestConcurrentCreate checks that the albums created concurrently get distinct ids
+func TestConcurrentCreate(t *testing.T) {
+	router := albums.NewRouter(zap.NewNop())
+	const count = 50
+	ids := make(chan int, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var created struct {
+				ID int `json:"id"`
+			}
+			response := do(router, http.MethodPost, "/albums", `{"title": "Discovery"}`)
+			json.Unmarshal(response.Body.Bytes(), &created)
+			ids <- created.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := map[int]bool{}
+	for id := range ids {
+		if id <= 3 || seen[id] {
+			t.Fatalf("POST /albums created the id %d twice, or an id of the sample albums", id)
+		}
+		seen[id] = true
+	}
+}
+
+// TestConditionalGet checks that the GET responses carry an ETag and a Last-Modified time, and are
+// answered 304 Not Modified when the client already has them
+func TestConditionalGet(t *testing.T) {
+	router := albums.NewRouter(zap.NewNop())
+	// the Last-Modified time is sent once the second of the last change is over
+	waitNextSecond()
+	first := do(router, http.MethodGet, "/albums", "")
+	tag, modified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if tag == "" || modified == "" || first.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("GET /albums headers = %v, want an ETag, a Last-Modified time and no-cache", first.Header())
+	}
+	if page := do(router, http.MethodGet, "/albums?limit=1", ""); page.Header().Get("ETag") == tag {
+		t.Fatalf("GET /albums?limit=1 has the ETag of GET /albums")
+	}
+
+	conditional := func(name string, value string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/albums", nil)
+		request.Header.Set(name, value)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	for name, value := range map[string]string{"If-None-Match": tag, "If-Modified-Since": modified} {
+		if response := conditional(name, value); response.Code != http.StatusNotModified || response.Body.Len() != 0 {
+			t.Fatalf("GET /albums with %s = %d %s, want 304 without body", name, response.Code, response.Body)
+		}
+	}
+	if response := conditional("If-None-Match", `"other"`); response.Code != http.StatusOK {
+		t.Fatalf("GET /albums with another ETag = %d, want 200", response.Code)
+	}
+
+	do(router, http.MethodDelete, "/albums/1", "")
+	if response := conditional("If-None-Match", tag); response.Code != http.StatusOK || response.Header().Get("ETag") == tag {
+		t.Fatalf("GET /albums after a change = %d with the ETag %s, want 200 with a new ETag", response.Code, response.Header().Get("ETag"))
+	}
+}
+
+// waitNextSecond waits for the start of the next second
+func waitNextSecond() {
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+}
+
+// TestConditionalGetSameSecond checks that a change in the same second as a response is not hidden
+// by the Last-Modified time of the response
+func TestConditionalGetSameSecond(t *testing.T) {
+	router := albums.NewRouter(zap.NewNop())
+	waitNextSecond()
+	do(router, http.MethodDelete, "/albums/1", "")
+	first := do(router, http.MethodGet, "/albums", "")
+	do(router, http.MethodDelete, "/albums/2", "")
+	// the second is usually not over yet, and the response has no Last-Modified time
+	modified := first.Header().Get("Last-Modified")
+	for name, value := range map[string]string{"If-None-Match": first.Header().Get("ETag"), "If-Modified-Since": modified} {
+		if value == "" {
+			continue
+		}
+		request := httptest.NewRequest(http.MethodGet, "/albums", nil)
+		request.Header.Set(name, value)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET /albums with %s after a change in the same second = %d, want 200", name, recorder.Code)
+		}
+	}
+}
+
+// TestAllowedOrigins checks that only the allowed origins get the CORS headers, and that they can
+// change while the router runs
+func TestAllowedOrigins(t *testing.T) {
+	origins := albums.NewAllowedOrigins("https://albums.example.com")
+	router := albums.NewRouter(zap.NewNop(), albums.WithAllowedOrigins(origins))
+	get := func(origin string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/albums", nil)
+		request.Header.Set("Origin", origin)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if response := get("https://albums.example.com"); response.Header().Get("Access-Control-Allow-Origin") != "https://albums.example.com" {
+		t.Fatalf("GET /albums from an allowed origin = %v, want the CORS headers", response.Header())
+	}
+	if response := get("https://other.example.com"); response.Code != http.StatusForbidden {
+		t.Fatalf("GET /albums from another origin = %d, want 403", response.Code)
+	}
+	origins.Set([]string{"*"})
+	if response := get("https://other.example.com"); response.Code != http.StatusOK ||
+		response.Header().Get("Access-Control-Allow-Origin") != "https://other.example.com" {
+		t.Fatalf("GET /albums once every origin is allowed = %d %v, want 200", response.Code, response.Header())
+	}
+}
+
+// TestAdminHandler checks that the admin handler changes the level of the logger, and serves nothing
+// else
+func TestAdminHandler(t *testing.T) {
+	logger, err := zaplogger.New(zaplogger.DefaultConfig())
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	defer logger.Close()
+	handler := albums.NewAdminHandler(logger)
+
+	if response := do(handler, http.MethodPut, "/log/level", `{"level":"debug"}`); response.Code != http.StatusOK {
+		t.Fatalf("PUT /log/level = %d %s, want 200", response.Code, response.Body)
+	}
+	if !logger.Core().Enabled(zapcore.DebugLevel) {
+		t.Fatalf("PUT /log/level debug did not enable the debug level")
+	}
+	if response := do(handler, http.MethodGet, "/albums", ""); response.Code != http.StatusNotFound {
+		t.Fatalf("GET /albums on the admin handler = %d, want 404", response.Code)
+	}
+}
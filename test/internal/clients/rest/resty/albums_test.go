@@ -0,0 +1,192 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	albums "golang_starter/internal/api/rest/gin"
+	"golang_starter/internal/clients/rest/resty"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func init() {
+	// do not print the routes
+	gin.SetMode(gin.TestMode)
+}
+
+// serve starts the albums API on a local port, with its sample albums, and returns its URL.
+// The handler is called before the router, to inspect the requests.
+func serve(t *testing.T, handler func(r *http.Request)) string {
+	t.Helper()
+	router := albums.NewRouter(zap.NewNop())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler != nil {
+			handler(r)
+		}
+		router.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func price(p float64) *float64 {
+	return &p
+}
+
+// TestList checks the filters and the pagination of List
+func TestList(t *testing.T) {
+	client := resty.NewAlbumsClient(serve(t, nil))
+	tests := []struct {
+		name      string
+		options   resty.ListOptions
+		wantIDs   []int
+		wantTotal int
+	}{
+		{"all", resty.ListOptions{}, []int{1, 2, 3}, 3},
+		{"artist", resty.ListOptions{Artist: "john coltrane"}, []int{1}, 1},
+		{"title", resty.ListOptions{Title: "BROWN"}, []int{3}, 1},
+		{"price range", resty.ListOptions{MinPrice: price(17.99), MaxPrice: price(40)}, []int{2, 3}, 2},
+		{"first page", resty.ListOptions{Limit: 2}, []int{1, 2}, 3},
+		{"second page", resty.ListOptions{Offset: 2, Limit: 2}, []int{3}, 3},
+		{"after the last page", resty.ListOptions{Offset: 5}, []int{}, 3},
+		{"no match", resty.ListOptions{Artist: "Daft Punk"}, []int{}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := client.List(context.Background(), test.options)
+			if err != nil {
+				t.Fatalf("List(%+v) = %v", test.options, err)
+			}
+			ids := []int{}
+			for _, album := range page.Albums {
+				ids = append(ids, album.ID)
+			}
+			if !reflect.DeepEqual(ids, test.wantIDs) || page.Total != test.wantTotal {
+				t.Fatalf("List(%+v) = %v of %d, want %v of %d", test.options, ids, page.Total, test.wantIDs, test.wantTotal)
+			}
+		})
+	}
+}
+
+// TestCRUD checks that the created albums can be read, updated and deleted
+func TestCRUD(t *testing.T) {
+	ctx := context.Background()
+	client := resty.NewAlbumsClient(serve(t, nil))
+
+	created, err := client.Create(ctx, resty.AlbumInput{Title: "Discovery", Artist: "Daft Punk", Price: 9.99})
+	if err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	want := resty.Album{ID: 4, Title: "Discovery", Artist: "Daft Punk", Price: 9.99}
+	if *created != want {
+		t.Fatalf("Create() = %+v, want %+v", created, want)
+	}
+	if got, err := client.Get(ctx, created.ID); err != nil || *got != want {
+		t.Fatalf("Get(%d) = %+v, %v, want %+v", created.ID, got, err, want)
+	}
+
+	want.Price = 12.5
+	updated, err := client.Update(ctx, created.ID, resty.AlbumInput{Title: want.Title, Artist: want.Artist, Price: want.Price})
+	if err != nil || *updated != want {
+		t.Fatalf("Update(%d) = %+v, %v, want %+v", created.ID, updated, err, want)
+	}
+	if got, err := client.Get(ctx, created.ID); err != nil || *got != want {
+		t.Fatalf("Get(%d) after Update = %+v, %v, want %+v", created.ID, got, err, want)
+	}
+
+	if err := client.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete(%d) = %v", created.ID, err)
+	}
+	if got, err := client.Get(ctx, created.ID); !resty.IsNotFound(err) {
+		t.Fatalf("Get(%d) after Delete = %+v, %v, want not found", created.ID, got, err)
+	}
+}
+
+// TestErrors checks that the error responses are returned as APIError
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	client := resty.NewAlbumsClient(serve(t, nil))
+	tests := []struct {
+		name       string
+		call       func() error
+		wantStatus int
+		wantID     int
+	}{
+		{"get unknown", func() error { _, err := client.Get(ctx, 42); return err }, http.StatusNotFound, 42},
+		{"update unknown", func() error {
+			_, err := client.Update(ctx, 42, resty.AlbumInput{Title: "Jeru"})
+			return err
+		}, http.StatusNotFound, 42},
+		{"delete unknown", func() error { return client.Delete(ctx, 42) }, http.StatusNotFound, 42},
+		{"create without title", func() error { _, err := client.Create(ctx, resty.AlbumInput{Artist: "Daft Punk"}); return err }, http.StatusBadRequest, 0},
+		{"negative price", func() error { _, err := client.Create(ctx, resty.AlbumInput{Title: "Jeru", Price: -1}); return err }, http.StatusBadRequest, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call()
+			var apiErr *resty.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want an APIError", err)
+			}
+			if apiErr.StatusCode != test.wantStatus || apiErr.ID != test.wantID || apiErr.Message == "" {
+				t.Fatalf("error = %+v, want status %d and id %d with a message", apiErr, test.wantStatus, test.wantID)
+			}
+			if resty.IsNotFound(err) != (test.wantStatus == http.StatusNotFound) {
+				t.Fatalf("IsNotFound(%v) = %v", err, resty.IsNotFound(err))
+			}
+		})
+	}
+}
+
+// TestPlainTextError checks that the error responses without JSON body are returned as APIError
+func TestPlainTextError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := resty.NewAlbumsClient(server.URL).Get(context.Background(), 1)
+	var apiErr *resty.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Get() = %v, want an APIError with the 500 status", err)
+	}
+}
+
+// TestOptions checks that the requests are sent with the token and the user agent of the options
+func TestOptions(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	url := serve(t, func(r *http.Request) { headers <- r.Header.Clone() })
+	client := resty.NewAlbumsClient(url, resty.WithAuthToken("s3cr3t"), resty.WithUserAgent("albums-test/1.0"))
+
+	if _, err := client.Get(context.Background(), 1); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	header := <-headers
+	if got := header.Get("Authorization"); got != "Bearer s3cr3t" {
+		t.Fatalf("Authorization = %q, want %q", got, "Bearer s3cr3t")
+	}
+	if got := header.Get("User-Agent"); got != "albums-test/1.0" {
+		t.Fatalf("User-Agent = %q, want %q", got, "albums-test/1.0")
+	}
+}
+
+// TestTimeout checks that the requests fail once the timeout is over
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	url := serve(t, func(r *http.Request) { <-release })
+	defer close(release)
+	client := resty.NewAlbumsClient(url, resty.WithTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	if _, err := client.Get(context.Background(), 1); err == nil {
+		t.Fatal("Get() = nil, want a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Get() returned after %v, want about 50ms", elapsed)
+	}
+}
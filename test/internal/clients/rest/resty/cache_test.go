@@ -0,0 +1,210 @@
+package resty
+
+import (
+	"context"
+	"golang_starter/internal/clients/rest/resty"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// wantStats fails when the cache has not counted the given hits, revalidations and misses
+func wantStats(t *testing.T, cache *resty.Cache, want resty.CacheStats) {
+	t.Helper()
+	if got := cache.Stats(); got != want {
+		t.Fatalf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+// TestCacheRevalidation checks that the cached albums are revalidated with the ETag of the Gin API,
+// and downloaded again once changed
+func TestCacheRevalidation(t *testing.T) {
+	var conditional atomic.Int64
+	url := serve(t, func(r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Add(1)
+		}
+	})
+	cache := resty.NewCache(resty.NewMemoryStore(resty.DefaultMemoryStoreSize))
+	client := resty.NewAlbumsClient(url, resty.WithCache(cache))
+	ctx := context.Background()
+
+	first, err := client.List(ctx, resty.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	second, err := client.List(ctx, resty.ListOptions{})
+	if err != nil || second.Total != first.Total || len(second.Albums) != len(first.Albums) {
+		t.Fatalf("List() again = %+v, %v, want %+v", second, err, first)
+	}
+	if conditional.Load() != 1 {
+		t.Fatalf("List() again sent %d conditional requests, want 1", conditional.Load())
+	}
+	wantStats(t, cache, resty.CacheStats{Revalidations: 1, Misses: 1})
+
+	if _, err := client.Create(ctx, resty.AlbumInput{Title: "Discovery", Artist: "Daft Punk"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	third, err := client.List(ctx, resty.ListOptions{})
+	if err != nil || third.Total != first.Total+1 {
+		t.Fatalf("List() after Create() = %+v, %v, want %d albums", third, err, first.Total+1)
+	}
+	wantStats(t, cache, resty.CacheStats{Revalidations: 1, Misses: 2})
+}
+
+// TestCacheInvalidation checks that a change of an album removes its cached response
+func TestCacheInvalidation(t *testing.T) {
+	cache := resty.NewCache(resty.NewMemoryStore(resty.DefaultMemoryStoreSize))
+	client := resty.NewAlbumsClient(serve(t, nil), resty.WithCache(cache))
+	ctx := context.Background()
+
+	if _, err := client.Get(ctx, 1); err != nil {
+		t.Fatalf("Get(1) error = %v", err)
+	}
+	if _, err := client.Update(ctx, 1, resty.AlbumInput{Title: "Giant Steps", Artist: "John Coltrane"}); err != nil {
+		t.Fatalf("Update(1) error = %v", err)
+	}
+	// the cached response was removed: the album is downloaded without revalidation
+	if album, err := client.Get(ctx, 1); err != nil || album.Title != "Giant Steps" {
+		t.Fatalf("Get(1) after Update(1) = %+v, %v, want Giant Steps", album, err)
+	}
+	wantStats(t, cache, resty.CacheStats{Misses: 2})
+
+	if err := client.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete(1) error = %v", err)
+	}
+	if _, err := client.Get(ctx, 1); !resty.IsNotFound(err) {
+		t.Fatalf("Get(1) after Delete(1) error = %v, want not found", err)
+	}
+}
+
+// cachingServer answers an album with the given Cache-Control header, and a Last-Modified time
+// honoring If-Modified-Since. It returns its URL and the number of requests received.
+func cachingServer(t *testing.T, cacheControl string) (string, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	modified := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "Blue Train", "artist": "John Coltrane", "price": 56.99}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &requests
+}
+
+// TestCacheControl checks that the responses are reused while fresh, revalidated with
+// If-Modified-Since when stale, and never kept with no-store
+func TestCacheControl(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		wantRequests int64
+		wantStats    resty.CacheStats
+	}{
+		{"max-age=60", 1, resty.CacheStats{Hits: 2, Misses: 1}},
+		{"max-age=0", 3, resty.CacheStats{Revalidations: 2, Misses: 1}},
+		{"no-cache, max-age=60", 3, resty.CacheStats{Revalidations: 2, Misses: 1}},
+		{"no-store", 3, resty.CacheStats{Misses: 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.cacheControl, func(t *testing.T) {
+			url, requests := cachingServer(t, test.cacheControl)
+			cache := resty.NewCache(resty.NewMemoryStore(resty.DefaultMemoryStoreSize))
+			client := resty.NewAlbumsClient(url, resty.WithCache(cache))
+			for i := 0; i < 3; i++ {
+				if album, err := client.Get(context.Background(), 1); err != nil || album.Title != "Blue Train" {
+					t.Fatalf("Get(1) = %+v, %v, want Blue Train", album, err)
+				}
+			}
+			if requests.Load() != test.wantRequests {
+				t.Fatalf("Get(1) 3 times sent %d requests, want %d", requests.Load(), test.wantRequests)
+			}
+			wantStats(t, cache, test.wantStats)
+		})
+	}
+}
+
+// TestDiskStore checks that the responses kept on disk are reused by another cache
+func TestDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	url, requests := cachingServer(t, "max-age=60")
+	for i := 0; i < 2; i++ {
+		store, err := resty.NewDiskStore(dir)
+		if err != nil {
+			t.Fatalf("NewDiskStore(%s) error = %v", dir, err)
+		}
+		client := resty.NewAlbumsClient(url, resty.WithCache(resty.NewCache(store)))
+		if album, err := client.Get(context.Background(), 1); err != nil || album.Title != "Blue Train" {
+			t.Fatalf("Get(1) = %+v, %v, want Blue Train", album, err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("Get(1) with 2 caches on the same directory sent %d requests, want 1", requests.Load())
+	}
+
+	store, _ := resty.NewDiskStore(dir)
+	store.Set("key", &resty.CachedResponse{StatusCode: http.StatusOK, Body: []byte("body")})
+	if response, found := store.Get("key"); !found || string(response.Body) != "body" {
+		t.Fatalf("Get(key) = %+v, %t, want the stored body", response, found)
+	}
+	store.Delete("key")
+	if _, found := store.Get("key"); found {
+		t.Fatalf("Get(key) after Delete(key) found a response")
+	}
+}
+
+// TestCacheConditionalRequest checks that the 304 answering the conditional request of the caller is
+// returned as it is, and keeps the cached response
+func TestCacheConditionalRequest(t *testing.T) {
+	url, _ := cachingServer(t, "max-age=0")
+	cache := resty.NewCache(resty.NewMemoryStore(resty.DefaultMemoryStoreSize))
+	client := resty.NewAlbumsClient(url, resty.WithCache(cache))
+	if _, err := client.Get(context.Background(), 1); err != nil {
+		t.Fatalf("Get(1) error = %v", err)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, url+"/albums/1", nil)
+	request.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+	response, err := (&http.Client{Transport: cache.RoundTripper(http.DefaultTransport)}).Do(request)
+	if err != nil {
+		t.Fatalf("GET with If-Modified-Since error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotModified {
+		t.Fatalf("GET with If-Modified-Since = %d, want 304", response.StatusCode)
+	}
+
+	if album, err := client.Get(context.Background(), 1); err != nil || album.Title != "Blue Train" {
+		t.Fatalf("Get(1) again = %+v, %v, want Blue Train", album, err)
+	}
+	wantStats(t, cache, resty.CacheStats{Revalidations: 1, Misses: 2})
+}
+
+// TestMemoryStoreEviction checks that the memory store evicts the least recently used response
+func TestMemoryStoreEviction(t *testing.T) {
+	store := resty.NewMemoryStore(2)
+	response := &resty.CachedResponse{StatusCode: http.StatusOK}
+	store.Set("a", response)
+	store.Set("b", response)
+	store.Get("a")
+	store.Set("c", response)
+	if _, found := store.Get("b"); found {
+		t.Fatalf("Get(b) found the least recently used response")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found := store.Get(key); !found {
+			t.Fatalf("Get(%s) = not found, want the response", key)
+		}
+	}
+	if store.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", store.Len())
+	}
+}
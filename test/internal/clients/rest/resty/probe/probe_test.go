@@ -0,0 +1,154 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"golang_starter/internal/clients/rest/resty/probe"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echo answers 201 to the POST requests with the expected header and body, and 400 to the others
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if r.Method != http.MethodPost || r.Header.Get("X-Probe") != "yes" || string(body) != "ping" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// phases returns the phases of the report
+func phases(report *probe.Report) map[string]int {
+	counts := make(map[string]int)
+	for _, phase := range report.Phases {
+		counts[phase.Phase] = phase.Latency.Count
+	}
+	return counts
+}
+
+// TestRunRequests checks that a run sends the requested number of requests, and measures the
+// phases of the new connections only
+func TestRunRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echo))
+	t.Cleanup(server.Close)
+	header, err := probe.ParseHeaders([]string{"X-Probe: yes"})
+	if err != nil {
+		t.Fatalf("ParseHeaders() = %v", err)
+	}
+	config := probe.Config{Method: http.MethodPost, URL: server.URL, Header: header, Body: "ping", Concurrency: 2, Requests: 20}
+
+	report, err := probe.Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if report.Requests != 20 || report.Errors != 0 || report.Statuses[http.StatusCreated] != 20 {
+		t.Fatalf("Run() = %d requests, %d errors, statuses %v, want 20 requests answered 201", report.Requests, report.Errors, report.Statuses)
+	}
+	// the workers reuse their connection
+	counts := phases(report)
+	if report.NewConnections > 2 || counts[probe.Connect] != report.NewConnections || counts[probe.Server] != 20 || counts[probe.TLS] != 0 {
+		t.Fatalf("Run() phases = %v with %d new connections, want at most 2 connections and no TLS", counts, report.NewConnections)
+	}
+
+	config.DisableKeepAlives = true
+	config.Header = nil
+	report, err = probe.Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() without keep-alive = %v", err)
+	}
+	if report.NewConnections != 20 || phases(report)[probe.DNS] != 20 || report.Errors != 20 || report.Statuses[http.StatusBadRequest] != 20 {
+		t.Fatalf("Run() without keep-alive nor header = %+v, want 20 connections answered 400", report)
+	}
+}
+
+// TestRunTLS checks that the TLS handshakes are measured, and the failed requests reported
+func TestRunTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(echo))
+	t.Cleanup(server.Close)
+	config := probe.Config{URL: server.URL, Concurrency: 1, Requests: 3}
+
+	// the certificate of the test server is not trusted
+	report, err := probe.Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if report.Errors != 3 || len(report.Failures) != 1 || len(report.Statuses) != 0 {
+		t.Fatalf("Run() with an untrusted certificate = %+v, want 3 failures", report)
+	}
+
+	config.Insecure = true
+	if report, err = probe.Run(context.Background(), config); err != nil {
+		t.Fatalf("Run() insecure = %v", err)
+	}
+	if counts := phases(report); counts[probe.TLS] != 1 || report.Statuses[http.StatusBadRequest] != 3 {
+		t.Fatalf("Run() insecure phases = %v, statuses %v, want a TLS handshake", counts, report.Statuses)
+	}
+}
+
+// TestRunDuration checks that a run without number of requests ends after its duration, and the
+// outputs of the report
+func TestRunDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echo))
+	t.Cleanup(server.Close)
+	report, err := probe.Run(context.Background(), probe.Config{URL: server.URL, Concurrency: 4, Duration: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if report.Elapsed > 5*time.Second || report.Requests == 0 || report.Method != http.MethodGet {
+		t.Fatalf("Run() = %d GET requests in %v, want requests for about 200ms", report.Requests, report.Elapsed)
+	}
+
+	var out bytes.Buffer
+	if err := probe.WriteTable(&out, report); err != nil || !strings.Contains(out.String(), "P99 (ms)") || !strings.Contains(out.String(), "400 Bad Request") {
+		t.Fatalf("WriteTable() = %q, %v, want a table of the phases", out.String(), err)
+	}
+	out.Reset()
+	var decoded struct {
+		Requests       int
+		ElapsedSeconds float64
+		Statuses       map[string]int
+		Phases         []struct {
+			Phase   string
+			Latency struct{ Count int }
+		}
+	}
+	if err := probe.WriteJSON(&out, report); err != nil {
+		t.Fatalf("WriteJSON() = %v", err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded.Requests != report.Requests || decoded.Statuses["400"] != report.Requests {
+		t.Fatalf("WriteJSON() = %s, %v, want the requests answered 400", out.String(), err)
+	}
+	if last := decoded.Phases[len(decoded.Phases)-1]; last.Phase != probe.Total || last.Latency.Count != report.Requests {
+		t.Fatalf("WriteJSON() last phase = %+v, want the total of the %d requests", last, report.Requests)
+	}
+}
+
+// TestInvalidConfig checks the settings refused by Run and ParseHeaders
+func TestInvalidConfig(t *testing.T) {
+	configs := []probe.Config{
+		{URL: "http://localhost", Concurrency: 0, Requests: 1},
+		{URL: "http://localhost", Concurrency: 1},
+		{Concurrency: 1, Requests: 1},
+	}
+	for _, config := range configs {
+		if _, err := probe.Run(context.Background(), config); err == nil {
+			t.Fatalf("Run(%+v) = nil, want an error", config)
+		}
+	}
+
+	header, err := probe.ParseHeaders([]string{"Accept: application/json", "X-Empty:"})
+	if err != nil || header.Get("Accept") != "application/json" || len(header) != 2 {
+		t.Fatalf("ParseHeaders() = %v, %v, want Accept and X-Empty", header, err)
+	}
+	for _, value := range []string{"Accept", ": value"} {
+		if _, err := probe.ParseHeaders([]string{value}); err == nil {
+			t.Fatalf("ParseHeaders(%q) = nil, want an error", value)
+		}
+	}
+}
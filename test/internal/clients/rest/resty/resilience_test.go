@@ -0,0 +1,434 @@
+package resty
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	albums "golang_starter/internal/api/rest/gin"
+	"golang_starter/internal/clients/rest/resty"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fastRetry retries without waiting long, to keep the tests fast
+var fastRetry = resty.RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.5,
+	MaxRetryAfter:  2 * time.Second,
+}
+
+// failure is a scripted answer of the flaky server
+type failure struct {
+	status     int
+	retryAfter string
+	// hangUp closes the connection without answering
+	hangUp bool
+}
+
+// flakyServer serves the albums API, after answering the scripted failures to the first requests
+type flakyServer struct {
+	URL string
+
+	mu       sync.Mutex
+	failures []failure
+	attempts int
+}
+
+// serveFlaky starts a flaky server failing the first requests with the given failures
+func serveFlaky(t *testing.T, failures ...failure) *flakyServer {
+	t.Helper()
+	flaky := &flakyServer{failures: failures}
+	router := albums.NewRouter(zap.NewNop())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flaky.mu.Lock()
+		flaky.attempts++
+		var next *failure
+		if len(flaky.failures) > 0 {
+			next = &flaky.failures[0]
+			flaky.failures = flaky.failures[1:]
+		}
+		flaky.mu.Unlock()
+
+		switch {
+		case next == nil:
+			router.ServeHTTP(w, r)
+		case next.hangUp:
+			connection, _, _ := w.(http.Hijacker).Hijack()
+			connection.Close()
+		default:
+			if next.retryAfter != "" {
+				w.Header().Set("Retry-After", next.retryAfter)
+			}
+			w.WriteHeader(next.status)
+		}
+	}))
+	t.Cleanup(server.Close)
+	flaky.URL = server.URL
+	return flaky
+}
+
+// fail adds failures to the script
+func (f *flakyServer) fail(failures ...failure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, failures...)
+}
+
+// Attempts returns the number of requests received
+func (f *flakyServer) Attempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts
+}
+
+// statusOf returns the status code of an APIError, or 0
+func statusOf(err error) int {
+	var apiErr *resty.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// TestRetry checks which requests are retried
+func TestRetry(t *testing.T) {
+	unavailable := failure{status: http.StatusServiceUnavailable}
+	tests := []struct {
+		name         string
+		failures     []failure
+		call         func(*resty.AlbumsClient) error
+		wantAttempts int
+		wantStatus   int
+		wantErr      bool
+	}{
+		{
+			name:         "get after unavailable",
+			failures:     []failure{unavailable, unavailable},
+			call:         get,
+			wantAttempts: 3,
+		},
+		{
+			name:         "get after a hang up",
+			failures:     []failure{{hangUp: true}},
+			call:         get,
+			wantAttempts: 2,
+		},
+		{
+			name:         "get after a bad gateway",
+			failures:     []failure{{status: http.StatusBadGateway}},
+			call:         get,
+			wantAttempts: 2,
+		},
+		{
+			name:         "get unavailable after the last retry",
+			failures:     []failure{unavailable, unavailable, unavailable, unavailable},
+			call:         get,
+			wantAttempts: 4,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "get internal error",
+			failures:     []failure{{status: http.StatusInternalServerError}},
+			call:         get,
+			wantAttempts: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			name:         "create after too many requests",
+			failures:     []failure{{status: http.StatusTooManyRequests, retryAfter: "0"}},
+			call:         create,
+			wantAttempts: 2,
+		},
+		{
+			name:         "create after a bad gateway",
+			failures:     []failure{{status: http.StatusBadGateway}},
+			call:         create,
+			wantAttempts: 1,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "create after a hang up",
+			failures:     []failure{{hangUp: true}},
+			call:         create,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "retry after too long",
+			failures:     []failure{{status: http.StatusServiceUnavailable, retryAfter: "60"}},
+			call:         get,
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flaky := serveFlaky(t, test.failures...)
+			err := test.call(resty.NewAlbumsClient(flaky.URL, resty.WithRetry(fastRetry)))
+			if flaky.Attempts() != test.wantAttempts {
+				t.Fatalf("%d attempts, want %d", flaky.Attempts(), test.wantAttempts)
+			}
+			if test.wantStatus != 0 && statusOf(err) != test.wantStatus {
+				t.Fatalf("error = %v, want the %d status", err, test.wantStatus)
+			}
+			if test.wantStatus == 0 && test.wantErr != (err != nil) {
+				t.Fatalf("error = %v, want an error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func get(client *resty.AlbumsClient) error {
+	_, err := client.Get(context.Background(), 1)
+	return err
+}
+
+func create(client *resty.AlbumsClient) error {
+	_, err := client.Create(context.Background(), resty.AlbumInput{Title: "Discovery"})
+	return err
+}
+
+// TestRetryAfter checks that the client waits for the duration of the Retry-After header
+func TestRetryAfter(t *testing.T) {
+	for _, retryAfter := range []string{"1", "date"} {
+		if retryAfter == "date" {
+			retryAfter = time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+		}
+		flaky := serveFlaky(t, failure{status: http.StatusTooManyRequests, retryAfter: retryAfter})
+		start := time.Now()
+		if err := get(resty.NewAlbumsClient(flaky.URL, resty.WithRetry(fastRetry))); err != nil {
+			t.Fatalf("Retry-After %s: Get() = %v", retryAfter, err)
+		}
+		// the HTTP dates have a precision of one second
+		if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+			t.Fatalf("Retry-After %s: Get() returned after %v", retryAfter, elapsed)
+		}
+	}
+}
+
+// TestRetryAfterNow checks that a Retry-After of zero or in the past retries right away, rather than
+// after the backoff of resty
+func TestRetryAfterNow(t *testing.T) {
+	policy := fastRetry
+	policy.MaxRetryAfter = 10 * time.Second
+	for _, retryAfter := range []string{"0", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)} {
+		flaky := serveFlaky(t, failure{status: http.StatusServiceUnavailable, retryAfter: retryAfter})
+		start := time.Now()
+		if err := get(resty.NewAlbumsClient(flaky.URL, resty.WithRetry(policy))); err != nil {
+			t.Fatalf("Retry-After %s: Get() = %v", retryAfter, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Retry-After %s: Get() returned after %v, want a retry right away", retryAfter, elapsed)
+		}
+	}
+}
+
+// TestNoRetry checks that a zero policy disables the retries
+func TestNoRetry(t *testing.T) {
+	flaky := serveFlaky(t, failure{status: http.StatusServiceUnavailable})
+	if err := get(resty.NewAlbumsClient(flaky.URL, resty.WithRetry(resty.RetryPolicy{}))); statusOf(err) != http.StatusServiceUnavailable || flaky.Attempts() != 1 {
+		t.Fatalf("Get() = %v after %d attempts, want 503 after 1 attempt", err, flaky.Attempts())
+	}
+}
+
+// TestBackoff checks that the backoff grows exponentially up to its maximum, minus the jitter
+func TestBackoff(t *testing.T) {
+	policy := resty.RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Jitter: 0.5}
+	tests := []struct {
+		retry  int
+		random float64
+		want   time.Duration
+	}{
+		{1, 0, 100 * time.Millisecond},
+		{2, 0, 200 * time.Millisecond},
+		{3, 0, 400 * time.Millisecond},
+		{5, 0, time.Second},
+		{10, 0, time.Second},
+		{2, 0.5, 150 * time.Millisecond},
+		{2, 1, 100 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := policy.Backoff(test.retry, test.random); got != test.want {
+			t.Fatalf("Backoff(%d, %v) = %v, want %v", test.retry, test.random, got, test.want)
+		}
+	}
+}
+
+// breakerEvents records the hooks called by a circuit breaker
+type breakerEvents struct {
+	mu          sync.Mutex
+	transitions []string
+	rejected    int
+}
+
+func (e *breakerEvents) hooks() resty.BreakerHooks {
+	return resty.BreakerHooks{
+		OnStateChange: func(from resty.State, to resty.State) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			e.transitions = append(e.transitions, from.String()+" -> "+to.String())
+		},
+		OnRejected: func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			e.rejected++
+		},
+	}
+}
+
+// TestCircuitBreaker checks that the circuit opens after consecutive failures, and closes once a
+// probe succeeds
+func TestCircuitBreaker(t *testing.T) {
+	internal := failure{status: http.StatusInternalServerError}
+	flaky := serveFlaky(t, internal, internal)
+	events := &breakerEvents{}
+	breaker := resty.NewCircuitBreaker(resty.BreakerSettings{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		Hooks:            events.hooks(),
+	})
+	client := resty.NewAlbumsClient(flaky.URL, resty.WithRetry(resty.RetryPolicy{}), resty.WithCircuitBreaker(breaker))
+
+	for i := 0; i < 2; i++ {
+		if err := get(client); statusOf(err) != http.StatusInternalServerError {
+			t.Fatalf("Get() = %v, want 500", err)
+		}
+	}
+	if err := get(client); !errors.Is(err, resty.ErrCircuitOpen) || flaky.Attempts() != 2 {
+		t.Fatalf("Get() with an open circuit = %v after %d attempts, want ErrCircuitOpen after 2", err, flaky.Attempts())
+	}
+	if breaker.State() != resty.StateOpen {
+		t.Fatalf("State() = %v, want open", breaker.State())
+	}
+
+	// the failed probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	flaky.fail(internal)
+	if err := get(client); statusOf(err) != http.StatusInternalServerError {
+		t.Fatalf("Get() probe = %v, want 500", err)
+	}
+	if err := get(client); !errors.Is(err, resty.ErrCircuitOpen) {
+		t.Fatalf("Get() after a failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if breaker.State() != resty.StateHalfOpen {
+		t.Fatalf("State() after the timeout = %v, want half-open", breaker.State())
+	}
+	if err := get(client); err != nil {
+		t.Fatalf("Get() probe = %v", err)
+	}
+	if breaker.State() != resty.StateClosed {
+		t.Fatalf("State() after a probe = %v, want closed", breaker.State())
+	}
+
+	want := []string{
+		"closed -> open", "open -> half-open", "half-open -> open", "open -> half-open", "half-open -> closed",
+	}
+	if !reflect.DeepEqual(events.transitions, want) || events.rejected != 2 {
+		t.Fatalf("transitions %v and %d rejections, want %v and 2", events.transitions, events.rejected, want)
+	}
+}
+
+// TestCircuitBreakerStopsRetries checks that the retries stop once the circuit is open
+func TestCircuitBreakerStopsRetries(t *testing.T) {
+	unavailable := failure{status: http.StatusServiceUnavailable}
+	flaky := serveFlaky(t, unavailable, unavailable, unavailable, unavailable)
+	breaker := resty.NewCircuitBreaker(resty.BreakerSettings{
+		FailureThreshold: 2,
+		IsFailure: func(request *http.Request, response *http.Response, err error) bool {
+			return err != nil || response.StatusCode == http.StatusServiceUnavailable
+		},
+	})
+	client := resty.NewAlbumsClient(flaky.URL, resty.WithRetry(fastRetry), resty.WithCircuitBreaker(breaker))
+
+	if err := get(client); !errors.Is(err, resty.ErrCircuitOpen) || flaky.Attempts() != 2 {
+		t.Fatalf("Get() = %v after %d attempts, want ErrCircuitOpen after 2", err, flaky.Attempts())
+	}
+}
+
+// TestHalfOpenProbes checks that the half-open circuit only lets its probes through
+func TestHalfOpenProbes(t *testing.T) {
+	breaker := resty.NewCircuitBreaker(resty.BreakerSettings{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Millisecond,
+		HalfOpenRequests: 2,
+	})
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Allow() = %v", err)
+	}
+	done(resty.ResultFailure)
+	time.Sleep(5 * time.Millisecond)
+
+	first, err1 := breaker.Allow()
+	second, err2 := breaker.Allow()
+	if _, err := breaker.Allow(); err1 != nil || err2 != nil || !errors.Is(err, resty.ErrCircuitOpen) {
+		t.Fatalf("Allow() = %v, %v, %v, want two probes then ErrCircuitOpen", err1, err2, err)
+	}
+	first(resty.ResultSuccess)
+	if breaker.State() != resty.StateHalfOpen {
+		t.Fatalf("State() after a successful probe = %v, want half-open", breaker.State())
+	}
+	second(resty.ResultSuccess)
+	if breaker.State() != resty.StateClosed {
+		t.Fatalf("State() after the successful probes = %v, want closed", breaker.State())
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// TestHalfOpenCancelledProbe checks that a probe cancelled by the caller is not counted, and lets
+// another request probe the server
+func TestHalfOpenCancelledProbe(t *testing.T) {
+	events := &breakerEvents{}
+	breaker := resty.NewCircuitBreaker(resty.BreakerSettings{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Millisecond,
+		Hooks:            events.hooks(),
+	})
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Allow() = %v", err)
+	}
+	done(resty.ResultFailure)
+	time.Sleep(5 * time.Millisecond)
+
+	// the caller gives up while the probe is running
+	ctx, cancel := context.WithCancel(context.Background())
+	transport := breaker.RoundTripper(roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		cancel()
+		return nil, request.Context().Err()
+	}))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/albums/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transport.RoundTrip(request); !errors.Is(err, context.Canceled) {
+		t.Fatalf("RoundTrip() = %v, want context.Canceled", err)
+	}
+	if breaker.State() != resty.StateHalfOpen {
+		t.Fatalf("State() after a cancelled probe = %v, want half-open", breaker.State())
+	}
+	probe, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Allow() after a cancelled probe = %v, want a probe", err)
+	}
+	probe(resty.ResultSuccess)
+	want := []string{"closed -> open", "open -> half-open", "half-open -> closed"}
+	if breaker.State() != resty.StateClosed || !reflect.DeepEqual(events.transitions, want) {
+		t.Fatalf("State() = %v after %v, want closed after %v", breaker.State(), events.transitions, want)
+	}
+}
@@ -0,0 +1,60 @@
+package zap
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	zaplogger "golang_starter/internal/logger/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestContext checks that the fields added to a context are logged by its logger
+func TestContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := zaplogger.NewContext(context.Background(), zap.New(core))
+	ctx = zaplogger.WithFields(ctx, zap.String("request_id", "42"))
+	ctx = zaplogger.WithFields(ctx, zap.String("user", "frodo"))
+	zaplogger.FromContext(ctx).Info("album created")
+
+	fields := logs.All()[0].ContextMap()
+	if fields["request_id"] != "42" || fields["user"] != "frodo" {
+		t.Fatalf("FromContext().Info() fields = %v, want request_id and user", fields)
+	}
+
+	// without logger, the global one is used
+	global, globalLogs := observer.New(zap.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(global))()
+	zaplogger.FromContext(context.Background()).Info("global")
+	if globalLogs.Len() != 1 {
+		t.Fatalf("FromContext(no logger) logged %d entries with the global logger, want 1", globalLogs.Len())
+	}
+}
+
+// TestMiddleware checks the request fields of the logger of the requests
+func TestMiddleware(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	handler := zaplogger.Middleware(zap.New(core), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zaplogger.FromContext(r.Context()).Info("handled")
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/albums", nil)
+	request.Header.Set(zaplogger.RequestIDHeader, "abc")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	fields := logs.All()[0].ContextMap()
+	if fields["request_id"] != "abc" || fields["method"] != "GET" || fields["path"] != "/albums" {
+		t.Fatalf("Middleware() fields = %v, want the request id, method and path", fields)
+	}
+	if id := recorder.Header().Get(zaplogger.RequestIDHeader); id != "abc" {
+		t.Fatalf("Middleware() %s = %q, want abc", zaplogger.RequestIDHeader, id)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/albums", nil))
+	generated := recorder.Header().Get(zaplogger.RequestIDHeader)
+	if len(generated) != 16 || logs.All()[1].ContextMap()["request_id"] != generated {
+		t.Fatalf("Middleware() generated request id = %q, want 16 characters logged", generated)
+	}
+}
@@ -0,0 +1,203 @@
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	zaplogger "golang_starter/internal/logger/zap"
+	"golang_starter/internal/marshaller/viper/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fileConfig returns a configuration logging in a file of a temporary directory
+func fileConfig(t *testing.T) (zaplogger.Config, string) {
+	file := filepath.Join(t.TempDir(), "app.log")
+	conf := zaplogger.DefaultConfig()
+	conf.OutputPaths = []string{file}
+	return conf, file
+}
+
+// readEntries returns the JSON entries of a log file
+func readEntries(t *testing.T, file string) []map[string]interface{} {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("entry %q = %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// TestNew checks the level, the encoding and the outputs of a logger
+func TestNew(t *testing.T) {
+	conf, file := fileConfig(t)
+	logger, err := zaplogger.New(conf)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	logger.Debug("hidden")
+	logger.Info("album created", zap.Int("id", 7))
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	entries := readEntries(t, file)
+	if len(entries) != 1 {
+		t.Fatalf("New() entries = %v, want the info entry only", entries)
+	}
+	entry := entries[0]
+	if entry["msg"] != "album created" || entry["level"] != "info" || entry["id"] != 7.0 {
+		t.Fatalf("New() entry = %v, want the info entry with its id", entry)
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05.000Z0700", entry["ts"].(string)); err != nil {
+		t.Fatalf("New() ts = %v, want an ISO 8601 time", entry["ts"])
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "zap/logger_test.go") {
+		t.Fatalf("New() caller = %v, want the test", entry["caller"])
+	}
+}
+
+// TestNewErrors checks that the invalid configurations are reported
+func TestNewErrors(t *testing.T) {
+	tests := map[string]func(c *zaplogger.Config){
+		"level":       func(c *zaplogger.Config) { c.Level = "verbose" },
+		"encoding":    func(c *zaplogger.Config) { c.Encoding = "xml" },
+		"outputpaths": func(c *zaplogger.Config) { c.OutputPaths = nil },
+		"rotation":    func(c *zaplogger.Config) { c.Rotation.MaxSize = -1 },
+	}
+	for key, change := range tests {
+		conf := zaplogger.DefaultConfig()
+		change(&conf)
+		_, err := zaplogger.New(conf)
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) || !strings.HasPrefix(validationErr.Errors[0].Key, key) {
+			t.Errorf("New(invalid %s) = %v, want a validation error of %s", key, err, key)
+		}
+	}
+
+	conf := zaplogger.DefaultConfig()
+	conf.OutputPaths = []string{filepath.Join(t.TempDir(), "missing", "app.log")}
+	if _, err := zaplogger.New(conf); err == nil {
+		t.Errorf("New(file in a missing directory) = nil, want an error")
+	}
+}
+
+// TestSampling checks that the repeated entries are dropped
+func TestSampling(t *testing.T) {
+	conf, file := fileConfig(t)
+	conf.Sampling = zaplogger.SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Minute}
+	logger, err := zaplogger.New(conf)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		logger.Info("repeated")
+	}
+	logger.Close()
+	// the 2 first entries, then the 5th and the 8th
+	if entries := readEntries(t, file); len(entries) != 4 {
+		t.Fatalf("New(sampling 2, 3) logged %d entries out of 8, want 4", len(entries))
+	}
+}
+
+// TestLevelHandler checks that the level is changed by the HTTP handler
+func TestLevelHandler(t *testing.T) {
+	conf, _ := fileConfig(t)
+	logger, err := zaplogger.New(conf)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	defer logger.Close()
+	server := httptest.NewServer(logger.LevelHandler())
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"level":"debug"}`))
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || !logger.Core().Enabled(zapcore.DebugLevel) {
+		t.Fatalf("PUT level debug = %d, enabled %v, want the debug level", response.StatusCode, logger.Core().Enabled(zapcore.DebugLevel))
+	}
+
+	response, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var body struct{ Level string }
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil || body.Level != "debug" {
+		t.Fatalf("GET level = %+v, %v, want debug", body, err)
+	}
+}
+
+// TestRotation checks that the files are rotated by size and by time
+func TestRotation(t *testing.T) {
+	conf, file := fileConfig(t)
+	conf.Sampling.Initial = 0
+	conf.Rotation = zaplogger.RotationConfig{MaxSize: 1, MaxBackups: 2}
+	logger, err := zaplogger.New(conf)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	// 4 MB of entries
+	padding := strings.Repeat("x", 1024)
+	for i := 0; i < 4*1024; i++ {
+		logger.Info("padding", zap.String("padding", padding))
+	}
+	logger.Close()
+	// the older backups are removed in the background
+	var files []string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		files, _ = filepath.Glob(filepath.Join(filepath.Dir(file), "app*.log"))
+		if len(files) == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(files) != 3 {
+		t.Fatalf("rotation by size files = %v, want the file and 2 backups", files)
+	}
+	for _, name := range files {
+		if info, err := os.Stat(name); err != nil || info.Size() > 1024*1024 {
+			t.Fatalf("rotation by size file %s = %v, want at most 1 MB", name, info.Size())
+		}
+	}
+
+	conf, file = fileConfig(t)
+	// the interval rotates the files without MaxSize too
+	conf.Rotation = zaplogger.RotationConfig{Interval: 20 * time.Millisecond}
+	logger, err = zaplogger.New(conf)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	defer logger.Close()
+	logger.Info("before the rotation")
+	deadline = time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		files, _ = filepath.Glob(filepath.Join(filepath.Dir(file), "app-*.log"))
+		if len(files) > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("rotation every 20ms files = %v, want a backup", files)
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"github.com/spf13/pflag"
+	"golang_starter/internal/marshaller/viper/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaults is the default configuration of the tests
+var defaults = []byte(`
+name: demo
+server:
+  host: localhost
+  port: 8080
+  timeout: 5s
+tags: [a, b]
+`)
+
+type serverConfig struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
+type testConfig struct {
+	Name   string
+	Server serverConfig
+	Tags   []string
+	// Debug is not in the defaults
+	Debug bool
+	// Owner is read from the key "maintainer"
+	Owner string `mapstructure:"maintainer"`
+}
+
+// writeFile writes a configuration file in a temporary directory, and returns its path
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// TestLoadDefaults checks that the configuration defaults to the Defaults document
+func TestLoadDefaults(t *testing.T) {
+	var got testConfig
+	if err := (config.Loader{Defaults: defaults}).Load(&got); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	want := testConfig{Name: "demo", Server: serverConfig{"localhost", 8080, 5 * time.Second}, Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+
+	// the defaults can be in another format
+	if err := (config.Loader{Defaults: []byte(`{"name": "json"}`), DefaultsType: "json"}).Load(&got); err != nil || got.Name != "json" {
+		t.Fatalf("Load() with JSON defaults = %+v, %v, want the name json", got, err)
+	}
+}
+
+// TestLoadPrecedence checks that the flags override the environment, which overrides the file, which
+// overrides the defaults
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "app.toml", `
+name = "file"
+maintainer = "frodo"
+[server]
+host = "0.0.0.0"
+port = 9000
+`)
+	t.Setenv("TEST_SERVER_PORT", "9001")
+	t.Setenv("TEST_SERVER_HOST", "127.0.0.1")
+	t.Setenv("TEST_DEBUG", "true")
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("port", 0, "")
+	flags.String("name", "flag default", "")
+	flags.Duration("timeout", 0, "")
+	if err := flags.Parse([]string{"--port", "9002", "--timeout", "1m"}); err != nil {
+		t.Fatal(err)
+	}
+	loader := config.Loader{
+		Defaults:  defaults,
+		File:      file,
+		EnvPrefix: "TEST",
+		Flags:     flags,
+		FlagKeys:  map[string]string{"port": "server.port", "timeout": "server.timeout"},
+	}
+
+	var got testConfig
+	if err := loader.Load(&got); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"flag over environment and file", got.Server.Port, 9002},
+		{"flag over default", got.Server.Timeout, time.Minute},
+		{"environment over file", got.Server.Host, "127.0.0.1"},
+		{"environment without default", got.Debug, true},
+		{"file over default", got.Name, "file"},
+		{"file with tag", got.Owner, "frodo"},
+		{"default", got.Tags, []string{"a", "b"}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+// TestLoadErrors checks that the errors of every layer are returned
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		loader  config.Loader
+		wantErr string
+	}{
+		{"invalid defaults", config.Loader{Defaults: []byte("name: [")}, "default configuration"},
+		{"missing file", config.Loader{Defaults: defaults, File: filepath.Join(t.TempDir(), "missing.yaml")}, "missing.yaml"},
+		{"malformed file", config.Loader{Defaults: defaults, File: writeFile(t, "app.yaml", "server: [")}, "app.yaml"},
+		{"unknown format", config.Loader{Defaults: defaults, File: writeFile(t, "app.txt", "name: text")}, "app.txt"},
+		{"invalid value", config.Loader{Defaults: defaults, File: writeFile(t, "app.yaml", "server:\n  port: eighty")}, "invalid configuration"},
+	}
+	for _, test := range tests {
+		var got testConfig
+		if err := test.loader.Load(&got); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: Load() = %v, want an error with %q", test.name, err, test.wantErr)
+		}
+	}
+}
+
+// TestKeys checks the keys of the struct fields
+func TestKeys(t *testing.T) {
+	type Base struct {
+		Name   string
+		Server serverConfig
+	}
+	type log struct {
+		Level string
+	}
+	type target struct {
+		Base    `mapstructure:",squash"`
+		Log     *log
+		Since   time.Time
+		Ignored string `mapstructure:"-"`
+		Owner   string `mapstructure:"maintainer,omitempty"`
+		private string
+	}
+	want := []string{"name", "server.host", "server.port", "server.timeout", "log.level", "since", "maintainer"}
+	if got := config.Keys(&target{}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"golang_starter/internal/marshaller/viper/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const convertInput = `version: 1.0.0
+server:
+  host: localhost
+  port: 8080
+  timeout: 10s
+origins:
+  - http://localhost
+  - http://albums
+password: ${env:DB_PASSWORD}
+`
+
+// TestConvert checks that the conversions keep the values, by converting them back to yaml
+func TestConvert(t *testing.T) {
+	var want bytes.Buffer
+	if err := config.Convert(&want, strings.NewReader(convertInput), "yaml", "yaml", ""); err != nil {
+		t.Fatalf("Convert(yaml, yaml) = %v", err)
+	}
+	for _, format := range []string{"json", "toml"} {
+		var converted, back bytes.Buffer
+		if err := config.Convert(&converted, strings.NewReader(convertInput), "yaml", format, ""); err != nil {
+			t.Fatalf("Convert(yaml, %s) = %v", format, err)
+		}
+		if err := config.Convert(&back, &converted, format, "yaml", ""); err != nil {
+			t.Fatalf("Convert(%s, yaml) = %v", format, err)
+		}
+		if back.String() != want.String() {
+			t.Errorf("Convert(yaml, %s) then back = %q, want %q", format, back.String(), want.String())
+		}
+	}
+
+	if err := config.Convert(&bytes.Buffer{}, strings.NewReader(convertInput), "yaml", "xml", ""); err == nil {
+		t.Errorf("Convert(yaml, xml) = nil, want an error")
+	}
+	if err := config.Convert(&bytes.Buffer{}, strings.NewReader("version: [1"), "yaml", "json", ""); err == nil {
+		t.Errorf("Convert(invalid yaml, json) = nil, want an error")
+	}
+}
+
+// TestConvertEnv checks that the environment variables are the ones read by the loader
+func TestConvertEnv(t *testing.T) {
+	var out bytes.Buffer
+	if err := config.Convert(&out, strings.NewReader(convertInput+"name: Frodo Baggins\nquote: \"it's $HOME\"\n"), "yaml", "env", "APP"); err != nil {
+		t.Fatalf("Convert(yaml, env) = %v", err)
+	}
+	want := `APP_NAME='Frodo Baggins'
+APP_ORIGINS=http://localhost,http://albums
+APP_PASSWORD='${env:DB_PASSWORD}'
+APP_QUOTE='it'\''s $HOME'
+APP_SERVER_HOST=localhost
+APP_SERVER_PORT=8080
+APP_SERVER_TIMEOUT=10s
+APP_VERSION=1.0.0
+`
+	if out.String() != want {
+		t.Fatalf("Convert(yaml, env) = %q, want %q", out.String(), want)
+	}
+
+	// the loader reads the same values from the environment
+	for _, line := range strings.Split(strings.TrimSpace(want), "\n") {
+		name, value, _ := strings.Cut(line, "=")
+		t.Setenv(name, strings.ReplaceAll(strings.Trim(value, "'"), `'\''`, "'"))
+	}
+	type server struct {
+		Host    string
+		Port    int
+		Timeout string
+	}
+	var loaded struct {
+		Version string
+		Name    string
+		Quote   string
+		Server  server
+		Origins []string
+	}
+	if err := (config.Loader{Defaults: []byte("{}")}).Load(&loaded); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if loaded.Server.Port != 8080 || loaded.Name != "Frodo Baggins" || loaded.Quote != "it's $HOME" || !reflect.DeepEqual(loaded.Origins, []string{"http://localhost", "http://albums"}) {
+		t.Fatalf("Load() = %+v, want the values of the environment", loaded)
+	}
+
+	err := config.Convert(&bytes.Buffer{}, strings.NewReader("people:\n  - name: Frodo\n"), "yaml", "env", "APP")
+	if err == nil || !strings.Contains(err.Error(), "people") {
+		t.Fatalf("Convert(list of objects, env) = %v, want an error about people", err)
+	}
+}
+
+// TestConvertDottedKeys checks that the keys holding a dot are refused, rather than split into
+// nested keys
+func TestConvertDottedKeys(t *testing.T) {
+	for _, input := range []string{"server.port: 8080\n", "server:\n  tls.cert: cert.pem\n"} {
+		for _, format := range []string{"json", "env"} {
+			err := config.Convert(&bytes.Buffer{}, strings.NewReader(input), "yaml", format, "APP")
+			if err == nil || !strings.Contains(err.Error(), "dot") {
+				t.Fatalf("Convert(%q, %s) = %v, want an error about the dot", input, format, err)
+			}
+		}
+	}
+}
+
+// TestConvertCommand checks the format detection of the convert command
+func TestConvertCommand(t *testing.T) {
+	file := writeFile(t, "app.yaml", "version: 1.0.0\n")
+	run := func(input string, args ...string) (string, error) {
+		cmd := config.NewCommand(config.Loader{Defaults: []byte("{}")}, func() interface{} { return &struct{}{} })
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if out, err := run("", "convert", file, "--to", "toml"); err != nil || out != "version = '1.0.0'\n" {
+		t.Fatalf("config convert %s --to toml = %q, %v, want the toml", file, out, err)
+	}
+	if out, err := run("version: 1.0.0\n", "convert", "--from", "yaml", "--to", "env", "--env-prefix", "TEST"); err != nil || out != "TEST_VERSION=1.0.0\n" {
+		t.Fatalf("config convert --from yaml --to env = %q, %v, want TEST_VERSION", out, err)
+	}
+	if _, err := run("version: 1.0.0\n", "convert", "--to", "json"); err == nil {
+		t.Fatalf("config convert --to json = nil, want an error without --from")
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"golang_starter/internal/api/grpc/go-grpc/route-guide/server"
+	"golang_starter/internal/api/rest/gin"
+	"golang_starter/internal/marshaller/viper"
+	"golang_starter/internal/marshaller/viper/config"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+)
+
+type schemaServer struct {
+	Host    string `validate:"required"`
+	Port    int    `validate:"min=1,max=65535"`
+	Timeout time.Duration
+}
+
+type schemaConfig struct {
+	Version  string `validate:"semver"`
+	LogLevel string `validate:"oneof=debug info"`
+	Name     string `validate:"regexp=^[a-z]+$,max=8"`
+	Server   schemaServer
+	Origins  []string `validate:"min=1,dive,gt=0"`
+	APIToken config.Secret
+	Database string            `mapstructure:"dbPath"`
+	Labels   map[string]string `validate:"lt=3"`
+	Retries  uint
+	Ignored  string `mapstructure:"-"`
+}
+
+// TestSchema checks the properties of a schema and the translation of the validate rules
+func TestSchema(t *testing.T) {
+	schema := config.NewSchema(&schemaConfig{}, "test")
+	if schema.Schema != config.SchemaVersion || schema.Title != "test" || schema.Type != "object" || schema.AdditionalProperties != false {
+		t.Fatalf("NewSchema() = %+v, want a closed object with the version and the title", schema)
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	wantNames := []string{"apiToken", "dbPath", "labels", "logLevel", "name", "origins", "retries", "server", "version"}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("NewSchema() properties = %v, want %v", names, wantNames)
+	}
+
+	properties := schema.Properties
+	if properties["logLevel"].Type != "string" || !reflect.DeepEqual(properties["logLevel"].Enum, []interface{}{"debug", "info"}) {
+		t.Errorf("logLevel = %+v, want the enum debug, info", properties["logLevel"])
+	}
+	if pattern := properties["version"].Pattern; !regexp.MustCompile(pattern).MatchString("1.2.3-rc.1") || regexp.MustCompile(pattern).MatchString("v1.2") {
+		t.Errorf("version pattern = %s, want a semantic version pattern", pattern)
+	}
+	if name := properties["name"]; name.Pattern != "^[a-z]+$" || name.MaxLength == nil || *name.MaxLength != 8 {
+		t.Errorf("name = %+v, want the pattern and a max length of 8", name)
+	}
+	port := properties["server"].Properties["port"]
+	if port.Type != "integer" || port.Minimum == nil || *port.Minimum != 1 || port.Maximum == nil || *port.Maximum != 65535 {
+		t.Errorf("server.port = %+v, want an integer between 1 and 65535", port)
+	}
+	if host := properties["server"].Properties["host"]; host.Type != "string" || host.MinLength != nil {
+		t.Errorf("server.host = %+v, want a string without length, required is not translated", host)
+	}
+	if timeout := properties["server"].Properties["timeout"]; timeout.Type != "string" || !regexp.MustCompile(timeout.Pattern).MatchString("1m30s") {
+		t.Errorf("server.timeout = %+v, want a duration string", timeout)
+	}
+	origins := properties["origins"]
+	if origins.Type != "array" || origins.MinItems == nil || *origins.MinItems != 1 || origins.Items.MinLength == nil || *origins.Items.MinLength != 1 {
+		t.Errorf("origins = %+v, want at least 1 non empty string", origins)
+	}
+	if labels := properties["labels"]; labels.Type != "object" || labels.AdditionalProperties == nil {
+		t.Errorf("labels = %+v, want an object of strings", labels)
+	}
+	if retries := properties["retries"]; retries.Type != "integer" || retries.Minimum == nil || *retries.Minimum != 0 {
+		t.Errorf("retries = %+v, want a positive integer", retries)
+	}
+	if token := properties["apiToken"]; token.Type != "string" {
+		t.Errorf("apiToken = %+v, want a string", token)
+	}
+}
+
+// confDir holds the configuration files of the programs and their schemas
+var confDir = filepath.Join("..", "..", "..", "..", "..", "res", "conf")
+
+// TestSchemaFiles checks that the schemas of res/conf are up to date, run
+// go run ./cmd/marshaller/config-schemas otherwise, and that they describe the keys of the files
+func TestSchemaFiles(t *testing.T) {
+	targets := map[string]interface{}{
+		"viper":              &viper.Config{},
+		"gin":                &gin.Config{},
+		"route-guide-server": &server.Config{},
+	}
+	for name, target := range targets {
+		committed, err := os.ReadFile(filepath.Join(confDir, name+".schema.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema config.Schema
+		if err := json.Unmarshal(committed, &schema); err != nil {
+			t.Fatalf("%s.schema.json = %v", name, err)
+		}
+		var generated bytes.Buffer
+		if err := config.WriteSchema(&generated, target, schema.Title); err != nil {
+			t.Fatal(err)
+		}
+		if generated.String() != string(committed) {
+			t.Errorf("%s.schema.json is not up to date, run go run ./cmd/marshaller/config-schemas", name)
+		}
+
+		content, err := os.ReadFile(filepath.Join(confDir, name+".yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values interface{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			t.Fatal(err)
+		}
+		checkKeys(t, name+".yaml", values, config.NewSchema(target, ""))
+	}
+}
+
+// checkKeys checks that the keys of a document are properties of its schema
+func checkKeys(t *testing.T, path string, value interface{}, schema *config.Schema) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			property, found := schema.Properties[key]
+			if !found {
+				if additional, ok := schema.AdditionalProperties.(*config.Schema); ok {
+					property, found = additional, true
+				}
+			}
+			if !found {
+				t.Errorf("%s.%s is not in the schema", path, key)
+				continue
+			}
+			checkKeys(t, path+"."+key, nested, property)
+		}
+	case []interface{}:
+		for _, item := range value {
+			if schema.Items == nil {
+				t.Errorf("%s is not a list in the schema", path)
+				return
+			}
+			checkKeys(t, path+"[]", item, schema.Items)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"github.com/spf13/pflag"
+	"golang_starter/internal/marshaller/viper/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type member struct {
+	Name string `validate:"required,regexp=^[A-Z][a-z]+$"`
+	Role string `validate:"oneof=owner reader"`
+}
+
+type rulesConfig struct {
+	Version string   `validate:"required,semver"`
+	Port    int      `validate:"min=1,max=65535"`
+	Title   string   `mapstructure:"heading" validate:"max=5"`
+	Members []member `validate:"min=1,dive"`
+}
+
+// rulesDefaults is a valid configuration
+var rulesDefaults = []byte(`version: 1.0.0
+port: 8080
+heading: demo
+members:
+  - name: Frodo
+    role: owner
+`)
+
+// fieldErrors returns the keys and messages of a *ValidationError
+func fieldErrors(t *testing.T, err error) map[string]*config.FieldError {
+	t.Helper()
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+	errs := make(map[string]*config.FieldError)
+	for _, fieldErr := range validationErr.Errors {
+		errs[fieldErr.Key] = fieldErr
+	}
+	return errs
+}
+
+// TestValidate checks the messages of the rules
+func TestValidate(t *testing.T) {
+	valid := rulesConfig{Version: "1.2.3-rc.1", Port: 80, Title: "abc", Members: []member{{"Sam", "reader"}}}
+	if err := config.Validate(&valid); err != nil {
+		t.Fatalf("Validate(%+v) = %v, want nil", valid, err)
+	}
+
+	invalid := rulesConfig{Version: "v1", Port: 70000, Title: "too long", Members: []member{{"sam", "admin"}, {}}}
+	want := map[string]string{
+		"version":         `is "v1", must be a semantic version like 1.2.3`,
+		"port":            "must be at most 65535, not 70000",
+		"heading":         "must have a length of at most 5, not 8",
+		"members[0].name": `is "sam", must match ^[A-Z][a-z]+$`,
+		"members[0].role": `is "admin", must be one of: owner, reader`,
+		"members[1].name": "is required",
+		"members[1].role": `is "", must be one of: owner, reader`,
+	}
+	got := make(map[string]string)
+	for key, fieldErr := range fieldErrors(t, config.Validate(&invalid)) {
+		got[key] = fieldErr.Message
+		if fieldErr.Error() != key+" "+fieldErr.Message {
+			t.Errorf("Error() = %q, want the key and the message without origin", fieldErr.Error())
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Validate(%+v) = %v, want %v", invalid, got, want)
+	}
+
+	if errs := fieldErrors(t, config.Validate(&rulesConfig{Version: "1.0.0", Port: 1})); errs["members"].Message != "must have a length of at least 1, not 0" {
+		t.Fatalf("Validate() without members = %v, want a length error", errs["members"])
+	}
+}
+
+// TestLoadOrigins checks that the invalid values are reported with the line of the file, or the
+// layer, setting them
+func TestLoadOrigins(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		wantOrigins         map[string]string
+	}{
+		{"yaml", "app.yaml", "version: 1.0\nmembers:\n  - name: Frodo\n    role: owner\n  - name: sam\n",
+			map[string]string{"version": "app.yaml:1", "members[1].name": "app.yaml:5", "members[1].role": "app.yaml:5"}},
+		{"json", "app.json", "{\n  \"port\": 0,\n  \"members\": []\n}\n",
+			map[string]string{"port": "app.json:2", "members": "app.json:3"}},
+		{"toml", "app.toml", "heading = \"too long\"\n\n[[members]]\nname = \"Frodo\"\nrole = \"owner\"\n\n[[members]]\nname = \"Sam\"\nrole = \"cook\"\n",
+			map[string]string{"heading": "app.toml:1", "members[1].role": "app.toml:9"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeFile(t, test.file, test.content)
+			err := config.Loader{Defaults: rulesDefaults, File: file}.Load(&rulesConfig{})
+			errs := fieldErrors(t, err)
+			if len(errs) != len(test.wantOrigins) {
+				t.Fatalf("Load() = %v, want errors for %v", err, test.wantOrigins)
+			}
+			for key, want := range test.wantOrigins {
+				if errs[key] == nil || errs[key].Origin.Layer != config.LayerFile || !strings.HasSuffix(errs[key].Origin.String(), want) {
+					t.Errorf("Load() error of %s = %v, want the origin %s", key, errs[key], want)
+				}
+			}
+			if !strings.HasPrefix(err.Error(), file+":") {
+				t.Errorf("Load() = %q, want errors starting with the file name", err)
+			}
+		})
+	}
+
+	// the other layers
+	t.Setenv("APP_VERSION", "latest")
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("port", 0, "")
+	if err := flags.Parse([]string{"--port", "-1"}); err != nil {
+		t.Fatal(err)
+	}
+	defaults := []byte("version: 1.0.0\nport: 8080\nheading: too long\n")
+	errs := fieldErrors(t, config.Loader{Defaults: defaults, Flags: flags}.Load(&rulesConfig{}))
+	want := map[string]string{"version": "$APP_VERSION", "port": "--port", "heading": "defaults:3", "members": ""}
+	for key, origin := range want {
+		if errs[key] == nil || errs[key].Origin.String() != origin {
+			t.Errorf("Load() error of %s = %v, want the origin %q", key, errs[key], origin)
+		}
+	}
+	if errs["members"].Error() != "members must have a length of at least 1, not 0" {
+		t.Errorf("Load() error of an unset key = %q, want no origin", errs["members"].Error())
+	}
+}
+
+// TestValidateCommand checks the output of config validate
+func TestValidateCommand(t *testing.T) {
+	valid := writeFile(t, "valid.yaml", "version: 2.0.0\n")
+	invalid := writeFile(t, "invalid.yaml", "version: 2.0.0\nport: 0\n")
+	t.Setenv("APP_PORT", "0")
+	newTarget := func() interface{} { return &rulesConfig{} }
+
+	run := func(args ...string) (string, error) {
+		cmd := config.NewCommand(config.Loader{Defaults: rulesDefaults}, newTarget)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	if out, err := run("validate", valid); err != nil || !strings.Contains(out, "is valid") {
+		t.Fatalf("config validate valid.yaml = %q, %v, want valid", out, err)
+	}
+	if out, err := run("validate", invalid); err == nil || !strings.Contains(out, "invalid.yaml:2: port must be at least 1, not 0") {
+		t.Fatalf("config validate invalid.yaml = %q, %v, want the error of port", out, err)
+	}
+	if out, err := run("validate", "--env", valid); err == nil || !strings.Contains(out, "$APP_PORT: port") {
+		t.Fatalf("config validate --env valid.yaml = %q, %v, want the error of APP_PORT", out, err)
+	}
+	if _, err := run("validate", valid+".missing"); err == nil {
+		t.Fatalf("config validate of a missing file = nil, want an error")
+	}
+}
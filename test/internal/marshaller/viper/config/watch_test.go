@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"golang_starter/internal/marshaller/viper/config"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type watchedConfig struct {
+	Level string `validate:"oneof=debug info"`
+}
+
+// rewrite replaces the content of a configuration file
+func rewrite(t *testing.T, file string, content string) {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestReload checks that only the valid changes replace the active configuration, and are given to
+// the subscribers
+func TestReload(t *testing.T) {
+	file := writeFile(t, "app.yaml", "level: info\n")
+	loader := config.Loader{Defaults: []byte("level: debug\n"), File: file}
+	watcher, err := config.NewWatcher[watchedConfig](loader)
+	if err != nil {
+		t.Fatalf("NewWatcher() = %v", err)
+	}
+	if watcher.Current().Level != "info" {
+		t.Fatalf("Current() = %+v, want the level of the file", watcher.Current())
+	}
+
+	var calls []string
+	watcher.Subscribe(func(previous *watchedConfig, current *watchedConfig) {
+		calls = append(calls, "first "+previous.Level+" -> "+current.Level)
+	})
+	cancel := watcher.Subscribe(func(previous *watchedConfig, current *watchedConfig) {
+		calls = append(calls, "second "+previous.Level+" -> "+current.Level)
+	})
+	changes, _ := watcher.Changes()
+
+	rewrite(t, file, "level: debug\n")
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if want := []string{"first info -> debug", "second info -> debug"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("Reload() calls = %v, want %v", calls, want)
+	}
+
+	// an invalid configuration is not applied
+	rewrite(t, file, "level: loud\n")
+	if err := watcher.Reload(); err == nil || !strings.Contains(err.Error(), "app.yaml:1") {
+		t.Fatalf("Reload() of an invalid file = %v, want the error of its line 1", err)
+	}
+	// an unchanged configuration is not notified
+	rewrite(t, file, "# same level\nlevel: debug\n")
+	cancel()
+	rewrite(t, file, "level: info\n")
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	if watcher.Current().Level != "info" || len(calls) != 3 || calls[2] != "first debug -> info" {
+		t.Fatalf("Reload() calls = %v, want a call of the first subscriber only", calls)
+	}
+
+	// the channel holds the last change
+	if current := <-changes; current.Level != "info" {
+		t.Fatalf("Changes() = %+v, want the last change", current)
+	}
+
+	rewrite(t, file, "level: loud\n")
+	if _, err := config.NewWatcher[watchedConfig](loader); err == nil {
+		t.Fatalf("NewWatcher() of an invalid file = nil, want an error")
+	}
+}
+
+// TestWatch checks that the changes of the file are applied until the context is done
+func TestWatch(t *testing.T) {
+	file := writeFile(t, "app.yaml", "level: info\n")
+	watcher, err := config.NewWatcher[watchedConfig](config.Loader{Defaults: []byte("level: debug\n"), File: file})
+	if err != nil {
+		t.Fatalf("NewWatcher() = %v", err)
+	}
+	changes, _ := watcher.Changes()
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watcher.Watch(ctx, func(err error) { errs <- err })
+	}()
+	// let the watch start
+	time.Sleep(100 * time.Millisecond)
+
+	rewrite(t, file, "level: loud\n")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "loud") {
+			t.Fatalf("Watch() error = %v, want the invalid level", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch() has not reloaded the invalid file")
+	}
+
+	rewrite(t, file, "level: debug\n")
+	select {
+	case current := <-changes:
+		if current.Level != "debug" || watcher.Current().Level != "debug" {
+			t.Fatalf("Watch() change = %+v, want the debug level", current)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch() has not reloaded the file")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() = %v, want nil once the context is done", err)
+	}
+
+	noFile, _ := config.NewWatcher[watchedConfig](config.Loader{Defaults: []byte("level: debug\n")})
+	if err := noFile.Watch(context.Background(), nil); err == nil {
+		t.Fatalf("Watch() without file = nil, want an error")
+	}
+}
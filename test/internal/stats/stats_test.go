@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"golang_starter/internal/stats"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPercentile checks the nearest-rank percentiles
+func TestPercentile(t *testing.T) {
+	// 1ms to 100ms
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{latencies, 50, 50 * time.Millisecond},
+		{latencies, 95, 95 * time.Millisecond},
+		{latencies, 99, 99 * time.Millisecond},
+		{latencies, 100, 100 * time.Millisecond},
+		{latencies, 0, time.Millisecond},
+		{[]time.Duration{time.Second}, 99, time.Second},
+		{[]time.Duration{time.Millisecond, time.Second}, 50, time.Millisecond},
+		{nil, 50, 0},
+	}
+	for _, test := range tests {
+		if got := stats.Percentile(test.sorted, test.p); got != test.want {
+			t.Fatalf("Percentile(%d latencies, %v) = %v, want %v", len(test.sorted), test.p, got, test.want)
+		}
+	}
+}
+
+// TestSummarize checks the statistics of unsorted latencies
+func TestSummarize(t *testing.T) {
+	latencies := []time.Duration{4 * time.Millisecond, time.Millisecond, 3 * time.Millisecond, 2 * time.Millisecond}
+	got := stats.Summarize(latencies)
+	want := stats.Summary{
+		Count: 4,
+		Min:   time.Millisecond,
+		Mean:  2500 * time.Microsecond,
+		Max:   4 * time.Millisecond,
+		P50:   2 * time.Millisecond,
+		P95:   4 * time.Millisecond,
+		P99:   4 * time.Millisecond,
+	}
+	if got != want {
+		t.Fatalf("Summarize(%v) = %+v, want %+v", latencies, got, want)
+	}
+	if got := stats.Summarize(nil); got != (stats.Summary{}) {
+		t.Fatalf("Summarize(nil) = %+v, want zero", got)
+	}
+}
+
+// TestRecorder checks that the recorder counts the calls and the errors of concurrent goroutines
+func TestRecorder(t *testing.T) {
+	recorder := &stats.Recorder{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%5 == 0 {
+				err = errors.New("failed")
+			}
+			recorder.Record(time.Duration(i+1)*time.Millisecond, err)
+		}(i)
+	}
+	wg.Wait()
+	summary := recorder.Summary()
+	if summary.Count != 10 || summary.Errors != 2 || summary.Max != 10*time.Millisecond {
+		t.Fatalf("Summary() = %+v, want 10 calls, 2 errors, max 10ms", summary)
+	}
+}
+
+// TestSummaryJSON checks that the latencies are written in milliseconds
+func TestSummaryJSON(t *testing.T) {
+	data, err := json.Marshal(stats.Summary{Count: 2, Errors: 1, P50: 1500 * time.Microsecond})
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	var got map[string]float64
+	json.Unmarshal(data, &got)
+	if got["count"] != 2 || got["errors"] != 1 || got["p50Ms"] != 1.5 {
+		t.Fatalf("json.Marshal() = %s, want p50Ms 1.5", data)
+	}
+}
@@ -0,0 +1,360 @@
+package tracing
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang_starter/internal/api/grpc/go-grpc/greeter"
+	"golang_starter/internal/api/grpc/go-grpc/greeter/server"
+	"golang_starter/internal/api/grpc/go-grpc/grpctest"
+	"golang_starter/internal/tracing"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func init() {
+	// do not print the routes and the requests
+	gin.SetMode(gin.TestMode)
+}
+
+// record installs a tracer provider keeping the ended spans in memory, and the propagators
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.NoExporter}); err != nil {
+		t.Fatalf("Setup() = %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewTracerProvider("test", sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// find returns the ended span with the given name
+func find(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	var names []string
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+		names = append(names, span.Name())
+	}
+	t.Fatalf("span %q not found in %v", name, names)
+	return nil
+}
+
+// intAttribute returns the value of an int attribute of the span, or -1
+func intAttribute(span sdktrace.ReadOnlySpan, key string) int64 {
+	for _, attribute := range span.Attributes() {
+		if string(attribute.Key) == key {
+			return attribute.Value.AsInt64()
+		}
+	}
+	return -1
+}
+
+// TestMiddleware checks that the gin middleware continues the trace of the traceparent header
+func TestMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.GET("/albums/:id", func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus codes.Code
+	}{
+		{"/albums/1", codes.Unset},
+		{"/albums/0", codes.Error},
+	}
+	for _, test := range tests {
+		recorder := record(t)
+		request := httptest.NewRequest(http.MethodGet, test.path, nil)
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		span := find(t, recorder, "GET /albums/:id")
+		if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Fatalf("GET %s trace id = %s, want the traceparent one", test.path, got)
+		}
+		if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+			t.Fatalf("GET %s parent span = %s, want the traceparent one", test.path, got)
+		}
+		if span.SpanKind() != trace.SpanKindServer || span.Status().Code != test.wantStatus {
+			t.Fatalf("GET %s span = %v %v, want a server span with status %v", test.path, span.SpanKind(), span.Status(), test.wantStatus)
+		}
+	}
+}
+
+// TestGRPC checks that the interceptors trace every kind of rpc method, and propagate the trace
+// from the client to the server
+func TestGRPC(t *testing.T) {
+	recorder := record(t)
+	s := grpctest.Start(t, server.Register,
+		grpctest.WithServerOptions(tracing.ServerOptions()...),
+		grpctest.WithDialOptions(tracing.DialOptions()...))
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "root")
+	defer root.End()
+
+	// unary
+	if _, err := greeter.NewGreeterClient(s.Conn).SayHello(ctx, &greeter.HelloRequest{Name: "Gladys"}); err != nil {
+		t.Fatalf("SayHello() = %v", err)
+	}
+	// server streaming, ended by the server
+	replies, err := greeter.NewGreeterServerStreamClient(s.Conn).LotsOfReplies(ctx, &greeter.HelloRequest{Name: "Gladys"})
+	if err != nil {
+		t.Fatalf("LotsOfReplies() = %v", err)
+	}
+	for err == nil {
+		_, err = replies.Recv()
+	}
+	// client streaming, ended by the single answer
+	greetings, err := greeter.NewGreeterClientStreamClient(s.Conn).LotsOfGreetings(ctx)
+	if err != nil {
+		t.Fatalf("LotsOfGreetings() = %v", err)
+	}
+	greetings.Send(&greeter.HelloRequest{Name: "Gladys"})
+	if _, err := greetings.CloseAndRecv(); err != nil {
+		t.Fatalf("CloseAndRecv() = %v", err)
+	}
+	// bidirectional streaming, ended by the cancellation
+	bidiCtx, cancel := context.WithCancel(ctx)
+	bidi, err := greeter.NewGreeterBidirectionalStreamClient(s.Conn).BidiHello(bidiCtx)
+	if err != nil {
+		t.Fatalf("BidiHello() = %v", err)
+	}
+	bidi.Send(&greeter.HelloRequest{Name: "Gladys"})
+	bidi.Recv()
+	cancel()
+	bidi.Recv()
+
+	methods := []string{"Greeter/SayHello", "GreeterServerStream/LotsOfReplies", "GreeterClientStream/LotsOfGreetings", "GreeterBidirectionalStream/BidiHello"}
+	for _, method := range methods {
+		name := "main." + method
+		// the server span ends after the client one: wait for it
+		var serverSpan sdktrace.ReadOnlySpan
+		for deadline := time.Now().Add(5 * time.Second); serverSpan == nil; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s server span not ended", name)
+			}
+			for _, span := range recorder.Ended() {
+				if span.Name() == name && span.SpanKind() == trace.SpanKindServer {
+					serverSpan = span
+				}
+			}
+		}
+		var clientSpan sdktrace.ReadOnlySpan
+		for _, span := range recorder.Ended() {
+			if span.Name() == name && span.SpanKind() == trace.SpanKindClient {
+				clientSpan = span
+			}
+		}
+		if clientSpan == nil {
+			t.Fatalf("%s client span not ended", name)
+		}
+		if clientSpan.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Fatalf("%s client span parent = %v, want the root span", name, clientSpan.Parent().SpanID())
+		}
+		if serverSpan.Parent().SpanID() != clientSpan.SpanContext().SpanID() || serverSpan.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Fatalf("%s server span parent = %v, want the client span", name, serverSpan.Parent())
+		}
+	}
+
+	// the errors are recorded with their gRPC code
+	recorder = record(t)
+	greeter.NewGreeterClient(s.Conn).SayHello(ctx, &greeter.HelloRequest{})
+	span := find(t, recorder, "main.Greeter/SayHello")
+	if span.Status().Code != codes.Error || intAttribute(span, string(semconv.RPCGRPCStatusCodeKey)) != 3 {
+		t.Fatalf("SayHello(\"\") span = %v, %v, want an InvalidArgument error", span.Status(), span.Attributes())
+	}
+}
+
+// TestGRPCConnectionClosed checks that the span of a stream which is not drained ends when its
+// connection is closed
+func TestGRPCConnectionClosed(t *testing.T) {
+	recorder := record(t)
+	s := grpctest.Start(t, server.Register,
+		grpctest.WithServerOptions(tracing.ServerOptions()...),
+		grpctest.WithDialOptions(tracing.DialOptions()...))
+	connection := s.Dial()
+	bidi, err := greeter.NewGreeterBidirectionalStreamClient(connection).BidiHello(context.Background())
+	if err != nil {
+		t.Fatalf("BidiHello() = %v", err)
+	}
+	bidi.Send(&greeter.HelloRequest{Name: "Gladys"})
+	bidi.Recv()
+	connection.Close()
+
+	name := "main.GreeterBidirectionalStream/BidiHello"
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		for _, span := range recorder.Ended() {
+			if span.Name() == name && span.SpanKind() == trace.SpanKindClient {
+				if span.Status().Code != codes.Error || intAttribute(span, string(semconv.RPCGRPCStatusCodeKey)) != 1 {
+					t.Fatalf("BidiHello() span = %v, %v, want a Canceled error", span.Status(), span.Attributes())
+				}
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s client span not ended", name)
+		}
+	}
+}
+
+// TestResty checks that the resty hooks send the trace context to the server, and trace the retries
+func TestResty(t *testing.T) {
+	recorder := record(t)
+	var mu sync.Mutex
+	calls := 0
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.GET("/albums", func(c *gin.Context) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	client := tracing.InstrumentResty(resty.New()).
+		SetRetryCount(1).
+		AddRetryCondition(func(response *resty.Response, err error) bool {
+			return response.StatusCode() == http.StatusServiceUnavailable
+		})
+	ctx, root := otel.Tracer("test").Start(context.Background(), "root")
+	response, err := client.R().SetContext(ctx).Get(httpServer.URL + "/albums")
+	root.End()
+	if err != nil || response.StatusCode() != http.StatusOK {
+		t.Fatalf("GET /albums = %v, %v, want 200 after a retry", response, err)
+	}
+
+	var attempts, served []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "HTTP GET":
+			attempts = append(attempts, span)
+		case "GET /albums":
+			served = append(served, span)
+		}
+	}
+	if len(attempts) != 2 || len(served) != 2 {
+		t.Fatalf("GET /albums = %d client spans and %d server spans, want 2 attempts", len(attempts), len(served))
+	}
+	for i, attempt := range attempts {
+		if attempt.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Fatalf("attempt %d parent = %v, want the root span", i, attempt.Parent().SpanID())
+		}
+		if served[i].Parent().SpanID() != attempt.SpanContext().SpanID() {
+			t.Fatalf("server span %d parent = %v, want the attempt", i, served[i].Parent().SpanID())
+		}
+	}
+	if attempts[0].Status().Code != codes.Error || attempts[1].Status().Code != codes.Unset {
+		t.Fatalf("attempts status = %v, %v, want the first one failed", attempts[0].Status(), attempts[1].Status())
+	}
+}
+
+// TestLogger checks that the zap logs get the ids of the span of the context
+func TestLogger(t *testing.T) {
+	record(t)
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+
+	tracing.Logger(context.Background(), logger).Info("no span")
+	ctx, span := otel.Tracer("test").Start(context.Background(), "root")
+	defer span.End()
+	tracing.Logger(ctx, logger).Info("in a span")
+
+	entries := logs.All()
+	if _, found := entries[0].ContextMap()["trace_id"]; found {
+		t.Fatalf("log without span = %v, want no trace_id", entries[0].ContextMap())
+	}
+	fields := entries[1].ContextMap()
+	if fields["trace_id"] != span.SpanContext().TraceID().String() || fields["span_id"] != span.SpanContext().SpanID().String() {
+		t.Fatalf("log in a span = %v, want the ids of %v", fields, span.SpanContext())
+	}
+}
+
+// TestOTLPExporter checks that the spans are sent to the collector, here an HTTP server standing in
+// for it
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := &collectorpb.ExportTraceServiceRequest{}
+		if r.URL.Path != "/v1/traces" || proto.Unmarshal(body, request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					names = append(names, span.Name)
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(nil)
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "test",
+		Exporter:    tracing.OTLPExporter,
+		Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		Insecure:    true,
+	})
+	if err != nil {
+		t.Fatalf("Setup() = %v", err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "exported")
+	span.End()
+	// shutdown sends the batched spans
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(names) != 1 || names[0] != "exported" {
+		t.Fatalf("collector received %v, want the exported span", names)
+	}
+}
+
+// TestSetupUnknownExporter checks that the unknown exporters are refused
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "jaeger"}); err == nil {
+		t.Fatal("Setup(jaeger) = nil, want an error")
+	}
+}